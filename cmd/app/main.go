@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"social-media-analyzer/internal/config"
 	"social-media-analyzer/internal/db"
 	"social-media-analyzer/internal/db/migrations"
+	"social-media-analyzer/internal/models"
 	"social-media-analyzer/internal/service"
 	"social-media-analyzer/internal/transport/http/controller"
 	"social-media-analyzer/internal/transport/http/router"
@@ -18,40 +24,180 @@ func main() {
 		log.Fatalf("congif load failed: %v", err)
 	}
 
-	db, err := db.Connect(&cfg.Database)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	database, err := db.Connect(ctx, &cfg.Database)
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
 
-	if err := migrations.Migrate(db); err != nil {
-		log.Fatalf("auto-migrate failed: %v", err)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := runMigrate(database, os.Args[2:])
+		db.Close(database)
+		if err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
 	}
 
-	// Initialize router
-	r := router.New()
+	if err := migrations.Migrate(database); err != nil {
+		log.Fatalf("migrate failed: %v", err)
+	}
 
 	// Initialize services using Factory pattern
-	factory := service.NewServiceFactory(cfg, db)
+	factory := service.NewServiceFactory(cfg, database)
 	services := factory.CreateServices()
 
-	// Initialize controllers
-	pageCtrl := controller.NewMainController(services.TemplateDataService)
-	groupCtrl := controller.NewGroupController(db, services.VKService)
+	if len(os.Args) > 1 && os.Args[1] == "retention" {
+		err := runRetention(ctx, services.RetentionService, os.Args[2:])
+		db.Close(database)
+		if err != nil {
+			log.Fatalf("retention: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "user" {
+		err := runUser(services.AuthService, os.Args[2:], os.Stdin)
+		db.Close(database)
+		if err != nil {
+			log.Fatalf("user: %v", err)
+		}
+		return
+	}
+
+	services.SyncService.Start(cfg.VK.SyncWorkers)
+	services.RetentionService.Start(cfg.Retention.Interval)
+
+	// Initialize router; request ID and access log come first so that they
+	// see recovered panics and the compressed response size. Recovery runs
+	// inside Gzip, so its 500 response is written through the compressor.
+	r := router.New()
+	r.Use(router.RequestID())
+	r.Use(router.AccessLog(nil))
+	r.Use(router.Gzip())
+	r.Use(router.Recovery())
+
+	// Initialize controllers; the auditor records their actions in the audit log
+	auditor := controller.NewAuditor(services.AuditService)
+	pageCtrl := controller.NewMainController(services.TemplateDataService, services.TagService, r.URL)
+	groupCtrl := controller.NewGroupController(services.VKService, services.GroupIdentityService, services.DailyStatsService, services.AnalyticsService, services.SyncService, services.WorkspaceService, auditor)
+	importCtrl := controller.NewImportController(services.GroupImportService, auditor)
+	tagCtrl := controller.NewTagController(services.TagService, auditor)
+	compareCtrl := controller.NewComparisonController(services.ComparisonService, r.URL)
+	setCtrl := controller.NewGroupSetController(services.GroupSetService, auditor)
+	retentionCtrl := controller.NewRetentionController(services.RetentionService)
+	authCtrl := controller.NewAuthController(services.AuthService, services.APIKeyService, cfg.Auth, r.URL)
+	keyCtrl := controller.NewAPIKeyController(services.APIKeyService, auditor, r.URL)
+	workspaceCtrl := controller.NewWorkspaceController(services.WorkspaceService, auditor, r.URL)
+	auditCtrl := controller.NewAuditController(services.AuditService, r.URL)
 
 	// Register routes
-	r.GET("/", pageCtrl.GetMainPage)
-	r.POST("/api/groups", groupCtrl.AddGroup)
+	// Routes used by templates and scripts are named and linked with the
+	// "url" template function instead of hard-coded paths
+	r.GET("/login", authCtrl.GetLoginPage).Name("login")
+	r.POST("/login", authCtrl.Login)
+
+	// Everything but the login page and static files needs a signed-in user
+	pages := r.Group("/", authCtrl.RequirePageLogin())
+	pages.GET("/workspaces", workspaceCtrl.GetWorkspacesPage).Name("workspaces")
+	pages.POST("/workspaces/select", workspaceCtrl.SelectWorkspace).Name("workspaces.select")
+	pages.POST("/logout", authCtrl.Logout).Name("logout")
+
+	// Pages showing data need a workspace
+	wsPages := pages.Group("/", workspaceCtrl.RequireWorkspacePage())
+	wsPages.GET("/", pageCtrl.GetMainPage).Name("main")
+	wsPages.GET("/compare", compareCtrl.GetComparePage).Name("compare")
+	wsPages.GET("/keys", keyCtrl.GetKeysPage).Name("keys")
+	wsPages.GET("/audit", auditCtrl.GetAuditPage).Name("audit")
+
+	authed := r.Group("/api", authCtrl.RequireAPILogin())
+	authed.GET("/workspaces", workspaceCtrl.ListWorkspaces).Name("api.workspaces")
+	authed.POST("/workspaces", workspaceCtrl.CreateWorkspace)
+
+	// The data API works in the workspace of the key or session: viewers
+	// read, editors also write
+	api := authed.Group("/", workspaceCtrl.RequireWorkspace())
+	api.GET("/groups", groupCtrl.ListGroups).Name("api.groups")
+	api.POST("/groups", groupCtrl.AddGroup)
+	api.POST("/groups/bulk", importCtrl.BulkAddGroups).Name("api.groups.bulk")
+
+	group := api.Group("/groups/:id", workspaceCtrl.RequireTrackedGroup())
+	group.DELETE("", groupCtrl.RemoveGroup).Name("api.groups.item")
+	group.GET("/trend", groupCtrl.GetGroupTrend).Name("api.groups.trend")
+	group.GET("/screen-names", groupCtrl.GetScreenNameHistory)
+	group.DELETE("/sync", groupCtrl.CancelSync)
+	group.PUT("/tags", tagCtrl.SetGroupTags)
+	group.PUT("/notes", tagCtrl.SetGroupNotes)
+	api.GET("/tags", tagCtrl.ListTags)
+	api.POST("/tags/bulk", tagCtrl.BulkTag)
+	api.GET("/compare", compareCtrl.Compare)
+	api.GET("/sets", setCtrl.ListSets)
+	api.POST("/sets", setCtrl.CreateSet)
+	api.GET("/sets/:id", setCtrl.GetSet)
+	api.PUT("/sets/:id", setCtrl.UpdateSet)
+	api.DELETE("/sets/:id", setCtrl.DeleteSet)
+	api.GET("/sets/:id/benchmark", setCtrl.GetBenchmark)
+	api.GET("/retention", retentionCtrl.GetRetention)
+
+	api.GET("/workspace/members", workspaceCtrl.ListMembers).Name("api.members")
+	members := api.Group("/workspace/members", workspaceCtrl.RequireWorkspaceRole(models.WorkspaceRoleOwner))
+	members.POST("", workspaceCtrl.AddMember)
+	members.PUT("/:user_id{uint}", workspaceCtrl.SetMemberRole).Name("api.members.item")
+	members.DELETE("/:user_id{uint}", workspaceCtrl.RemoveMember)
+
+	// Only owners read the audit log, with a session or an admin key
+	api.GET("/audit", auditCtrl.ListEvents,
+		authCtrl.RequireScope(models.APIKeyScopeAdmin),
+		workspaceCtrl.RequireWorkspaceRole(models.WorkspaceRoleOwner)).Name("api.audit")
+
+	// API keys may only manage keys with the admin scope. Every member may
+	// have keys: a key never gets more than its owner's role allows.
+	keys := authed.Group("/keys",
+		authCtrl.RequireScope(models.APIKeyScopeAdmin),
+		workspaceCtrl.RequireWorkspaceRole(models.WorkspaceRoleViewer))
+	keys.GET("", keyCtrl.ListKeys).Name("api.keys")
+	keys.POST("", keyCtrl.CreateKey)
+	keys.DELETE("/:id{uint}", keyCtrl.RevokeKey).Name("api.keys.item")
 
-	// Create multiplexer
-	mux := http.NewServeMux()
-	
 	// Serve static files
 	fs := http.FileServer(http.Dir("web/static"))
-	mux.Handle("/static/", http.StripPrefix("/static/", fs))
+	r.Mount("/static/", http.StripPrefix("/static/", fs)).Name("static")
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           r,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
 
-	// Use router as fallback for all other routes
-	mux.Handle("/", r)
-	
-	log.Printf("Server starting on port %s", cfg.Server.Port)
-	log.Fatal(http.ListenAndServe(":"+cfg.Server.Port, mux))
+	go func() {
+		log.Printf("Server starting on port %s", cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server failed: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	// Restore default signal handling, so a second Ctrl-C forces an exit
+	stop()
+	log.Print("Shutting down, waiting for running syncs to finish")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("server shutdown: %v", err)
+	}
+	if err := services.SyncService.Shutdown(shutdownCtx); err != nil {
+		log.Printf("sync shutdown: %v", err)
+	}
+	services.RetentionService.Stop()
+	if err := db.Close(database); err != nil {
+		log.Printf("failed to close database: %v", err)
+	}
+	log.Print("Server stopped")
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"social-media-analyzer/internal/db/migrations"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `usage: app migrate <command>
+
+commands:
+  up            apply all pending migrations
+  down          roll back the last applied migration
+  status        list migrations and when they were applied
+  to <version>  migrate up or down to the given version (0 rolls back everything)`
+
+var errMigrateUsage = errors.New(migrateUsage)
+
+// runMigrate executes the migrate subcommand
+func runMigrate(database *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	m, err := migrations.New(database)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+	case "down":
+		if err := m.Down(); err != nil {
+			return err
+		}
+	case "to":
+		if len(args) != 2 {
+			return errMigrateUsage
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := m.To(version); err != nil {
+			return err
+		}
+	case "status":
+		return printMigrationStatus(m)
+	default:
+		return errMigrateUsage
+	}
+
+	current, err := m.Current()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Schema version: %d (latest %d)\n", current, m.Latest())
+	return nil
+}
+
+// printMigrationStatus prints a table of known migrations
+func printMigrationStatus(m *migrations.Migrator) error {
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	current, err := m.Current()
+	if err != nil {
+		return err
+	}
+	if current > m.Latest() {
+		fmt.Printf("Schema version %d is newer than this build (latest %d)\n", current, m.Latest())
+	}
+	return nil
+}
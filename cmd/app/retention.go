@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"social-media-analyzer/internal/service"
+)
+
+const retentionUsage = `usage: app retention [run]
+
+Without arguments, prints what the configured retention policy would remove.
+"run" removes it.`
+
+var errRetentionUsage = errors.New(retentionUsage)
+
+// runRetention executes the retention subcommand
+func runRetention(ctx context.Context, retention *service.RetentionService, args []string) error {
+	dryRun := true
+	switch {
+	case len(args) == 0:
+	case len(args) == 1 && args[0] == "run":
+		dryRun = false
+	default:
+		return errRetentionUsage
+	}
+
+	if !retention.Enabled() {
+		fmt.Println("No retention configured: all data is kept")
+		return nil
+	}
+	report, err := retention.Run(ctx, dryRun)
+	if err != nil {
+		return err
+	}
+	printRetentionReport(report)
+	return nil
+}
+
+// printRetentionReport prints the counts and purged groups of a retention run
+func printRetentionReport(report *service.RetentionReport) {
+	verb := "Removed"
+	if report.DryRun {
+		verb = "Would remove"
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATA\tOLDER THAN\tROWS")
+	if report.Cutoffs.Posts != "" {
+		fmt.Fprintf(w, "posts\t%s\t%d\n", report.Cutoffs.Posts, report.Posts)
+	}
+	if report.Cutoffs.DailyStats != "" {
+		fmt.Fprintf(w, "daily stats\t%s\t%d\n", report.Cutoffs.DailyStats, report.DailyStats)
+	}
+	if report.Cutoffs.DeletedGroups != "" {
+		fmt.Fprintf(w, "deleted groups\t%s\t%d\n", report.Cutoffs.DeletedGroups, len(report.Groups))
+	}
+	w.Flush()
+
+	for _, group := range report.Groups {
+		fmt.Printf("%s group %d (%s) with all its data\n", verb, group.ID, group.Domain)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"social-media-analyzer/internal/service"
+)
+
+const userUsage = `usage: app user <command>
+
+commands:
+  list                list user accounts
+  add <username>      create an account; the password is read from stdin
+  passwd <username>   set a new password read from stdin and sign the user out everywhere
+  delete <username>   delete an account and its sessions`
+
+var errUserUsage = errors.New(userUsage)
+
+// runUser executes the user subcommand
+func runUser(auth *service.AuthService, args []string, stdin io.Reader) error {
+	if len(args) == 0 {
+		return errUserUsage
+	}
+
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		users, err := auth.ListUsers()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "USERNAME\tCREATED\tLAST LOGIN")
+		for _, user := range users {
+			lastLogin := "never"
+			if user.LastLoginAt != nil {
+				lastLogin = user.LastLoginAt.Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", user.Username, user.CreatedAt.Format("2006-01-02 15:04"), lastLogin)
+		}
+		return w.Flush()
+
+	case args[0] == "add" && len(args) == 2:
+		password, err := readPassword(stdin)
+		if err != nil {
+			return err
+		}
+		user, err := auth.CreateUser(args[1], password)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created user %s\n", user.Username)
+		return nil
+
+	case args[0] == "passwd" && len(args) == 2:
+		password, err := readPassword(stdin)
+		if err != nil {
+			return err
+		}
+		if err := auth.SetPassword(args[1], password); err != nil {
+			return err
+		}
+		fmt.Printf("Changed password of %s\n", service.NormalizeUsername(args[1]))
+		return nil
+
+	case args[0] == "delete" && len(args) == 2:
+		if err := auth.DeleteUser(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted user %s\n", service.NormalizeUsername(args[1]))
+		return nil
+	}
+	return errUserUsage
+}
+
+// readPassword reads one line from stdin, so the password can be piped in
+// instead of appearing in the process list
+func readPassword(stdin io.Reader) (string, error) {
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
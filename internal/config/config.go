@@ -4,32 +4,92 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	VK       VKConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	VK        VKConfig
+	Retention RetentionConfig
+	Auth      AuthConfig
 }
 
 type ServerConfig struct {
-	Port string
+	Port              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout bounds how long running requests and syncs may take to finish on exit
+	ShutdownTimeout time.Duration
 }
 
+// Supported database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Database log levels, from quietest to most verbose
+var DatabaseLogLevels = []string{"silent", "error", "warn", "info"}
+
 type DatabaseConfig struct {
+	// Driver is DriverPostgres or DriverSQLite
+	Driver string
+	// Path is the database file used by the SQLite driver
+	Path string
+	// URL is a postgres:// connection URL used instead of the discrete fields below
+	URL      string
 	Host     string
 	Port     int
 	User     string
 	Password string
 	Name     string
+
+	// Connection pool limits; zero keeps the database/sql default
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// PrepareStmt caches prepared statements per connection
+	PrepareStmt bool
+
+	// LogLevel is one of DatabaseLogLevels
+	LogLevel string
+	// SlowQueryThreshold logs queries slower than this at warn level; zero disables it
+	SlowQueryThreshold time.Duration
+	// ConnectTimeout is how long startup keeps retrying an unreachable database
+	ConnectTimeout time.Duration
 }
 
 type VKConfig struct {
 	AccessToken string
 	APIVersion  string
+	SyncWorkers int
+}
+
+// RetentionConfig sets how long each kind of data is kept; zero keeps it forever
+type RetentionConfig struct {
+	PostDays         int
+	DailyStatsDays   int
+	DeletedGroupDays int
+	// Interval between maintenance runs; zero disables the background job
+	Interval time.Duration
+}
+
+// AuthConfig controls sign-in sessions
+type AuthConfig struct {
+	// SessionTTL is how long a session stays valid without activity
+	SessionTTL time.Duration
+	// CookieSecure marks the session cookie Secure, so browsers only send it
+	// over HTTPS; enable it whenever the app is served through TLS
+	CookieSecure bool
 }
 
 // Load reads configuration from environment variables
@@ -38,31 +98,160 @@ func Load() (*Config, error) {
         log.Print("No .env file found")
     }
 
-	dbPort, err := strconv.Atoi(getEnv("DB_PORT", "5432"))
+	database, err := loadDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	syncWorkers, err := strconv.Atoi(getEnv("VK_SYNC_WORKERS", "4"))
 	if err != nil {
-		return nil, fmt.Errorf("invalid DB_PORT: %w", err)
+		return nil, fmt.Errorf("invalid VK_SYNC_WORKERS: %w", err)
+	}
+
+	server := ServerConfig{Port: getEnv("PORT", "3000")}
+	for _, d := range []struct {
+		key   string
+		value string
+		dst   *time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", "15s", &server.ReadTimeout},
+		{"SERVER_READ_HEADER_TIMEOUT", "5s", &server.ReadHeaderTimeout},
+		{"SERVER_WRITE_TIMEOUT", "60s", &server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", "120s", &server.IdleTimeout},
+		{"SERVER_SHUTDOWN_TIMEOUT", "30s", &server.ShutdownTimeout},
+	} {
+		if *d.dst, err = time.ParseDuration(getEnv(d.key, d.value)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", d.key, err)
+		}
+	}
+
+	retention, err := loadRetention()
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := loadAuth()
+	if err != nil {
+		return nil, err
 	}
 
 	cfg := &Config{
-		Server: ServerConfig{
-			Port: getEnv("PORT", "3000"),
-		},
-		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "postgres"),
-			Port:     dbPort,
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			Name:     getEnv("DB_NAME", "database"),
-		},
+		Server:    server,
+		Database:  database,
+		Retention: retention,
+		Auth:      auth,
 		VK: VKConfig{
 			AccessToken: getEnv("VK_ACCESS_TOKEN", ""),
 			APIVersion:  getEnv("VK_API_VERSION", "5.131"),
+			SyncWorkers: syncWorkers,
 		},
 	}
 
 	return cfg, nil
 }
 
+// loadDatabase reads the DB_* variables; DATABASE_URL, when set, replaces
+// the driver and the discrete connection fields
+func loadDatabase() (DatabaseConfig, error) {
+	cfg := DatabaseConfig{
+		Driver:   getEnv("DB_DRIVER", DriverPostgres),
+		Path:     getEnv("DB_PATH", "social-media-analyzer.db"),
+		Host:     getEnv("DB_HOST", "postgres"),
+		User:     getEnv("DB_USER", "postgres"),
+		Password: getEnv("DB_PASSWORD", "postgres"),
+		Name:     getEnv("DB_NAME", "database"),
+		LogLevel: getEnv("DB_LOG_LEVEL", "warn"),
+	}
+
+	if url := getEnv("DATABASE_URL", ""); url != "" {
+		switch {
+		case strings.HasPrefix(url, "postgres://"), strings.HasPrefix(url, "postgresql://"):
+			cfg.Driver = DriverPostgres
+			cfg.URL = url
+		case strings.HasPrefix(url, "sqlite:"):
+			cfg.Driver = DriverSQLite
+			cfg.Path = strings.TrimPrefix(strings.TrimPrefix(url, "sqlite:"), "//")
+		default:
+			return cfg, fmt.Errorf("invalid DATABASE_URL: expected a postgres:// or sqlite: URL")
+		}
+	}
+	if cfg.Driver != DriverPostgres && cfg.Driver != DriverSQLite {
+		return cfg, fmt.Errorf("invalid DB_DRIVER %q: expected %s or %s", cfg.Driver, DriverPostgres, DriverSQLite)
+	}
+	if !slices.Contains(DatabaseLogLevels, cfg.LogLevel) {
+		return cfg, fmt.Errorf("invalid DB_LOG_LEVEL %q: expected one of %s", cfg.LogLevel, strings.Join(DatabaseLogLevels, ", "))
+	}
+
+	var err error
+	for _, n := range []struct {
+		key   string
+		value string
+		dst   *int
+	}{
+		{"DB_PORT", "5432", &cfg.Port},
+		{"DB_MAX_OPEN_CONNS", "10", &cfg.MaxOpenConns},
+		{"DB_MAX_IDLE_CONNS", "5", &cfg.MaxIdleConns},
+	} {
+		if *n.dst, err = strconv.Atoi(getEnv(n.key, n.value)); err != nil {
+			return cfg, fmt.Errorf("invalid %s: %w", n.key, err)
+		}
+	}
+	for _, d := range []struct {
+		key   string
+		value string
+		dst   *time.Duration
+	}{
+		{"DB_CONN_MAX_LIFETIME", "30m", &cfg.ConnMaxLifetime},
+		{"DB_CONN_MAX_IDLE_TIME", "5m", &cfg.ConnMaxIdleTime},
+		{"DB_SLOW_QUERY_THRESHOLD", "200ms", &cfg.SlowQueryThreshold},
+		{"DB_CONNECT_TIMEOUT", "30s", &cfg.ConnectTimeout},
+	} {
+		if *d.dst, err = time.ParseDuration(getEnv(d.key, d.value)); err != nil {
+			return cfg, fmt.Errorf("invalid %s: %w", d.key, err)
+		}
+	}
+	if cfg.PrepareStmt, err = strconv.ParseBool(getEnv("DB_PREPARE_STMT", "false")); err != nil {
+		return cfg, fmt.Errorf("invalid DB_PREPARE_STMT: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// loadRetention reads the RETENTION_* variables
+func loadRetention() (RetentionConfig, error) {
+	var cfg RetentionConfig
+	var err error
+	for _, n := range []struct {
+		key string
+		dst *int
+	}{
+		{"RETENTION_POST_DAYS", &cfg.PostDays},
+		{"RETENTION_DAILY_STATS_DAYS", &cfg.DailyStatsDays},
+		{"RETENTION_DELETED_GROUP_DAYS", &cfg.DeletedGroupDays},
+	} {
+		if *n.dst, err = strconv.Atoi(getEnv(n.key, "0")); err != nil || *n.dst < 0 {
+			return cfg, fmt.Errorf("invalid %s: expected a non-negative number of days", n.key)
+		}
+	}
+	if cfg.Interval, err = time.ParseDuration(getEnv("RETENTION_INTERVAL", "24h")); err != nil {
+		return cfg, fmt.Errorf("invalid RETENTION_INTERVAL: %w", err)
+	}
+	return cfg, nil
+}
+
+// loadAuth reads the AUTH_* variables
+func loadAuth() (AuthConfig, error) {
+	var cfg AuthConfig
+	var err error
+	if cfg.SessionTTL, err = time.ParseDuration(getEnv("AUTH_SESSION_TTL", "168h")); err != nil || cfg.SessionTTL <= 0 {
+		return cfg, fmt.Errorf("invalid AUTH_SESSION_TTL: expected a positive duration")
+	}
+	if cfg.CookieSecure, err = strconv.ParseBool(getEnv("AUTH_COOKIE_SECURE", "false")); err != nil {
+		return cfg, fmt.Errorf("invalid AUTH_COOKIE_SECURE: %w", err)
+	}
+	return cfg, nil
+}
+
 // getEnv gets an environment variable with a fallback default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -71,8 +260,18 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-// DSN returns the database connection string
+// DSN returns the database connection string for the configured driver
 func (c *DatabaseConfig) DSN() string {
+	if c.Driver != DriverSQLite && c.URL != "" {
+		return c.URL
+	}
+	if c.Driver == DriverSQLite {
+		// WAL and a busy timeout let sync workers write while pages are read;
+		// immediate transactions take the write lock up front instead of
+		// failing when two readers try to upgrade at once
+		return "file:" + c.Path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)" +
+			"&_pragma=journal_mode(WAL)&_txlock=immediate"
+	}
 	return fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable TimeZone=UTC",
 		c.Host, c.Port, c.User, c.Password, c.Name,
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLoadDatabaseDefaults tests pool and retry defaults
+func TestLoadDatabaseDefaults(t *testing.T) {
+	cfg, err := loadDatabase()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Driver != DriverPostgres || cfg.Port != 5432 || cfg.MaxOpenConns != 10 || cfg.MaxIdleConns != 5 {
+		t.Errorf("Unexpected defaults %+v", cfg)
+	}
+	if cfg.ConnectTimeout != 30*time.Second || cfg.SlowQueryThreshold != 200*time.Millisecond || cfg.LogLevel != "warn" {
+		t.Errorf("Unexpected defaults %+v", cfg)
+	}
+}
+
+// TestLoadDatabaseURL tests that DATABASE_URL replaces the discrete fields
+func TestLoadDatabaseURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		driver string
+		dsn    string
+		path   string
+	}{
+		{"postgres://u:p@db:5432/app?sslmode=disable", DriverPostgres, "postgres://u:p@db:5432/app?sslmode=disable", ""},
+		{"postgresql://u@db/app", DriverPostgres, "postgresql://u@db/app", ""},
+		{"sqlite:data/app.db", DriverSQLite, "", "data/app.db"},
+		{"sqlite:///var/lib/app.db", DriverSQLite, "", "/var/lib/app.db"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			t.Setenv("DB_DRIVER", DriverSQLite)
+			t.Setenv("DATABASE_URL", tt.url)
+			cfg, err := loadDatabase()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.Driver != tt.driver {
+				t.Errorf("Expected driver %s, got %s", tt.driver, cfg.Driver)
+			}
+			if tt.dsn != "" && cfg.DSN() != tt.dsn {
+				t.Errorf("Expected DSN %q, got %q", tt.dsn, cfg.DSN())
+			}
+			if tt.path != "" && cfg.Path != tt.path {
+				t.Errorf("Expected path %q, got %q", tt.path, cfg.Path)
+			}
+		})
+	}
+}
+
+// TestLoadDatabaseInvalid tests rejection of malformed settings
+func TestLoadDatabaseInvalid(t *testing.T) {
+	for key, value := range map[string]string{
+		"DATABASE_URL":            "mysql://db/app",
+		"DB_DRIVER":               "oracle",
+		"DB_LOG_LEVEL":            "debug",
+		"DB_MAX_OPEN_CONNS":       "many",
+		"DB_SLOW_QUERY_THRESHOLD": "fast",
+		"DB_PREPARE_STMT":         "maybe",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := loadDatabase(); err == nil {
+				t.Errorf("Expected %s=%q to be rejected", key, value)
+			}
+		})
+	}
+}
+
+// TestLoadRetention tests that retention defaults to keeping everything and rejects negative days
+func TestLoadRetention(t *testing.T) {
+	cfg, err := loadRetention()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.PostDays != 0 || cfg.DailyStatsDays != 0 || cfg.DeletedGroupDays != 0 || cfg.Interval != 24*time.Hour {
+		t.Errorf("Unexpected defaults %+v", cfg)
+	}
+
+	t.Setenv("RETENTION_POST_DAYS", "-1")
+	if _, err := loadRetention(); err == nil {
+		t.Error("Expected negative days to be rejected")
+	}
+}
+
+// TestLoadAuth tests the session defaults and rejecting invalid values
+func TestLoadAuth(t *testing.T) {
+	cfg, err := loadAuth()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.SessionTTL != 7*24*time.Hour || cfg.CookieSecure {
+		t.Errorf("Unexpected defaults %+v", cfg)
+	}
+
+	t.Setenv("AUTH_SESSION_TTL", "0s")
+	if _, err := loadAuth(); err == nil {
+		t.Error("Expected a zero session TTL to be rejected")
+	}
+}
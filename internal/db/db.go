@@ -1,15 +1,129 @@
 package db
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
 	"social-media-analyzer/internal/config"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Backoff between connection attempts while the database is starting
+const (
+	connectInitialDelay = 500 * time.Millisecond
+	connectMaxDelay     = 5 * time.Second
 )
 
-func Connect(cfg *config.DatabaseConfig) (*gorm.DB, error) {
-	dsn := cfg.DSN()
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+var logLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
+
+// Connect opens the configured database and applies the pool settings.
+// An unreachable database is retried with exponential backoff until
+// cfg.ConnectTimeout passes or ctx is cancelled, so the app survives a
+// database that starts a few seconds after it.
+func Connect(ctx context.Context, cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case config.DriverSQLite:
+		if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	case config.DriverPostgres, "":
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+
+	deadline := time.Now().Add(cfg.ConnectTimeout)
+	delay := connectInitialDelay
+	for attempt := 1; ; attempt++ {
+		db, err := open(cfg)
+		if err == nil {
+			return db, configurePool(db, cfg)
+		}
+		if db != nil {
+			Close(db)
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return nil, fmt.Errorf("database unreachable after %d attempts: %w", attempt, err)
+		}
+
+		log.Printf("Database not ready (attempt %d): %v; retrying in %s\n", attempt, err, delay)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, connectMaxDelay)
+	}
+}
+
+// open makes a single connection attempt
+func open(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	dialector := postgres.Open(cfg.DSN())
+	if cfg.Driver == config.DriverSQLite {
+		dialector = sqlite.Open(cfg.DSN())
+	}
+
+	return gorm.Open(dialector, &gorm.Config{
+		// TranslateError maps driver-specific errors such as unique violations to
+		// gorm.ErrDuplicatedKey, so services need not know which database is in use
+		TranslateError: true,
+		PrepareStmt:    cfg.PrepareStmt,
+		Logger:         newLogger(cfg),
+	})
+}
+
+// newLogger logs through the standard logger at the configured level,
+// reporting queries slower than the threshold as warnings
+func newLogger(cfg *config.DatabaseConfig) logger.Interface {
+	level, ok := logLevels[cfg.LogLevel]
+	if !ok {
+		level = logger.Warn
+	}
+	return logger.New(log.Default(), logger.Config{
+		SlowThreshold:             cfg.SlowQueryThreshold,
+		LogLevel:                  level,
+		IgnoreRecordNotFoundError: true,
+	})
+}
+
+// configurePool applies the connection pool limits that are set
+func configurePool(db *gorm.DB, cfg *config.DatabaseConfig) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if cfg.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+	return nil
+}
 
-	return db, err
+// Close closes the connection pool behind db
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
 }
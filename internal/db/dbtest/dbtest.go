@@ -0,0 +1,37 @@
+// Package dbtest provides migrated SQLite databases for tests that need real SQL
+package dbtest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"social-media-analyzer/internal/config"
+	"social-media-analyzer/internal/db"
+	"social-media-analyzer/internal/db/migrations"
+
+	"gorm.io/gorm"
+)
+
+// New returns a fully migrated SQLite database in a temporary directory,
+// closed when the test finishes
+func New(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	cfg := config.DatabaseConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "test.db"),
+		// Tests provoke constraint violations on purpose
+		LogLevel: "silent",
+	}
+	database, err := db.Connect(context.Background(), &cfg)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close(database) })
+
+	if err := migrations.Migrate(database); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return database
+}
@@ -0,0 +1,249 @@
+package migrations
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"social-media-analyzer/internal/models"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestLoadOrdersMigrations tests pairing up and down files and ordering by version
+func TestLoadOrdersMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_second.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"0002_second.down.sql": {Data: []byte("DROP TABLE b;")},
+		"0001_first.up.sql":    {Data: []byte("CREATE TABLE a (id INT);")},
+		"0001_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("Expected 2 migrations, got %d", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[0].Name != "first" || migrations[1].Version != 2 {
+		t.Errorf("Expected versions 1 and 2 in order, got %+v", migrations)
+	}
+	if migrations[1].Down != "DROP TABLE b;" {
+		t.Errorf("Expected down script of migration 2, got %q", migrations[1].Down)
+	}
+}
+
+// TestLoadRejectsInvalidSets tests validation of migration files
+func TestLoadRejectsInvalidSets(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"Missing down", fstest.MapFS{"0001_first.up.sql": {Data: []byte("SELECT 1;")}}},
+		{"Bad name", fstest.MapFS{"first.up.sql": {Data: []byte("SELECT 1;")}}},
+		{"Conflicting names", fstest.MapFS{
+			"0001_first.up.sql":   {Data: []byte("SELECT 1;")},
+			"0001_other.down.sql": {Data: []byte("SELECT 1;")},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.fsys); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+// TestEmbeddedMigrations tests that every dialect ships the same contiguous versions
+func TestEmbeddedMigrations(t *testing.T) {
+	var reference []Migration
+	for _, dialect := range Dialects {
+		m, err := NewForDialect(nil, dialect)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", dialect, err)
+		}
+		for i, migration := range m.migrations {
+			if migration.Version != i+1 {
+				t.Errorf("%s: expected version %d, got %d (%s)", dialect, i+1, migration.Version, migration.Name)
+			}
+		}
+		if m.Latest() != len(m.migrations) {
+			t.Errorf("%s: expected latest %d, got %d", dialect, len(m.migrations), m.Latest())
+		}
+
+		if reference == nil {
+			reference = m.migrations
+			continue
+		}
+		if len(m.migrations) != len(reference) {
+			t.Fatalf("%s: expected %d migrations, got %d", dialect, len(reference), len(m.migrations))
+		}
+		for i, migration := range m.migrations {
+			if migration.Name != reference[i].Name {
+				t.Errorf("%s: expected migration %d to be %q, got %q", dialect, migration.Version, reference[i].Name, migration.Name)
+			}
+		}
+	}
+
+	if _, err := NewForDialect(nil, "mysql"); !errors.Is(err, ErrUnsupportedDialect) {
+		t.Errorf("Expected ErrUnsupportedDialect, got %v", err)
+	}
+}
+
+// TestSQLiteUpAndDown tests applying, rolling back and re-applying the SQLite migrations
+func TestSQLiteUpAndDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	m, err := New(db)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	applied, err := m.Up()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if applied != m.Latest() {
+		t.Errorf("Expected %d migrations applied, got %d", m.Latest(), applied)
+	}
+
+	// The final schema must hold every field of the models
+	vkID := 42
+	now := time.Now().UTC()
+	group := models.Group{
+		VKID: &vkID, Domain: "club", Name: "Club", Verified: true, IsClosed: 1,
+		Status: models.GroupStatusClosed, StatusChangedAt: &now, Notes: "note",
+		Tags: []models.Tag{{Name: "news"}},
+	}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := db.Create(&models.Post{GroupID: group.ID, Date: "2025-12-01", Reposts: 1}).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var stored models.Group
+	if err := db.Preload("Tags").First(&stored, group.ID).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !stored.Verified || stored.StatusChangedAt == nil || len(stored.Tags) != 1 {
+		t.Errorf("Unexpected stored group %+v", stored)
+	}
+
+	if err := m.To(0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if current, _ := m.Current(); current != 0 {
+		t.Errorf("Expected everything rolled back, got version %d", current)
+	}
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("Unexpected error re-applying: %v", err)
+	}
+}
+
+// TestSQLiteWorkspacesUpgrade tests that the SQLite workspaces migration keeps
+// existing rows with foreign keys enforced, and that the workspace columns
+// reject rows without a workspace as on PostgreSQL
+func TestSQLiteWorkspacesUpgrade(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "test.db") + "?_pragma=foreign_keys(1)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	m, err := New(db)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := m.To(8); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, statement := range []string{
+		"INSERT INTO users (id, username, password_hash) VALUES (1, 'anna', 'x')",
+		"INSERT INTO groups (id, domain) VALUES (1, 'club')",
+		"INSERT INTO tags (id, name) VALUES (1, 'news')",
+		"INSERT INTO group_tags (group_id, tag_id) VALUES (1, 1)",
+		"INSERT INTO group_sets (id, name) VALUES (1, 'rivals')",
+		"INSERT INTO group_set_members (group_set_id, group_id) VALUES (1, 1)",
+		"INSERT INTO sessions (token_hash, user_id, csrf_token, last_seen_at, expires_at) VALUES ('h', 1, 'c', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)",
+		"INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scope) VALUES (1, 1, 'script', 'sma_x', 'k', 'read')",
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if err := m.To(9); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	counts := func() map[string]int64 {
+		result := map[string]int64{}
+		for _, table := range []string{"tags", "group_tags", "group_sets", "group_set_members", "sessions", "api_keys"} {
+			var n int64
+			if err := db.Table(table).Count(&n).Error; err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			result[table] = n
+		}
+		return result
+	}
+	for table, n := range counts() {
+		if n != 1 {
+			t.Errorf("Expected the %s row to be kept, got %d", table, n)
+		}
+	}
+	var unscoped int64
+	db.Table("api_keys").Where("workspace_id IS NULL").Count(&unscoped)
+	if unscoped != 0 {
+		t.Errorf("Expected keys to move to the default workspace, got %d without one", unscoped)
+	}
+
+	for _, statement := range []string{
+		"INSERT INTO tags (name) VALUES ('sport')",
+		"INSERT INTO tags (workspace_id, name) VALUES (99, 'sport')",
+		"INSERT INTO group_sets (name) VALUES ('other')",
+		"INSERT INTO api_keys (user_id, name, prefix, key_hash, scope) VALUES (1, 'x', 'sma_y', 'k2', 'read')",
+	} {
+		if err := db.Exec(statement).Error; err == nil {
+			t.Errorf("Expected %q to be rejected", statement)
+		}
+	}
+
+	if err := m.To(8); err != nil {
+		t.Fatalf("Unexpected error rolling back: %v", err)
+	}
+	for table, n := range counts() {
+		if n != 1 {
+			t.Errorf("Expected the %s row to be kept on rollback, got %d", table, n)
+		}
+	}
+}
+
+// TestSplitStatements tests splitting scripts into single statements
+func TestSplitStatements(t *testing.T) {
+	script := `-- comment
+CREATE TABLE a (
+    id INT
+);
+
+DROP INDEX IF EXISTS b;
+SELECT 1`
+
+	statements := splitStatements(script)
+	if len(statements) != 3 {
+		t.Fatalf("Expected 3 statements, got %d: %q", len(statements), statements)
+	}
+	if !strings.HasPrefix(statements[0], "CREATE TABLE a (") || !strings.HasSuffix(statements[0], ");") {
+		t.Errorf("Unexpected first statement %q", statements[0])
+	}
+	if statements[2] != "SELECT 1" {
+		t.Errorf("Expected trailing statement without semicolon, got %q", statements[2])
+	}
+}
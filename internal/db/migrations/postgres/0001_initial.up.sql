@@ -0,0 +1,22 @@
+-- Groups and their wall posts. IF NOT EXISTS lets databases created by the
+-- former AutoMigrate startup adopt the versioned migrations.
+CREATE TABLE IF NOT EXISTS groups (
+    id          BIGSERIAL PRIMARY KEY,
+    domain      TEXT NOT NULL,
+    subscribers BIGINT DEFAULT 0,
+    parsed_at   TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_domain ON groups (domain);
+
+CREATE TABLE IF NOT EXISTS posts (
+    id        BIGSERIAL PRIMARY KEY,
+    group_id  BIGINT,
+    date      TEXT NOT NULL,
+    views     BIGINT NOT NULL,
+    reactions BIGINT NOT NULL,
+    likes     BIGINT NOT NULL,
+    text      TEXT NOT NULL,
+    comments  BIGINT NOT NULL,
+    CONSTRAINT fk_groups_posts FOREIGN KEY (group_id) REFERENCES groups (id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS group_daily_stats;
+ALTER TABLE posts DROP COLUMN IF EXISTS reposts;
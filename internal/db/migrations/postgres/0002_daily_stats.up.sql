@@ -0,0 +1,15 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS reposts BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS group_daily_stats (
+    id          BIGSERIAL PRIMARY KEY,
+    group_id    BIGINT NOT NULL,
+    day         TEXT NOT NULL,
+    posts       BIGINT NOT NULL DEFAULT 0,
+    views       BIGINT NOT NULL DEFAULT 0,
+    likes       BIGINT NOT NULL DEFAULT 0,
+    comments    BIGINT NOT NULL DEFAULT 0,
+    reposts     BIGINT NOT NULL DEFAULT 0,
+    subscribers BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_daily_stats_group_day ON group_daily_stats (group_id, day);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS group_sets (
+    id          BIGSERIAL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_sets_name ON group_sets (name);
+
+CREATE TABLE IF NOT EXISTS group_set_members (
+    group_set_id BIGINT NOT NULL,
+    group_id     BIGINT NOT NULL,
+    PRIMARY KEY (group_set_id, group_id),
+    CONSTRAINT fk_group_set_members_group_set FOREIGN KEY (group_set_id) REFERENCES group_sets (id),
+    CONSTRAINT fk_group_set_members_group FOREIGN KEY (group_id) REFERENCES groups (id)
+);
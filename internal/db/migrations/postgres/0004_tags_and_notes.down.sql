@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS group_tags;
+DROP TABLE IF EXISTS tags;
+ALTER TABLE groups DROP COLUMN IF EXISTS notes;
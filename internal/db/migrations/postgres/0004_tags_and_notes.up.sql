@@ -0,0 +1,16 @@
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS tags (
+    id   BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
+
+CREATE TABLE IF NOT EXISTS group_tags (
+    group_id BIGINT NOT NULL,
+    tag_id   BIGINT NOT NULL,
+    PRIMARY KEY (group_id, tag_id),
+    CONSTRAINT fk_group_tags_group FOREIGN KEY (group_id) REFERENCES groups (id),
+    CONSTRAINT fk_group_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+);
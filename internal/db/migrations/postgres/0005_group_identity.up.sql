@@ -0,0 +1,22 @@
+-- Groups are identified by their numeric VK ID instead of the screen name
+DROP INDEX IF EXISTS idx_groups_domain;
+
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS vk_id BIGINT;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS name TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS photo_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS verified BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS is_closed BIGINT NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_vk_id ON groups (vk_id);
+CREATE INDEX IF NOT EXISTS idx_groups_domain_lookup ON groups (domain);
+
+CREATE TABLE IF NOT EXISTS group_screen_names (
+    id          BIGSERIAL PRIMARY KEY,
+    group_id    BIGINT NOT NULL,
+    screen_name TEXT NOT NULL,
+    renamed_to  TEXT NOT NULL,
+    renamed_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_screen_names_group_id ON group_screen_names (group_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_groups_status;
+
+ALTER TABLE groups DROP COLUMN IF EXISTS status_changed_at;
+ALTER TABLE groups DROP COLUMN IF EXISTS status;
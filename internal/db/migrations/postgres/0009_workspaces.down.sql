@@ -0,0 +1,23 @@
+-- Tags and group sets are global again, so only those of the first
+-- workspace are kept, together with its notes
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
+UPDATE groups SET notes = wg.notes FROM workspace_groups wg WHERE wg.group_id = groups.id AND wg.workspace_id = (SELECT MIN(id) FROM workspaces);
+
+DELETE FROM group_tags WHERE tag_id IN (SELECT id FROM tags WHERE workspace_id <> (SELECT MIN(id) FROM workspaces));
+DELETE FROM tags WHERE workspace_id <> (SELECT MIN(id) FROM workspaces);
+DROP INDEX IF EXISTS idx_tags_workspace_name;
+ALTER TABLE tags DROP COLUMN IF EXISTS workspace_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
+
+DELETE FROM group_set_members WHERE group_set_id IN (SELECT id FROM group_sets WHERE workspace_id <> (SELECT MIN(id) FROM workspaces));
+DELETE FROM group_sets WHERE workspace_id <> (SELECT MIN(id) FROM workspaces);
+DROP INDEX IF EXISTS idx_group_sets_workspace_name;
+ALTER TABLE group_sets DROP COLUMN IF EXISTS workspace_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_sets_name ON group_sets (name);
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_groups;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,65 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id BIGINT NOT NULL,
+    user_id      BIGINT NOT NULL,
+    role         TEXT NOT NULL,
+    created_at   TIMESTAMPTZ,
+    PRIMARY KEY (workspace_id, user_id),
+    CONSTRAINT fk_workspace_members_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    CONSTRAINT fk_workspace_members_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members (user_id);
+
+CREATE TABLE IF NOT EXISTS workspace_groups (
+    workspace_id BIGINT NOT NULL,
+    group_id     BIGINT NOT NULL,
+    notes        TEXT NOT NULL DEFAULT '',
+    added_at     TIMESTAMPTZ,
+    PRIMARY KEY (workspace_id, group_id),
+    CONSTRAINT fk_workspace_groups_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    CONSTRAINT fk_workspace_groups_group FOREIGN KEY (group_id) REFERENCES groups (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_groups_group_id ON workspace_groups (group_id);
+
+-- Existing installations get one workspace with everything tracked so far,
+-- owned by every existing user
+INSERT INTO workspaces (name, created_at)
+SELECT 'Default', CURRENT_TIMESTAMP
+WHERE EXISTS (SELECT 1 FROM users) OR EXISTS (SELECT 1 FROM groups) OR EXISTS (SELECT 1 FROM tags) OR EXISTS (SELECT 1 FROM group_sets);
+
+INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+SELECT w.id, u.id, 'owner', CURRENT_TIMESTAMP FROM workspaces w CROSS JOIN users u;
+
+INSERT INTO workspace_groups (workspace_id, group_id, notes, added_at)
+SELECT w.id, g.id, g.notes, g.parsed_at FROM workspaces w CROSS JOIN groups g;
+
+ALTER TABLE groups DROP COLUMN IF EXISTS notes;
+
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS workspace_id BIGINT;
+UPDATE tags SET workspace_id = (SELECT MIN(id) FROM workspaces);
+ALTER TABLE tags ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE tags ADD CONSTRAINT fk_tags_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE;
+DROP INDEX IF EXISTS idx_tags_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_workspace_name ON tags (workspace_id, name);
+
+ALTER TABLE group_sets ADD COLUMN IF NOT EXISTS workspace_id BIGINT;
+UPDATE group_sets SET workspace_id = (SELECT MIN(id) FROM workspaces);
+ALTER TABLE group_sets ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE group_sets ADD CONSTRAINT fk_group_sets_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE;
+DROP INDEX IF EXISTS idx_group_sets_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_sets_workspace_name ON group_sets (workspace_id, name);
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS workspace_id BIGINT;
+ALTER TABLE sessions ADD CONSTRAINT fk_sessions_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE SET NULL;
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS workspace_id BIGINT;
+UPDATE api_keys SET workspace_id = (SELECT MIN(id) FROM workspaces);
+ALTER TABLE api_keys ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE api_keys ADD CONSTRAINT fk_api_keys_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id           BIGSERIAL PRIMARY KEY,
+    created_at   TIMESTAMPTZ,
+    user_id      BIGINT,
+    username     TEXT NOT NULL DEFAULT '',
+    api_key_id   BIGINT,
+    workspace_id BIGINT,
+    action       TEXT NOT NULL,
+    target_type  TEXT NOT NULL DEFAULT '',
+    target_id    TEXT NOT NULL DEFAULT '',
+    details      TEXT NOT NULL DEFAULT '{}',
+    request_id   TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_workspace_id ON audit_events (workspace_id);
+
+-- The log is append-only; actors and workspaces are plain columns without
+-- foreign keys so that deleting them leaves their events in place
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$ BEGIN RAISE EXCEPTION 'audit events are append-only'; END; $$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
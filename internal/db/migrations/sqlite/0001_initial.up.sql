@@ -0,0 +1,21 @@
+-- SQLite counterpart of postgres/0001_initial.up.sql
+CREATE TABLE IF NOT EXISTS groups (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    domain      TEXT NOT NULL,
+    subscribers INTEGER DEFAULT 0,
+    parsed_at   DATETIME
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_domain ON groups (domain);
+
+CREATE TABLE IF NOT EXISTS posts (
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id  INTEGER,
+    date      TEXT NOT NULL,
+    views     INTEGER NOT NULL,
+    reactions INTEGER NOT NULL,
+    likes     INTEGER NOT NULL,
+    text      TEXT NOT NULL,
+    comments  INTEGER NOT NULL,
+    CONSTRAINT fk_groups_posts FOREIGN KEY (group_id) REFERENCES groups (id)
+);
@@ -0,0 +1,15 @@
+ALTER TABLE posts ADD COLUMN reposts INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS group_daily_stats (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id    INTEGER NOT NULL,
+    day         TEXT NOT NULL,
+    posts       INTEGER NOT NULL DEFAULT 0,
+    views       INTEGER NOT NULL DEFAULT 0,
+    likes       INTEGER NOT NULL DEFAULT 0,
+    comments    INTEGER NOT NULL DEFAULT 0,
+    reposts     INTEGER NOT NULL DEFAULT 0,
+    subscribers INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_daily_stats_group_day ON group_daily_stats (group_id, day);
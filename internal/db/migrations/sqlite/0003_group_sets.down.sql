@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS group_set_members;
+DROP TABLE IF EXISTS group_sets;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS group_sets (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at  DATETIME
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_sets_name ON group_sets (name);
+
+CREATE TABLE IF NOT EXISTS group_set_members (
+    group_set_id INTEGER NOT NULL,
+    group_id     INTEGER NOT NULL,
+    PRIMARY KEY (group_set_id, group_id),
+    CONSTRAINT fk_group_set_members_group_set FOREIGN KEY (group_set_id) REFERENCES group_sets (id),
+    CONSTRAINT fk_group_set_members_group FOREIGN KEY (group_id) REFERENCES groups (id)
+);
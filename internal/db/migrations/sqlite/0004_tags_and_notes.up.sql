@@ -0,0 +1,16 @@
+ALTER TABLE groups ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS tags (
+    id   INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
+
+CREATE TABLE IF NOT EXISTS group_tags (
+    group_id INTEGER NOT NULL,
+    tag_id   INTEGER NOT NULL,
+    PRIMARY KEY (group_id, tag_id),
+    CONSTRAINT fk_group_tags_group FOREIGN KEY (group_id) REFERENCES groups (id),
+    CONSTRAINT fk_group_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+);
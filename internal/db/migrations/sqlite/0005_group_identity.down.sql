@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS group_screen_names;
+
+DROP INDEX IF EXISTS idx_groups_domain_lookup;
+DROP INDEX IF EXISTS idx_groups_vk_id;
+
+ALTER TABLE groups DROP COLUMN is_closed;
+ALTER TABLE groups DROP COLUMN verified;
+ALTER TABLE groups DROP COLUMN type;
+ALTER TABLE groups DROP COLUMN photo_url;
+ALTER TABLE groups DROP COLUMN name;
+ALTER TABLE groups DROP COLUMN vk_id;
+
+-- Fails if renamed groups now share a screen name
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_domain ON groups (domain);
@@ -0,0 +1,22 @@
+-- Groups are identified by their numeric VK ID instead of the screen name
+DROP INDEX IF EXISTS idx_groups_domain;
+
+ALTER TABLE groups ADD COLUMN vk_id INTEGER;
+ALTER TABLE groups ADD COLUMN name TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN photo_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN type TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN verified BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE groups ADD COLUMN is_closed INTEGER NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_vk_id ON groups (vk_id);
+CREATE INDEX IF NOT EXISTS idx_groups_domain_lookup ON groups (domain);
+
+CREATE TABLE IF NOT EXISTS group_screen_names (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id    INTEGER NOT NULL,
+    screen_name TEXT NOT NULL,
+    renamed_to  TEXT NOT NULL,
+    renamed_at  DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_screen_names_group_id ON group_screen_names (group_id);
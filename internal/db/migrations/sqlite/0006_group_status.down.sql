@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_groups_status;
+
+ALTER TABLE groups DROP COLUMN status_changed_at;
+ALTER TABLE groups DROP COLUMN status;
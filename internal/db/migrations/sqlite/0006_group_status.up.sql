@@ -0,0 +1,4 @@
+ALTER TABLE groups ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+ALTER TABLE groups ADD COLUMN status_changed_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_groups_status ON groups (status);
@@ -0,0 +1,95 @@
+-- Tags and group sets are global again, so only those of the first
+-- workspace are kept, together with its notes
+ALTER TABLE groups ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+UPDATE groups SET notes = COALESCE((SELECT wg.notes FROM workspace_groups wg WHERE wg.group_id = groups.id AND wg.workspace_id = (SELECT MIN(id) FROM workspaces)), '');
+
+DELETE FROM group_tags WHERE tag_id IN (SELECT id FROM tags WHERE workspace_id <> (SELECT MIN(id) FROM workspaces));
+DELETE FROM tags WHERE workspace_id <> (SELECT MIN(id) FROM workspaces);
+DELETE FROM group_set_members WHERE group_set_id IN (SELECT id FROM group_sets WHERE workspace_id <> (SELECT MIN(id) FROM workspaces));
+DELETE FROM group_sets WHERE workspace_id <> (SELECT MIN(id) FROM workspaces);
+
+-- The workspace_id columns carry foreign keys, which SQLite cannot drop, so
+-- the tables are rebuilt as the up migration does
+CREATE TABLE group_tags_old AS SELECT * FROM group_tags;
+DROP TABLE group_tags;
+CREATE TABLE group_set_members_old AS SELECT * FROM group_set_members;
+DROP TABLE group_set_members;
+
+CREATE TABLE tags_old (
+    id   INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL
+);
+INSERT INTO tags_old (id, name) SELECT id, name FROM tags;
+DROP TABLE tags;
+ALTER TABLE tags_old RENAME TO tags;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
+
+CREATE TABLE group_sets_old (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at  DATETIME
+);
+INSERT INTO group_sets_old (id, name, description, created_at) SELECT id, name, description, created_at FROM group_sets;
+DROP TABLE group_sets;
+ALTER TABLE group_sets_old RENAME TO group_sets;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_sets_name ON group_sets (name);
+
+CREATE TABLE group_tags (
+    group_id INTEGER NOT NULL,
+    tag_id   INTEGER NOT NULL,
+    PRIMARY KEY (group_id, tag_id),
+    CONSTRAINT fk_group_tags_group FOREIGN KEY (group_id) REFERENCES groups (id),
+    CONSTRAINT fk_group_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+);
+INSERT INTO group_tags (group_id, tag_id) SELECT group_id, tag_id FROM group_tags_old;
+DROP TABLE group_tags_old;
+
+CREATE TABLE group_set_members (
+    group_set_id INTEGER NOT NULL,
+    group_id     INTEGER NOT NULL,
+    PRIMARY KEY (group_set_id, group_id),
+    CONSTRAINT fk_group_set_members_group_set FOREIGN KEY (group_set_id) REFERENCES group_sets (id),
+    CONSTRAINT fk_group_set_members_group FOREIGN KEY (group_id) REFERENCES groups (id)
+);
+INSERT INTO group_set_members (group_set_id, group_id) SELECT group_set_id, group_id FROM group_set_members_old;
+DROP TABLE group_set_members_old;
+
+CREATE TABLE sessions_old (
+    token_hash   TEXT PRIMARY KEY,
+    user_id      INTEGER NOT NULL,
+    csrf_token   TEXT NOT NULL,
+    created_at   DATETIME,
+    last_seen_at DATETIME NOT NULL,
+    expires_at   DATETIME NOT NULL,
+    CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+INSERT INTO sessions_old (token_hash, user_id, csrf_token, created_at, last_seen_at, expires_at)
+SELECT token_hash, user_id, csrf_token, created_at, last_seen_at, expires_at FROM sessions;
+DROP TABLE sessions;
+ALTER TABLE sessions_old RENAME TO sessions;
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions (expires_at);
+
+CREATE TABLE api_keys_old (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id      INTEGER NOT NULL,
+    name         TEXT NOT NULL,
+    prefix       TEXT NOT NULL,
+    key_hash     TEXT NOT NULL,
+    scope        TEXT NOT NULL,
+    created_at   DATETIME,
+    expires_at   DATETIME,
+    last_used_at DATETIME,
+    CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+INSERT INTO api_keys_old (id, user_id, name, prefix, key_hash, scope, created_at, expires_at, last_used_at)
+SELECT id, user_id, name, prefix, key_hash, scope, created_at, expires_at, last_used_at FROM api_keys;
+DROP TABLE api_keys;
+ALTER TABLE api_keys_old RENAME TO api_keys;
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
+
+DROP TABLE IF EXISTS workspace_groups;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,136 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       TEXT NOT NULL,
+    created_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id INTEGER NOT NULL,
+    user_id      INTEGER NOT NULL,
+    role         TEXT NOT NULL,
+    created_at   DATETIME,
+    PRIMARY KEY (workspace_id, user_id),
+    CONSTRAINT fk_workspace_members_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    CONSTRAINT fk_workspace_members_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members (user_id);
+
+CREATE TABLE IF NOT EXISTS workspace_groups (
+    workspace_id INTEGER NOT NULL,
+    group_id     INTEGER NOT NULL,
+    notes        TEXT NOT NULL DEFAULT '',
+    added_at     DATETIME,
+    PRIMARY KEY (workspace_id, group_id),
+    CONSTRAINT fk_workspace_groups_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    CONSTRAINT fk_workspace_groups_group FOREIGN KEY (group_id) REFERENCES groups (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_workspace_groups_group_id ON workspace_groups (group_id);
+
+-- Existing installations get one workspace with everything tracked so far,
+-- owned by every existing user
+INSERT INTO workspaces (name, created_at)
+SELECT 'Default', CURRENT_TIMESTAMP
+WHERE EXISTS (SELECT 1 FROM users) OR EXISTS (SELECT 1 FROM groups) OR EXISTS (SELECT 1 FROM tags) OR EXISTS (SELECT 1 FROM group_sets);
+
+INSERT INTO workspace_members (workspace_id, user_id, role, created_at)
+SELECT w.id, u.id, 'owner', CURRENT_TIMESTAMP FROM workspaces w CROSS JOIN users u;
+
+INSERT INTO workspace_groups (workspace_id, group_id, notes, added_at)
+SELECT w.id, g.id, g.notes, g.parsed_at FROM workspaces w CROSS JOIN groups g;
+
+ALTER TABLE groups DROP COLUMN notes;
+
+-- SQLite cannot add a NOT NULL column with a foreign key, so the tables
+-- scoped by workspace are rebuilt. Dropping a table checks and deletes the
+-- rows referencing it, so group_tags and group_set_members are set aside first.
+CREATE TABLE group_tags_old AS SELECT * FROM group_tags;
+DROP TABLE group_tags;
+CREATE TABLE group_set_members_old AS SELECT * FROM group_set_members;
+DROP TABLE group_set_members;
+
+CREATE TABLE tags_new (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    workspace_id INTEGER NOT NULL,
+    name         TEXT NOT NULL,
+    CONSTRAINT fk_tags_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE
+);
+INSERT INTO tags_new (id, workspace_id, name)
+SELECT id, (SELECT MIN(id) FROM workspaces), name FROM tags;
+DROP TABLE tags;
+ALTER TABLE tags_new RENAME TO tags;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_workspace_name ON tags (workspace_id, name);
+
+CREATE TABLE group_sets_new (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    workspace_id INTEGER NOT NULL,
+    name         TEXT NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    created_at   DATETIME,
+    CONSTRAINT fk_group_sets_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE
+);
+INSERT INTO group_sets_new (id, workspace_id, name, description, created_at)
+SELECT id, (SELECT MIN(id) FROM workspaces), name, description, created_at FROM group_sets;
+DROP TABLE group_sets;
+ALTER TABLE group_sets_new RENAME TO group_sets;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_sets_workspace_name ON group_sets (workspace_id, name);
+
+CREATE TABLE group_tags (
+    group_id INTEGER NOT NULL,
+    tag_id   INTEGER NOT NULL,
+    PRIMARY KEY (group_id, tag_id),
+    CONSTRAINT fk_group_tags_group FOREIGN KEY (group_id) REFERENCES groups (id),
+    CONSTRAINT fk_group_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+);
+INSERT INTO group_tags (group_id, tag_id) SELECT group_id, tag_id FROM group_tags_old;
+DROP TABLE group_tags_old;
+
+CREATE TABLE group_set_members (
+    group_set_id INTEGER NOT NULL,
+    group_id     INTEGER NOT NULL,
+    PRIMARY KEY (group_set_id, group_id),
+    CONSTRAINT fk_group_set_members_group_set FOREIGN KEY (group_set_id) REFERENCES group_sets (id),
+    CONSTRAINT fk_group_set_members_group FOREIGN KEY (group_id) REFERENCES groups (id)
+);
+INSERT INTO group_set_members (group_set_id, group_id) SELECT group_set_id, group_id FROM group_set_members_old;
+DROP TABLE group_set_members_old;
+
+CREATE TABLE sessions_new (
+    token_hash   TEXT PRIMARY KEY,
+    user_id      INTEGER NOT NULL,
+    workspace_id INTEGER,
+    csrf_token   TEXT NOT NULL,
+    created_at   DATETIME,
+    last_seen_at DATETIME NOT NULL,
+    expires_at   DATETIME NOT NULL,
+    CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_sessions_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE SET NULL
+);
+INSERT INTO sessions_new (token_hash, user_id, csrf_token, created_at, last_seen_at, expires_at)
+SELECT token_hash, user_id, csrf_token, created_at, last_seen_at, expires_at FROM sessions;
+DROP TABLE sessions;
+ALTER TABLE sessions_new RENAME TO sessions;
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions (expires_at);
+
+CREATE TABLE api_keys_new (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id      INTEGER NOT NULL,
+    workspace_id INTEGER NOT NULL,
+    name         TEXT NOT NULL,
+    prefix       TEXT NOT NULL,
+    key_hash     TEXT NOT NULL,
+    scope        TEXT NOT NULL,
+    created_at   DATETIME,
+    expires_at   DATETIME,
+    last_used_at DATETIME,
+    CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_api_keys_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE
+);
+INSERT INTO api_keys_new (id, user_id, workspace_id, name, prefix, key_hash, scope, created_at, expires_at, last_used_at)
+SELECT id, user_id, (SELECT MIN(id) FROM workspaces), name, prefix, key_hash, scope, created_at, expires_at, last_used_at FROM api_keys;
+DROP TABLE api_keys;
+ALTER TABLE api_keys_new RENAME TO api_keys;
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at   DATETIME,
+    user_id      INTEGER,
+    username     TEXT NOT NULL DEFAULT '',
+    api_key_id   INTEGER,
+    workspace_id INTEGER,
+    action       TEXT NOT NULL,
+    target_type  TEXT NOT NULL DEFAULT '',
+    target_id    TEXT NOT NULL DEFAULT '',
+    details      TEXT NOT NULL DEFAULT '{}',
+    request_id   TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_workspace_id ON audit_events (workspace_id);
+
+-- The log is append-only; actors and workspaces are plain columns without
+-- foreign keys so that deleting them leaves their events in place
+CREATE TRIGGER IF NOT EXISTS audit_events_no_update BEFORE UPDATE ON audit_events
+BEGIN SELECT RAISE(ABORT, 'audit events are append-only'); END;
+
+CREATE TRIGGER IF NOT EXISTS audit_events_no_delete BEFORE DELETE ON audit_events
+BEGIN SELECT RAISE(ABORT, 'audit events are append-only'); END;
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// APIKey lets a user's scripts call the API without a browser session.
+// Like session tokens, only the SHA-256 hash of the key is stored; Prefix is
+// the key's public beginning, shown in lists so a key can be recognized.
+type APIKey struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint `gorm:"not null;index"`
+	User   User
+	// WorkspaceID is the only workspace the key can access
+	WorkspaceID uint `gorm:"not null"`
+	Workspace   Workspace
+	Name        string    `gorm:"type:text;not null"`
+	Prefix      string    `gorm:"type:text;not null"`
+	KeyHash     string    `gorm:"type:text;not null;uniqueIndex"`
+	Scope       string    `gorm:"type:text;not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime:milli"`
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+}
+
+// API key scopes; each one includes the ones before it
+const (
+	APIKeyScopeRead  = "read"  // GET requests
+	APIKeyScopeWrite = "write" // any request to the data API
+	APIKeyScopeAdmin = "admin" // also managing API keys
+)
+
+var apiKeyScopeLevels = map[string]int{
+	APIKeyScopeRead:  1,
+	APIKeyScopeWrite: 2,
+	APIKeyScopeAdmin: 3,
+}
+
+// ValidAPIKeyScope reports whether scope is one of the known scopes
+func ValidAPIKeyScope(scope string) bool {
+	return apiKeyScopeLevels[scope] > 0
+}
+
+// Allows reports whether the key's scope covers the required one
+func (k *APIKey) Allows(scope string) bool {
+	return ValidAPIKeyScope(scope) && apiKeyScopeLevels[k.Scope] >= apiKeyScopeLevels[scope]
+}
+
+// IsExpired reports whether the key can no longer be used at the given time
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !k.ExpiresAt.After(now)
+}
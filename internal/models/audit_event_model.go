@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// AuditEvent records who did what to which target, in which workspace and in
+// which request. Events are only ever inserted: the database refuses to
+// update or delete them.
+type AuditEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"autoCreateTime:milli;index"`
+	// UserID and Username identify the actor and are empty for the
+	// application's own maintenance. The name is copied so that events
+	// outlive deleted accounts.
+	UserID   *uint
+	Username string `gorm:"type:text;not null;default:''"`
+	// APIKeyID is set when the action was made with an API key
+	APIKeyID    *uint
+	WorkspaceID *uint  `gorm:"index"`
+	Action      string `gorm:"type:text;not null"`
+	TargetType  string `gorm:"type:text;not null;default:''"`
+	TargetID    string `gorm:"type:text;not null;default:''"`
+	// Details holds action-specific data as a JSON object
+	Details   string `gorm:"type:text;not null;default:'{}'"`
+	RequestID string `gorm:"type:text;not null;default:''"`
+}
+
+// Audited actions
+const (
+	AuditGroupAdd        = "group.add"
+	AuditGroupRefresh    = "group.refresh"
+	AuditGroupRemove     = "group.remove"
+	AuditGroupTags       = "group.tags"
+	AuditGroupNotes      = "group.notes"
+	AuditGroupSyncCancel = "group.sync_cancel"
+	AuditGroupPurge      = "group.purge"
+	AuditSetCreate       = "set.create"
+	AuditSetUpdate       = "set.update"
+	AuditSetDelete       = "set.delete"
+	AuditWorkspaceCreate = "workspace.create"
+	AuditMemberAdd       = "member.add"
+	AuditMemberRole      = "member.role"
+	AuditMemberRemove    = "member.remove"
+	AuditAPIKeyCreate    = "api_key.create"
+	AuditAPIKeyRevoke    = "api_key.revoke"
+)
+
+// Audit target types
+const (
+	AuditTargetGroup     = "group"
+	AuditTargetSet       = "set"
+	AuditTargetWorkspace = "workspace"
+	AuditTargetUser      = "user"
+	AuditTargetAPIKey    = "api_key"
+)
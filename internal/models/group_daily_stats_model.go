@@ -0,0 +1,16 @@
+package models
+
+// GroupDailyStats is a per-day rollup of a group's wall activity.
+// Rows are maintained incrementally after each sync, so trend charts and
+// period comparisons never have to scan the raw posts table.
+type GroupDailyStats struct {
+	ID          uint   `gorm:"primaryKey" json:"-"`
+	GroupID     uint   `gorm:"not null;uniqueIndex:idx_group_daily_stats_group_day" json:"group_id"`
+	Day         string `gorm:"type:text;not null;uniqueIndex:idx_group_daily_stats_group_day" json:"day"`
+	Posts       int    `gorm:"not null;default:0" json:"posts"`
+	Views       int    `gorm:"not null;default:0" json:"views"`
+	Likes       int    `gorm:"not null;default:0" json:"likes"`
+	Comments    int    `gorm:"not null;default:0" json:"comments"`
+	Reposts     int    `gorm:"not null;default:0" json:"reposts"`
+	Subscribers int    `gorm:"not null;default:0" json:"subscribers"`
+}
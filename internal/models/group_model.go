@@ -3,9 +3,39 @@ package models
 import "time"
 
 type Group struct {
-	ID          uint      `gorm:"primaryKey"`
-	Domain      string    `gorm:"type:text;not null;uniqueIndex"`
-	Subscribers int       `gorm:"default:0"`
-	ParsedAt    time.Time `gorm:"autoCreateTime:milli"`
-	Posts       []Post
+	ID uint `gorm:"primaryKey"`
+	// VKID is the numeric VK community ID; it identifies the group across
+	// screen-name changes. Groups added before it was stored have it unset
+	// until their next sync.
+	VKID            *int   `gorm:"column:vk_id;uniqueIndex"`
+	Domain          string `gorm:"type:text;not null;index:idx_groups_domain_lookup"`
+	Name            string `gorm:"type:text;not null;default:''"`
+	PhotoURL        string `gorm:"type:text;not null;default:''"`
+	Type            string `gorm:"type:text;not null;default:''"`
+	Verified        bool   `gorm:"not null;default:false"`
+	IsClosed        int    `gorm:"not null;default:0"` // 0 open, 1 closed, 2 private
+	Status          string `gorm:"type:text;not null;default:'active';index"`
+	StatusChangedAt *time.Time
+	Subscribers     int       `gorm:"default:0"`
+	ParsedAt        time.Time `gorm:"autoCreateTime:milli"`
+	// Notes are kept per workspace in workspace_groups; they are only filled
+	// when groups are listed for a workspace
+	Notes string `gorm:"->"`
+	Posts []Post
+	Tags  []Tag `gorm:"many2many:group_tags"`
+}
+
+// Group statuses set by the sync
+const (
+	GroupStatusActive       = "active"
+	GroupStatusClosed       = "closed"
+	GroupStatusBanned       = "banned"
+	GroupStatusDeleted      = "deleted"
+	GroupStatusWallDisabled = "wall_disabled"
+)
+
+// IsActive reports whether the group's wall could be read on the last sync.
+// Groups created before statuses were tracked count as active.
+func (g *Group) IsActive() bool {
+	return g.Status == "" || g.Status == GroupStatusActive
 }
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// GroupScreenName records a screen name a group used before being renamed
+type GroupScreenName struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	GroupID    uint      `gorm:"not null;index" json:"group_id"`
+	ScreenName string    `gorm:"type:text;not null" json:"screen_name"`
+	RenamedTo  string    `gorm:"type:text;not null" json:"renamed_to"`
+	RenamedAt  time.Time `gorm:"not null" json:"renamed_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// GroupSet is a named collection of groups benchmarked against each other,
+// e.g. a client's community together with its competitors.
+type GroupSet struct {
+	ID          uint      `gorm:"primaryKey"`
+	WorkspaceID uint      `gorm:"not null;uniqueIndex:idx_group_sets_workspace_name"`
+	Name        string    `gorm:"type:text;not null;uniqueIndex:idx_group_sets_workspace_name"`
+	Description string    `gorm:"type:text;not null;default:''"`
+	CreatedAt   time.Time `gorm:"autoCreateTime:milli"`
+	Groups      []Group   `gorm:"many2many:group_set_members"`
+}
@@ -10,4 +10,5 @@ type Post struct {
 	Likes     int    `gorm:"not null"`
 	Text      string `gorm:"type:text;not null"`
 	Comments  int    `gorm:"not null"`
+	Reposts   int    `gorm:"not null;default:0"`
 }
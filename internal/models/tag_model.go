@@ -0,0 +1,10 @@
+package models
+
+// Tag is a free-form label attached to groups, e.g. a niche or region.
+// Every workspace has its own tags.
+type Tag struct {
+	ID          uint    `gorm:"primaryKey"`
+	WorkspaceID uint    `gorm:"not null;uniqueIndex:idx_tags_workspace_name"`
+	Name        string  `gorm:"type:text;not null;uniqueIndex:idx_tags_workspace_name"`
+	Groups      []Group `gorm:"many2many:group_tags"`
+}
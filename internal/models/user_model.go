@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// User is an account allowed to sign in to the web interface
+type User struct {
+	ID           uint       `gorm:"primaryKey"`
+	Username     string     `gorm:"type:text;not null;uniqueIndex"`
+	PasswordHash string     `gorm:"type:text;not null"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime:milli"`
+	LastLoginAt  *time.Time `gorm:"default:null"`
+}
+
+// Session is a signed-in browser. Only the SHA-256 hash of the cookie token
+// is stored, so a leaked database cannot be used to take over sessions.
+type Session struct {
+	TokenHash string `gorm:"type:text;primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	User      User
+	// WorkspaceID is the workspace the user last switched to; nil means the first one
+	WorkspaceID *uint
+	// CSRFToken must accompany every state-changing request of this session
+	CSRFToken  string    `gorm:"type:text;not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime:milli"`
+	LastSeenAt time.Time `gorm:"not null"`
+	ExpiresAt  time.Time `gorm:"not null;index"`
+}
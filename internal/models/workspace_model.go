@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Workspace keeps one client's tracked groups, tags, notes and group sets
+// apart from the others. Groups and their posts are stored once and shared by
+// every workspace tracking them.
+type Workspace struct {
+	ID        uint      `gorm:"primaryKey"`
+	Name      string    `gorm:"type:text;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime:milli"`
+}
+
+// WorkspaceMember gives a user access to a workspace with a role
+type WorkspaceMember struct {
+	WorkspaceID uint `gorm:"primaryKey"`
+	UserID      uint `gorm:"primaryKey;index"`
+	Workspace   Workspace
+	User        User
+	Role        string    `gorm:"type:text;not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime:milli"`
+}
+
+// WorkspaceGroup marks a group as tracked by a workspace, with the
+// workspace's own notes on it
+type WorkspaceGroup struct {
+	WorkspaceID uint      `gorm:"primaryKey"`
+	GroupID     uint      `gorm:"primaryKey;index"`
+	Notes       string    `gorm:"type:text;not null;default:''"`
+	AddedAt     time.Time `gorm:"autoCreateTime:milli"`
+}
+
+// Workspace roles; each one includes the ones before it
+const (
+	WorkspaceRoleViewer = "viewer" // sees the workspace's groups and reports
+	WorkspaceRoleEditor = "editor" // also adds groups and edits tags, notes and sets
+	WorkspaceRoleOwner  = "owner"  // also manages members
+)
+
+var workspaceRoleLevels = map[string]int{
+	WorkspaceRoleViewer: 1,
+	WorkspaceRoleEditor: 2,
+	WorkspaceRoleOwner:  3,
+}
+
+// ValidWorkspaceRole reports whether role is one of the known roles
+func ValidWorkspaceRole(role string) bool {
+	return workspaceRoleLevels[role] > 0
+}
+
+// Can reports whether the member's role covers the required one
+func (m *WorkspaceMember) Can(role string) bool {
+	return ValidWorkspaceRole(role) && workspaceRoleLevels[m.Role] >= workspaceRoleLevels[role]
+}
@@ -1 +0,0 @@
-package repo
\ No newline at end of file
@@ -0,0 +1,177 @@
+package repo
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"social-media-analyzer/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+// GroupFilter narrows group listings; zero value matches every group.
+// Requests always set WorkspaceID, so that a workspace only sees its own
+// groups, tags and notes.
+type GroupFilter struct {
+	WorkspaceID uint
+	Tag         string
+}
+
+// GroupRepository stores tracked groups and their screen-name history
+type GroupRepository interface {
+	// List returns the groups matching the filter with their tags loaded
+	List(filter GroupFilter) ([]models.Group, error)
+	GetByID(id uint) (*models.Group, error)
+	FindByVKID(vkID int) (*models.Group, error)
+	// FindUnidentified finds a group without a VK ID by its screen name, ignoring case
+	FindUnidentified(domain string) (*models.Group, error)
+	Create(group *models.Group) error
+	// UpdateInfo copies VK metadata from info onto group and records rename, if any,
+	// in the same transaction
+	UpdateInfo(group *models.Group, info *models.Group, rename *models.GroupScreenName) error
+	SetStatus(group *models.Group, status string, changedAt time.Time) error
+	// ScreenNames returns the previous screen names of a group, newest first
+	ScreenNames(groupID uint) ([]models.GroupScreenName, error)
+}
+
+// GormGroupRepository is a GroupRepository backed by the database
+type GormGroupRepository struct {
+	db *gorm.DB
+}
+
+func NewGormGroupRepository(db *gorm.DB) *GormGroupRepository {
+	return &GormGroupRepository{db: db}
+}
+
+// normalizedTag returns the filter tag the way tags are stored
+func (f GroupFilter) normalizedTag() string {
+	return strings.ToLower(strings.TrimSpace(f.Tag))
+}
+
+// Apply restricts a query on the groups table to groups matching the filter
+func (f GroupFilter) Apply(query *gorm.DB) *gorm.DB {
+	if f.WorkspaceID != 0 {
+		tracked := query.Session(&gorm.Session{NewDB: true}).
+			Table("workspace_groups").
+			Select("workspace_groups.group_id").
+			Where("workspace_groups.workspace_id = ?", f.WorkspaceID)
+		query = query.Where("groups.id IN (?)", tracked)
+	}
+
+	tag := f.normalizedTag()
+	if tag == "" {
+		return query
+	}
+	sub := query.Session(&gorm.Session{NewDB: true}).
+		Table("group_tags").
+		Select("group_tags.group_id").
+		Joins("JOIN tags ON tags.id = group_tags.tag_id").
+		Where("tags.name = ?", tag)
+	if f.WorkspaceID != 0 {
+		sub = sub.Where("tags.workspace_id = ?", f.WorkspaceID)
+	}
+	return query.Where("groups.id IN (?)", sub)
+}
+
+// Matches reports whether a group with loaded tags passes the filter
+func (f GroupFilter) Matches(group models.Group) bool {
+	tag := f.normalizedTag()
+	if tag == "" {
+		return true
+	}
+	for _, t := range group.Tags {
+		if t.Name == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *GormGroupRepository) List(filter GroupFilter) ([]models.Group, error) {
+	query := r.db.Preload("Tags")
+	if filter.WorkspaceID != 0 {
+		// Tags and notes of other workspaces stay out of the listing
+		query = r.db.Preload("Tags", "workspace_id = ?", filter.WorkspaceID).
+			Select("groups.*, workspace_groups.notes").
+			Joins("JOIN workspace_groups ON workspace_groups.group_id = groups.id AND workspace_groups.workspace_id = ?", filter.WorkspaceID)
+	}
+	var groups []models.Group
+	err := filter.Apply(query).Find(&groups).Error
+	return groups, err
+}
+
+func (r *GormGroupRepository) GetByID(id uint) (*models.Group, error) {
+	return r.first(r.db.Where("id = ?", id))
+}
+
+func (r *GormGroupRepository) FindByVKID(vkID int) (*models.Group, error) {
+	return r.first(r.db.Where("vk_id = ?", vkID))
+}
+
+func (r *GormGroupRepository) FindUnidentified(domain string) (*models.Group, error) {
+	return r.first(r.db.Where("vk_id IS NULL AND LOWER(domain) = ?", strings.ToLower(domain)))
+}
+
+func (r *GormGroupRepository) Create(group *models.Group) error {
+	return r.db.Create(group).Error
+}
+
+func (r *GormGroupRepository) UpdateInfo(group *models.Group, info *models.Group, rename *models.GroupScreenName) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if rename != nil {
+			if err := tx.Create(rename).Error; err != nil {
+				return err
+			}
+		}
+
+		// A map is used so that zero values like verified=false are written too
+		updates := map[string]interface{}{
+			"name":        info.Name,
+			"photo_url":   info.PhotoURL,
+			"type":        info.Type,
+			"verified":    info.Verified,
+			"is_closed":   info.IsClosed,
+			"subscribers": info.Subscribers,
+		}
+		if info.VKID != nil {
+			updates["vk_id"] = *info.VKID
+		}
+		if info.Domain != "" {
+			updates["domain"] = info.Domain
+		}
+		return tx.Model(group).Updates(updates).Error
+	})
+}
+
+func (r *GormGroupRepository) SetStatus(group *models.Group, status string, changedAt time.Time) error {
+	return r.db.Model(group).Updates(map[string]interface{}{
+		"status":            status,
+		"status_changed_at": changedAt,
+	}).Error
+}
+
+func (r *GormGroupRepository) ScreenNames(groupID uint) ([]models.GroupScreenName, error) {
+	history := []models.GroupScreenName{}
+	err := r.db.Where("group_id = ?", groupID).Order("renamed_at DESC, id DESC").Find(&history).Error
+	return history, err
+}
+
+// first loads the first group matching query, mapping a miss to ErrNotFound
+func (r *GormGroupRepository) first(query *gorm.DB) (*models.Group, error) {
+	var group models.Group
+	if err := query.First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+var (
+	_ GroupRepository = (*GormGroupRepository)(nil)
+	_ GroupRepository = (*MemoryGroupRepository)(nil)
+)
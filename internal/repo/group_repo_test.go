@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+)
+
+// TestGormGroupRepositorySQLite tests lookups, renames, tag filtering and
+// workspace listings against SQLite
+func TestGormGroupRepositorySQLite(t *testing.T) {
+	db := dbtest.New(t)
+	r := NewGormGroupRepository(db)
+	workspace := models.Workspace{Name: "acme"}
+	if err := db.Create(&workspace).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	vkID := 10
+	legacy := &models.Group{Domain: "Legacy"}
+	known := &models.Group{VKID: &vkID, Domain: "known", Tags: []models.Tag{{WorkspaceID: workspace.ID, Name: "news"}}}
+	for _, group := range []*models.Group{legacy, known} {
+		if err := r.Create(group); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if g, err := r.FindUnidentified("legacy"); err != nil || g.ID != legacy.ID {
+		t.Errorf("Expected legacy group %d, got %+v, %v", legacy.ID, g, err)
+	}
+	if _, err := r.FindUnidentified("known"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected identified group to be skipped, got %v", err)
+	}
+	if err := r.Create(&models.Group{VKID: &vkID, Domain: "copy"}); err == nil {
+		t.Error("Expected duplicate VK ID to be rejected")
+	}
+
+	rename := &models.GroupScreenName{GroupID: known.ID, ScreenName: "known", RenamedTo: "renamed", RenamedAt: time.Now().UTC()}
+	if err := r.UpdateInfo(known, &models.Group{Domain: "renamed", Name: "Renamed"}, rename); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.SetStatus(known, models.GroupStatusClosed, time.Now().UTC()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stored, err := r.FindByVKID(vkID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stored.Domain != "renamed" || stored.Status != models.GroupStatusClosed || stored.StatusChangedAt == nil {
+		t.Errorf("Unexpected stored group %+v", stored)
+	}
+	if history, _ := r.ScreenNames(known.ID); len(history) != 1 || history[0].RenamedTo != "renamed" {
+		t.Errorf("Expected one rename, got %+v", history)
+	}
+
+	tagged, err := r.List(GroupFilter{Tag: "News"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tagged) != 1 || tagged[0].ID != known.ID {
+		t.Errorf("Expected only the tagged group, got %+v", tagged)
+	}
+
+	if err := db.Create(&models.WorkspaceGroup{WorkspaceID: workspace.ID, GroupID: legacy.ID, Notes: "client"}).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tracked, err := r.List(GroupFilter{WorkspaceID: workspace.ID})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tracked) != 1 || tracked[0].ID != legacy.ID || tracked[0].Notes != "client" {
+		t.Errorf("Expected the tracked group with the workspace's notes, got %+v", tracked)
+	}
+	if tagged, _ := r.List(GroupFilter{WorkspaceID: workspace.ID, Tag: "news"}); len(tagged) != 0 {
+		t.Errorf("Expected tags of other workspaces to be ignored, got %+v", tagged)
+	}
+}
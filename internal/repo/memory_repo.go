@@ -0,0 +1,221 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"social-media-analyzer/internal/models"
+)
+
+// MemoryGroupRepository is an in-memory GroupRepository for tests
+type MemoryGroupRepository struct {
+	mu          sync.Mutex
+	groups      []models.Group
+	screenNames []models.GroupScreenName
+	// tracked holds the group IDs on each workspace's list
+	tracked map[uint]map[uint]bool
+	nextID  uint
+}
+
+// NewMemoryGroupRepository returns a repository holding the given groups;
+// groups without an ID get one assigned
+func NewMemoryGroupRepository(groups ...models.Group) *MemoryGroupRepository {
+	r := &MemoryGroupRepository{}
+	for _, group := range groups {
+		r.Create(&group)
+	}
+	return r
+}
+
+func (r *MemoryGroupRepository) List(filter GroupFilter) ([]models.Group, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var groups []models.Group
+	for _, group := range r.groups {
+		if filter.WorkspaceID != 0 && !r.tracked[filter.WorkspaceID][group.ID] {
+			continue
+		}
+		if filter.Matches(group) {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// Track puts groups on a workspace's list, like workspace_groups rows do
+func (r *MemoryGroupRepository) Track(workspaceID uint, groupIDs ...uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tracked == nil {
+		r.tracked = make(map[uint]map[uint]bool)
+	}
+	if r.tracked[workspaceID] == nil {
+		r.tracked[workspaceID] = make(map[uint]bool)
+	}
+	for _, id := range groupIDs {
+		r.tracked[workspaceID][id] = true
+	}
+}
+
+func (r *MemoryGroupRepository) GetByID(id uint) (*models.Group, error) {
+	return r.find(func(g *models.Group) bool { return g.ID == id })
+}
+
+func (r *MemoryGroupRepository) FindByVKID(vkID int) (*models.Group, error) {
+	return r.find(func(g *models.Group) bool { return g.VKID != nil && *g.VKID == vkID })
+}
+
+func (r *MemoryGroupRepository) FindUnidentified(domain string) (*models.Group, error) {
+	return r.find(func(g *models.Group) bool { return g.VKID == nil && strings.EqualFold(g.Domain, domain) })
+}
+
+func (r *MemoryGroupRepository) Create(group *models.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if group.VKID != nil {
+		for _, g := range r.groups {
+			if g.VKID != nil && *g.VKID == *group.VKID {
+				return fmt.Errorf("duplicate vk_id %d", *group.VKID)
+			}
+		}
+	}
+	if group.ID == 0 {
+		r.nextID++
+		group.ID = r.nextID
+	} else if group.ID > r.nextID {
+		r.nextID = group.ID
+	}
+	if group.Status == "" {
+		group.Status = models.GroupStatusActive
+	}
+	r.groups = append(r.groups, *group)
+	return nil
+}
+
+func (r *MemoryGroupRepository) UpdateInfo(group *models.Group, info *models.Group, rename *models.GroupScreenName) error {
+	return r.update(group, func(g *models.Group) {
+		g.Name = info.Name
+		g.PhotoURL = info.PhotoURL
+		g.Type = info.Type
+		g.Verified = info.Verified
+		g.IsClosed = info.IsClosed
+		g.Subscribers = info.Subscribers
+		if info.VKID != nil {
+			vkID := *info.VKID
+			g.VKID = &vkID
+		}
+		if info.Domain != "" {
+			g.Domain = info.Domain
+		}
+		if rename != nil {
+			r.screenNames = append(r.screenNames, *rename)
+		}
+	})
+}
+
+func (r *MemoryGroupRepository) SetStatus(group *models.Group, status string, changedAt time.Time) error {
+	return r.update(group, func(g *models.Group) {
+		g.Status = status
+		g.StatusChangedAt = &changedAt
+	})
+}
+
+func (r *MemoryGroupRepository) ScreenNames(groupID uint) ([]models.GroupScreenName, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	history := []models.GroupScreenName{}
+	for _, rename := range r.screenNames {
+		if rename.GroupID == groupID {
+			history = append(history, rename)
+		}
+	}
+	sort.SliceStable(history, func(a, b int) bool {
+		return history[a].RenamedAt.After(history[b].RenamedAt)
+	})
+	return history, nil
+}
+
+// find returns a copy of the first group matching the predicate
+func (r *MemoryGroupRepository) find(match func(*models.Group) bool) (*models.Group, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.groups {
+		if match(&r.groups[i]) {
+			group := r.groups[i]
+			return &group, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// update applies fn to the stored group and mirrors the result into group
+func (r *MemoryGroupRepository) update(group *models.Group, fn func(*models.Group)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.groups {
+		if r.groups[i].ID == group.ID {
+			fn(&r.groups[i])
+			tags := group.Tags
+			*group = r.groups[i]
+			group.Tags = tags
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// MemoryPostRepository is an in-memory PostRepository for tests
+type MemoryPostRepository struct {
+	mu     sync.Mutex
+	posts  map[uint][]models.Post
+	nextID uint
+}
+
+// NewMemoryPostRepository returns a repository holding the given posts
+func NewMemoryPostRepository(posts ...models.Post) *MemoryPostRepository {
+	r := &MemoryPostRepository{posts: make(map[uint][]models.Post)}
+	for _, post := range posts {
+		r.add(post)
+	}
+	return r
+}
+
+func (r *MemoryPostRepository) ListByGroup(groupID uint) ([]models.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]models.Post(nil), r.posts[groupID]...), nil
+}
+
+func (r *MemoryPostRepository) ReplaceForGroup(groupID uint, posts []models.Post) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.posts, groupID)
+	for _, post := range posts {
+		post.GroupID = groupID
+		r.add(post)
+	}
+	return nil
+}
+
+// add stores a post, assigning an ID if it has none; callers hold the lock
+// except during construction
+func (r *MemoryPostRepository) add(post models.Post) {
+	if post.ID == 0 {
+		r.nextID++
+		post.ID = r.nextID
+	} else if post.ID > r.nextID {
+		r.nextID = post.ID
+	}
+	r.posts[post.GroupID] = append(r.posts[post.GroupID], post)
+}
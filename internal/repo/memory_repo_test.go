@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"social-media-analyzer/internal/models"
+)
+
+// TestMemoryGroupRepository tests lookups and updates of the in-memory groups
+func TestMemoryGroupRepository(t *testing.T) {
+	vkID := 10
+	r := NewMemoryGroupRepository(
+		models.Group{Domain: "legacy"},
+		models.Group{VKID: &vkID, Domain: "known", Tags: []models.Tag{{Name: "news"}}},
+	)
+
+	if g, err := r.FindUnidentified("LEGACY"); err != nil || g.ID != 1 {
+		t.Errorf("Expected legacy group 1, got %+v, %v", g, err)
+	}
+	if _, err := r.FindUnidentified("known"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected identified group to be skipped, got %v", err)
+	}
+	if err := r.Create(&models.Group{VKID: &vkID}); err == nil {
+		t.Error("Expected duplicate VK ID to be rejected")
+	}
+
+	group, err := r.FindByVKID(vkID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.SetStatus(group, models.GroupStatusClosed, time.Now()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stored, _ := r.GetByID(group.ID)
+	if stored.Status != models.GroupStatusClosed || group.Status != models.GroupStatusClosed {
+		t.Errorf("Expected status to be stored and mirrored, got %q and %q", stored.Status, group.Status)
+	}
+
+	tagged, _ := r.List(GroupFilter{Tag: "News"})
+	if len(tagged) != 1 || tagged[0].ID != group.ID {
+		t.Errorf("Expected only the tagged group, got %+v", tagged)
+	}
+
+	r.Track(1, 1)
+	if tracked, _ := r.List(GroupFilter{WorkspaceID: 1}); len(tracked) != 1 || tracked[0].ID != 1 {
+		t.Errorf("Expected only the tracked group, got %+v", tracked)
+	}
+}
+
+// TestMemoryPostRepositoryReplace tests replacing the posts of one group
+func TestMemoryPostRepositoryReplace(t *testing.T) {
+	r := NewMemoryPostRepository(
+		models.Post{GroupID: 1, Likes: 1},
+		models.Post{GroupID: 1, Likes: 2},
+		models.Post{GroupID: 2, Likes: 3},
+	)
+
+	if err := r.ReplaceForGroup(1, []models.Post{{Likes: 5}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	posts, _ := r.ListByGroup(1)
+	if len(posts) != 1 || posts[0].Likes != 5 || posts[0].GroupID != 1 {
+		t.Errorf("Expected the single replacement post, got %+v", posts)
+	}
+	if other, _ := r.ListByGroup(2); len(other) != 1 {
+		t.Errorf("Expected other groups to be untouched, got %+v", other)
+	}
+}
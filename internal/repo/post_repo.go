@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"social-media-analyzer/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PostRepository stores the parsed wall posts of groups
+type PostRepository interface {
+	ListByGroup(groupID uint) ([]models.Post, error)
+	// ReplaceForGroup atomically replaces all stored posts of a group
+	ReplaceForGroup(groupID uint, posts []models.Post) error
+}
+
+// GormPostRepository is a PostRepository backed by the database
+type GormPostRepository struct {
+	db *gorm.DB
+}
+
+func NewGormPostRepository(db *gorm.DB) *GormPostRepository {
+	return &GormPostRepository{db: db}
+}
+
+func (r *GormPostRepository) ListByGroup(groupID uint) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.Where("group_id = ?", groupID).Find(&posts).Error
+	return posts, err
+}
+
+func (r *GormPostRepository) ReplaceForGroup(groupID uint, posts []models.Post) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", groupID).Delete(&models.Post{}).Error; err != nil {
+			return err
+		}
+		if len(posts) == 0 {
+			return nil
+		}
+		for i := range posts {
+			posts[i].GroupID = groupID
+		}
+		return tx.Create(&posts).Error
+	})
+}
+
+var (
+	_ PostRepository = (*GormPostRepository)(nil)
+	_ PostRepository = (*MemoryPostRepository)(nil)
+)
@@ -0,0 +1,36 @@
+package repo
+
+import (
+	"testing"
+
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+)
+
+// TestGormPostRepositoryReplaceSQLite tests that replacing posts leaves other groups untouched
+func TestGormPostRepositoryReplaceSQLite(t *testing.T) {
+	db := dbtest.New(t)
+	groups := []models.Group{{Domain: "one"}, {Domain: "two"}}
+	if err := db.Create(&groups).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r := NewGormPostRepository(db)
+
+	if err := r.ReplaceForGroup(groups[0].ID, []models.Post{{Date: "2025-12-01"}, {Date: "2025-12-02"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.ReplaceForGroup(groups[1].ID, []models.Post{{Date: "2025-12-01"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.ReplaceForGroup(groups[0].ID, []models.Post{{Date: "2025-12-03", Likes: 3}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	first, _ := r.ListByGroup(groups[0].ID)
+	if len(first) != 1 || first[0].Likes != 3 || first[0].GroupID != groups[0].ID {
+		t.Errorf("Expected the single replacement post, got %+v", first)
+	}
+	if second, _ := r.ListByGroup(groups[1].ID); len(second) != 1 {
+		t.Errorf("Expected the other group's post to be kept, got %+v", second)
+	}
+}
@@ -4,20 +4,27 @@ import (
 	"log"
 
 	"social-media-analyzer/internal/models"
-	"gorm.io/gorm"
+	"social-media-analyzer/internal/repo"
 )
 
 type GroupStats struct {
-	ID                 uint
-	Domain             string
-	Subscribers        int
-	ParsedAt           string
-	TotalPosts         int
-	TotalLikes         int
-	AvgLikesPerPost    float64
-	MaxLikesPerPost    int
-	AvgCommentsPerPost float64
-	PostsLastWeek      int
+	ID                 uint     `json:"id"`
+	VKID               *int     `json:"vk_id"`
+	Domain             string   `json:"domain"`
+	Name               string   `json:"name"`
+	PhotoURL           string   `json:"photo_url"`
+	Verified           bool     `json:"verified"`
+	Status             string   `json:"status"`
+	Subscribers        int      `json:"subscribers"`
+	ParsedAt           string   `json:"parsed_at"`
+	TotalPosts         int      `json:"total_posts"`
+	TotalLikes         int      `json:"total_likes"`
+	AvgLikesPerPost    float64  `json:"avg_likes_per_post"`
+	MaxLikesPerPost    int      `json:"max_likes_per_post"`
+	AvgCommentsPerPost float64  `json:"avg_comments_per_post"`
+	PostsLastWeek      int      `json:"posts_last_week"`
+	Tags               []string `json:"tags"`
+	Notes              string   `json:"notes"`
 }
 
 type ChartData struct {
@@ -27,17 +34,18 @@ type ChartData struct {
 }
 
 type AnalyticsService struct {
-	db *gorm.DB
+	groups repo.GroupRepository
+	posts  repo.PostRepository
 }
 
-func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
-	return &AnalyticsService{db: db}
+func NewAnalyticsService(groups repo.GroupRepository, posts repo.PostRepository) *AnalyticsService {
+	return &AnalyticsService{groups: groups, posts: posts}
 }
 
-// CalculateGroupStats calculates statistics for all groups
-func (as *AnalyticsService) CalculateGroupStats() ([]GroupStats, error) {
-	var groups []models.Group
-	if err := as.db.Find(&groups).Error; err != nil {
+// CalculateGroupStats calculates statistics for all groups matching the filter
+func (as *AnalyticsService) CalculateGroupStats(filter GroupFilter) ([]GroupStats, error) {
+	groups, err := as.groups.List(filter)
+	if err != nil {
 		return nil, err
 	}
 
@@ -52,8 +60,8 @@ func (as *AnalyticsService) CalculateGroupStats() ([]GroupStats, error) {
 
 // calculateGroupStat calculates statistics for a single group
 func (as *AnalyticsService) calculateGroupStat(group models.Group) GroupStats {
-	var posts []models.Post
-	if err := as.db.Where("group_id = ?", group.ID).Find(&posts).Error; err != nil {
+	posts, err := as.posts.ListByGroup(group.ID)
+	if err != nil {
 		log.Printf("Failed to fetch posts for group %s: %v\n", group.Domain, err)
 	}
 
@@ -64,10 +72,17 @@ func (as *AnalyticsService) calculateGroupStat(group models.Group) GroupStats {
 
 	stats := GroupStats{
 		ID:         group.ID,
+		VKID:       group.VKID,
 		Domain:     group.Domain,
+		Name:       group.Name,
+		PhotoURL:   group.PhotoURL,
+		Verified:   group.Verified,
+		Status:     group.Status,
 		Subscribers: group.Subscribers,
 		ParsedAt:   parsedAt,
 		TotalPosts: len(posts),
+		Tags:       tagNames(group.Tags),
+		Notes:      group.Notes,
 	}
 
 	if len(posts) > 0 {
@@ -92,13 +107,21 @@ func (as *AnalyticsService) calculateGroupStat(group models.Group) GroupStats {
 	return stats
 }
 
-// CalculateChartData calculates data for charts (dependence of likes/comments on subscribers)
-func (as *AnalyticsService) CalculateChartData() (ChartData, error) {
-	stats, err := as.CalculateGroupStats()
+// CalculateChartData calculates data for charts (dependence of likes/comments on subscribers).
+// Groups whose wall could not be read on the last sync are left out, their numbers being stale.
+func (as *AnalyticsService) CalculateChartData(filter GroupFilter) (ChartData, error) {
+	all, err := as.CalculateGroupStats(filter)
 	if err != nil {
 		return ChartData{}, err
 	}
 
+	var stats []GroupStats
+	for _, stat := range all {
+		if stat.Status == "" || stat.Status == models.GroupStatusActive {
+			stats = append(stats, stat)
+		}
+	}
+
 	chartData := ChartData{
 		Subscribers: make([]int, len(stats)),
 		AvgLikes:    make([]float64, len(stats)),
@@ -5,42 +5,16 @@ import (
 	"time"
 
 	"social-media-analyzer/internal/models"
-	"gorm.io/gorm"
+	"social-media-analyzer/internal/repo"
 )
 
-// MockDB represents a mock database for testing
-type MockDB struct {
-	groups []models.Group
-	posts  []models.Post
-	err    error
-}
-
-// MockQueryResult represents a mock query result
-type MockQueryResult struct {
-	db  *MockDB
-	err error
-}
-
-// Find mock implementation
-func (mq *MockQueryResult) Find(dest interface{}, conds ...interface{}) *gorm.DB {
-	if mq.err != nil {
-		return &gorm.DB{Error: mq.err}
+// newMemoryAnalyticsService returns an analytics service over in-memory repositories
+func newMemoryAnalyticsService(groups []models.Group, posts map[uint][]models.Post) *AnalyticsService {
+	postRepo := repo.NewMemoryPostRepository()
+	for groupID, groupPosts := range posts {
+		postRepo.ReplaceForGroup(groupID, groupPosts)
 	}
-
-	// Type assert to get the pointer to slice
-	switch v := dest.(type) {
-	case *[]models.Group:
-		*v = mq.db.groups
-	case *[]models.Post:
-		*v = mq.db.posts
-	}
-
-	return &gorm.DB{Error: nil}
-}
-
-// Error method for gorm.DB compatibility
-func (mq *MockQueryResult) Error() error {
-	return mq.err
+	return NewAnalyticsService(repo.NewMemoryGroupRepository(groups...), postRepo)
 }
 
 // TestCalculateGroupStats tests the CalculateGroupStats method
@@ -98,12 +72,18 @@ func TestCalculateGroupStats(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Verify expected count
-			if len(tt.mockGroups) != tt.expectedCount {
-				// This test validates the test setup
-				stats := make([]GroupStats, len(tt.mockGroups))
-				if len(stats) != tt.expectedCount {
-					t.Errorf("Expected %d groups, got %d", tt.expectedCount, len(stats))
+			service := newMemoryAnalyticsService(tt.mockGroups, tt.mockPosts)
+
+			stats, err := service.CalculateGroupStats(GroupFilter{})
+			if (err != nil) != tt.shouldError {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(stats) != tt.expectedCount {
+				t.Errorf("Expected %d groups, got %d", tt.expectedCount, len(stats))
+			}
+			for _, stat := range stats {
+				if stat.TotalPosts != len(tt.mockPosts[stat.ID]) {
+					t.Errorf("Group %d: expected %d posts, got %d", stat.ID, len(tt.mockPosts[stat.ID]), stat.TotalPosts)
 				}
 			}
 		})
@@ -188,37 +168,57 @@ func TestCalculateGroupStat(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Calculate totals for validation
-			if len(tt.posts) > 0 {
-				totalLikes := 0
-				maxLikes := 0
-				totalComments := 0
+			service := newMemoryAnalyticsService(nil, map[uint][]models.Post{tt.group.ID: tt.posts})
+			stats := service.calculateGroupStat(tt.group)
 
-				for _, post := range tt.posts {
-					totalLikes += post.Likes
-					totalComments += post.Comments
-					if post.Likes > maxLikes {
-						maxLikes = post.Likes
-					}
-				}
-
-				avgLikes := float64(totalLikes) / float64(len(tt.posts))
-				avgComments := float64(totalComments) / float64(len(tt.posts))
-
-				if avgLikes != tt.expectedAvgLikes {
-					t.Errorf("Expected avg likes %.2f, got %.2f", tt.expectedAvgLikes, avgLikes)
-				}
-				if maxLikes != tt.expectedMaxLikes {
-					t.Errorf("Expected max likes %d, got %d", tt.expectedMaxLikes, maxLikes)
-				}
-				if avgComments != tt.expectedAvgComments {
-					t.Errorf("Expected avg comments %.2f, got %.2f", tt.expectedAvgComments, avgComments)
-				}
+			if stats.AvgLikesPerPost != tt.expectedAvgLikes {
+				t.Errorf("Expected avg likes %.2f, got %.2f", tt.expectedAvgLikes, stats.AvgLikesPerPost)
+			}
+			if stats.MaxLikesPerPost != tt.expectedMaxLikes {
+				t.Errorf("Expected max likes %d, got %d", tt.expectedMaxLikes, stats.MaxLikesPerPost)
+			}
+			if stats.AvgCommentsPerPost != tt.expectedAvgComments {
+				t.Errorf("Expected avg comments %.2f, got %.2f", tt.expectedAvgComments, stats.AvgCommentsPerPost)
 			}
 		})
 	}
 }
 
+// TestCalculateGroupStatsByTag tests filtering groups by tag
+func TestCalculateGroupStatsByTag(t *testing.T) {
+	service := newMemoryAnalyticsService([]models.Group{
+		{ID: 1, Domain: "tagged", Tags: []models.Tag{{Name: "retail"}}},
+		{ID: 2, Domain: "untagged"},
+	}, nil)
+
+	stats, err := service.CalculateGroupStats(GroupFilter{Tag: " Retail "})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Domain != "tagged" {
+		t.Fatalf("Expected only the tagged group, got %+v", stats)
+	}
+	if len(stats[0].Tags) != 1 || stats[0].Tags[0] != "retail" {
+		t.Errorf("Expected tags [retail], got %v", stats[0].Tags)
+	}
+}
+
+// TestCalculateChartDataSkipsInactiveGroups tests that stale groups are left out of charts
+func TestCalculateChartDataSkipsInactiveGroups(t *testing.T) {
+	service := newMemoryAnalyticsService([]models.Group{
+		{ID: 1, Domain: "active", Subscribers: 100},
+		{ID: 2, Domain: "banned", Subscribers: 200, Status: models.GroupStatusBanned},
+	}, nil)
+
+	chart, err := service.CalculateChartData(GroupFilter{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chart.Subscribers) != 1 || chart.Subscribers[0] != 100 {
+		t.Errorf("Expected only the active group, got %v", chart.Subscribers)
+	}
+}
+
 // TestChartDataStructure validates ChartData structure
 func TestChartDataStructure(t *testing.T) {
 	chartData := ChartData{
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"social-media-analyzer/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrAPIKeyInvalid  = errors.New("invalid api key")
+)
+
+// apiKeyPrefix starts every key, so leaked keys are easy to find in logs and repositories
+const apiKeyPrefix = "sma_"
+
+// lastUsedInterval limits how often last_used_at is written for a busy key
+const lastUsedInterval = time.Minute
+
+// APIKeyService manages the API keys of user accounts
+type APIKeyService struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+// APIKeyInput describes a new key; ExpiresInDays 0 creates a key that never expires
+type APIKeyInput struct {
+	Name          string `json:"name"`
+	Scope         string `json:"scope"`
+	ExpiresInDays int    `json:"expires_in_days"`
+}
+
+// CreatedAPIKey is a new key with its secret, which is only available once
+type CreatedAPIKey struct {
+	Key    string
+	APIKey *models.APIKey
+}
+
+func NewAPIKeyService(db *gorm.DB) *APIKeyService {
+	return &APIKeyService{db: db, now: time.Now}
+}
+
+// Create issues a key for a user that works in one workspace. The key looks like
+// sma_<prefix>_<secret>; the prefix part is stored to tell keys apart, the whole
+// key only as a hash.
+func (ks *APIKeyService) Create(userID, workspaceID uint, input APIKeyInput) (*CreatedAPIKey, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return nil, fmt.Errorf("%w: name must be 1-100 characters", ErrAPIKeyInvalid)
+	}
+	if !models.ValidAPIKeyScope(input.Scope) {
+		return nil, fmt.Errorf("%w: scope must be %s, %s or %s", ErrAPIKeyInvalid, models.APIKeyScopeRead, models.APIKeyScopeWrite, models.APIKeyScopeAdmin)
+	}
+	if input.ExpiresInDays < 0 {
+		return nil, fmt.Errorf("%w: expires_in_days must not be negative", ErrAPIKeyInvalid)
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	prefix := apiKeyPrefix + secret[:12]
+	key := prefix + "_" + secret[12:]
+
+	apiKey := models.APIKey{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		Name:        name,
+		Prefix:      prefix,
+		KeyHash:     hashToken(key),
+		Scope:       input.Scope,
+	}
+	if input.ExpiresInDays > 0 {
+		expiresAt := ks.now().UTC().AddDate(0, 0, input.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+	if err := ks.db.Omit(clause.Associations).Create(&apiKey).Error; err != nil {
+		return nil, err
+	}
+	return &CreatedAPIKey{Key: key, APIKey: &apiKey}, nil
+}
+
+// List returns a user's keys with their workspaces, newest first: the keys
+// in one workspace, or in all of them when workspaceID is zero
+func (ks *APIKeyService) List(userID, workspaceID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := ks.userKeys(userID, workspaceID).Preload("Workspace").Order("created_at DESC, id DESC").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke deletes one of a user's keys and returns it; keys of other users,
+// and outside the workspace unless workspaceID is zero, are reported as not found
+func (ks *APIKeyService) Revoke(userID, workspaceID, id uint) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := ks.userKeys(userID, workspaceID).Where("id = ?", id).First(&apiKey).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := ks.db.Delete(&apiKey)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrAPIKeyNotFound
+	}
+	return &apiKey, nil
+}
+
+// Authenticate returns the unexpired key with its user and records that it was used
+func (ks *APIKeyService) Authenticate(key string) (*models.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrAPIKeyNotFound
+	}
+	var apiKey models.APIKey
+	err := ks.db.Preload("User").Where("key_hash = ?", hashToken(key)).First(&apiKey).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := ks.now().UTC()
+	if apiKey.IsExpired(now) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedInterval {
+		apiKey.LastUsedAt = &now
+		if err := ks.db.Model(&apiKey).Omit(clause.Associations).Update("last_used_at", now).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &apiKey, nil
+}
+
+// userKeys selects a user's keys, limited to one workspace unless workspaceID is zero
+func (ks *APIKeyService) userKeys(userID, workspaceID uint) *gorm.DB {
+	query := ks.db.Where("user_id = ?", userID)
+	if workspaceID != 0 {
+		query = query.Where("workspace_id = ?", workspaceID)
+	}
+	return query
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"social-media-analyzer/internal/config"
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+)
+
+func newTestAPIKeyService(t *testing.T) (*APIKeyService, *models.User, uint) {
+	db := dbtest.New(t)
+	user, err := NewAuthService(db, config.AuthConfig{SessionTTL: time.Hour}).CreateUser("anna", "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	workspace, err := NewWorkspaceService(db).Create(user.ID, "Acme")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return NewAPIKeyService(db), user, workspace.ID
+}
+
+// TestCreateAPIKey tests the key format, hashing and input validation
+func TestCreateAPIKey(t *testing.T) {
+	ks, user, workspaceID := newTestAPIKeyService(t)
+
+	created, err := ks.Create(user.ID, workspaceID, APIKeyInput{Name: " BI export ", Scope: models.APIKeyScopeRead, ExpiresInDays: 30})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(created.Key, created.APIKey.Prefix+"_") || !strings.HasPrefix(created.Key, "sma_") {
+		t.Errorf("Expected the key to start with its prefix, got %q and %q", created.Key, created.APIKey.Prefix)
+	}
+	if created.APIKey.Name != "BI export" || created.APIKey.KeyHash == created.Key || created.APIKey.ExpiresAt == nil {
+		t.Errorf("Unexpected key %+v", created.APIKey)
+	}
+
+	for _, input := range []APIKeyInput{
+		{Name: "", Scope: models.APIKeyScopeRead},
+		{Name: "key", Scope: "root"},
+		{Name: "key", Scope: models.APIKeyScopeRead, ExpiresInDays: -1},
+	} {
+		if _, err := ks.Create(user.ID, workspaceID, input); !errors.Is(err, ErrAPIKeyInvalid) {
+			t.Errorf("Create(%+v): expected ErrAPIKeyInvalid, got %v", input, err)
+		}
+	}
+}
+
+// TestAuthenticateAPIKey tests key lookup, expiry and last-used tracking
+func TestAuthenticateAPIKey(t *testing.T) {
+	ks, user, workspaceID := newTestAPIKeyService(t)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	ks.now = func() time.Time { return now }
+
+	created, err := ks.Create(user.ID, workspaceID, APIKeyInput{Name: "script", Scope: models.APIKeyScopeWrite, ExpiresInDays: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	key, err := ks.Authenticate(created.Key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if key.User.Username != "anna" || key.WorkspaceID != workspaceID || key.LastUsedAt == nil || !key.LastUsedAt.Equal(now) {
+		t.Errorf("Expected the key with its user and last use, got %+v", key)
+	}
+	if !key.Allows(models.APIKeyScopeRead) || key.Allows(models.APIKeyScopeAdmin) {
+		t.Errorf("Expected a write key to allow read but not admin")
+	}
+
+	for _, bad := range []string{"", "sma_unknown", created.APIKey.Prefix, strings.TrimPrefix(created.Key, "sma_")} {
+		if _, err := ks.Authenticate(bad); !errors.Is(err, ErrAPIKeyNotFound) {
+			t.Errorf("Authenticate(%q): expected ErrAPIKeyNotFound, got %v", bad, err)
+		}
+	}
+
+	now = now.Add(25 * time.Hour)
+	if _, err := ks.Authenticate(created.Key); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected an expired key to be rejected, got %v", err)
+	}
+}
+
+// TestRevokeAPIKey tests that users can only revoke their own keys
+func TestRevokeAPIKey(t *testing.T) {
+	ks, user, workspaceID := newTestAPIKeyService(t)
+	created, err := ks.Create(user.ID, workspaceID, APIKeyInput{Name: "script", Scope: models.APIKeyScopeRead})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := ks.Revoke(user.ID+1, 0, created.APIKey.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected ErrAPIKeyNotFound for another user, got %v", err)
+	}
+	if _, err := ks.Revoke(user.ID, 0, created.APIKey.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := ks.Authenticate(created.Key); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected a revoked key to be rejected, got %v", err)
+	}
+	if keys, _ := ks.List(user.ID, 0); len(keys) != 0 {
+		t.Errorf("Expected no keys left, got %d", len(keys))
+	}
+}
+
+// TestAPIKeysScopedToWorkspace tests that a workspace filter hides the user's
+// keys in other workspaces
+func TestAPIKeysScopedToWorkspace(t *testing.T) {
+	ks, user, workspaceID := newTestAPIKeyService(t)
+	other, err := NewWorkspaceService(ks.db).Create(user.ID, "Other")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	mine, err := ks.Create(user.ID, workspaceID, APIKeyInput{Name: "mine", Scope: models.APIKeyScopeAdmin})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	theirs, err := ks.Create(user.ID, other.ID, APIKeyInput{Name: "theirs", Scope: models.APIKeyScopeAdmin})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	keys, err := ks.List(user.ID, workspaceID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].ID != mine.APIKey.ID {
+		t.Errorf("Expected only the key of the workspace, got %+v", keys)
+	}
+	if keys, _ := ks.List(user.ID, 0); len(keys) != 2 {
+		t.Errorf("Expected keys in all workspaces, got %d", len(keys))
+	}
+	if _, err := ks.Revoke(user.ID, workspaceID, theirs.APIKey.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected ErrAPIKeyNotFound for a key of another workspace, got %v", err)
+	}
+	if _, err := ks.Authenticate(theirs.Key); err != nil {
+		t.Errorf("Expected the key of another workspace to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"social-media-analyzer/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrAuditFilterInvalid = errors.New("invalid audit filter")
+
+// Audit listings return DefaultAuditLimit events per page, at most MaxAuditLimit
+const (
+	DefaultAuditLimit = 50
+	MaxAuditLimit     = 500
+)
+
+// AuditService appends events to the audit log and reads them back
+type AuditService struct {
+	db *gorm.DB
+}
+
+// AuditFilter narrows an audit listing of one workspace. From and To are
+// inclusive days in DayLayout; BeforeID continues a listing below the
+// last event of the previous page.
+type AuditFilter struct {
+	WorkspaceID uint
+	Action      string
+	Actor       string
+	TargetType  string
+	TargetID    string
+	From        string
+	To          string
+	BeforeID    uint
+	Limit       int
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Record appends events to the log
+func (as *AuditService) Record(events ...models.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return as.db.Create(&events).Error
+}
+
+// AuditDetails encodes action-specific data for AuditEvent.Details; nil
+// becomes an empty object
+func AuditDetails(v interface{}) string {
+	if v == nil {
+		return "{}"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// AuditTargetID formats a numeric target ID for AuditEvent.TargetID
+func AuditTargetID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// List returns a workspace's events matching the filter, newest first
+func (as *AuditService) List(filter AuditFilter) ([]models.AuditEvent, error) {
+	query := as.db.Where("workspace_id = ?", filter.WorkspaceID)
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Actor != "" {
+		query = query.Where("username = ?", NormalizeUsername(filter.Actor))
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != "" {
+		from, err := time.Parse(DayLayout, filter.From)
+		if err != nil {
+			return nil, fmt.Errorf("%w: date %q, expected YYYY-MM-DD", ErrAuditFilterInvalid, filter.From)
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if filter.To != "" {
+		to, err := time.Parse(DayLayout, filter.To)
+		if err != nil {
+			return nil, fmt.Errorf("%w: date %q, expected YYYY-MM-DD", ErrAuditFilterInvalid, filter.To)
+		}
+		query = query.Where("created_at < ?", to.AddDate(0, 0, 1))
+	}
+	if filter.BeforeID > 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	limit := filter.Limit
+	if limit < 0 || limit > MaxAuditLimit {
+		return nil, fmt.Errorf("%w: limit must be 1-%d", ErrAuditFilterInvalid, MaxAuditLimit)
+	}
+	if limit == 0 {
+		limit = DefaultAuditLimit
+	}
+
+	var events []models.AuditEvent
+	err := query.Order("id DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
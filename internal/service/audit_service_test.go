@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+)
+
+// TestAuditList tests filtering and paging a workspace's audit log
+func TestAuditList(t *testing.T) {
+	db := dbtest.New(t)
+	as := NewAuditService(db)
+	first, second := uint(1), uint(2)
+	anna := uint(10)
+
+	err := as.Record(
+		models.AuditEvent{WorkspaceID: &first, UserID: &anna, Username: "anna", Action: models.AuditGroupAdd, TargetType: models.AuditTargetGroup, TargetID: "5"},
+		models.AuditEvent{WorkspaceID: &first, UserID: &anna, Username: "anna", Action: models.AuditSetUpdate, TargetType: models.AuditTargetSet, TargetID: "3",
+			Details: AuditDetails(map[string][]uint{"removed_groups": {5}})},
+		models.AuditEvent{WorkspaceID: &first, Username: "boris", Action: models.AuditGroupTags, TargetType: models.AuditTargetGroup, TargetID: "5"},
+		models.AuditEvent{WorkspaceID: &second, Username: "anna", Action: models.AuditGroupAdd, TargetType: models.AuditTargetGroup, TargetID: "5"},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	all, err := as.List(AuditFilter{WorkspaceID: first})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(all) != 3 || all[0].Username != "boris" {
+		t.Fatalf("Expected 3 events of the first workspace, newest first, got %+v", all)
+	}
+	if all[1].Details != `{"removed_groups":[5]}` || all[2].Details != "{}" {
+		t.Errorf("Unexpected details %q and %q", all[1].Details, all[2].Details)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		filter AuditFilter
+		want   int
+	}{
+		{"action", AuditFilter{Action: models.AuditSetUpdate}, 1},
+		{"actor", AuditFilter{Actor: " Anna "}, 2},
+		{"target", AuditFilter{TargetType: models.AuditTargetGroup, TargetID: "5"}, 2},
+		{"today", AuditFilter{From: time.Now().UTC().Format(DayLayout), To: time.Now().UTC().Format(DayLayout)}, 3},
+		{"yesterday", AuditFilter{To: time.Now().UTC().AddDate(0, 0, -1).Format(DayLayout)}, 0},
+		{"page", AuditFilter{Limit: 2}, 2},
+		{"next page", AuditFilter{Limit: 2, BeforeID: all[1].ID}, 1},
+	} {
+		tc.filter.WorkspaceID = first
+		events, err := as.List(tc.filter)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if len(events) != tc.want {
+			t.Errorf("%s: expected %d events, got %d", tc.name, tc.want, len(events))
+		}
+	}
+
+	for _, filter := range []AuditFilter{{From: "01.01.2026"}, {Limit: MaxAuditLimit + 1}} {
+		if _, err := as.List(filter); !errors.Is(err, ErrAuditFilterInvalid) {
+			t.Errorf("Expected ErrAuditFilterInvalid for %+v, got %v", filter, err)
+		}
+	}
+}
+
+// TestAuditAppendOnly tests that the database refuses to change or delete events
+func TestAuditAppendOnly(t *testing.T) {
+	db := dbtest.New(t)
+	event := models.AuditEvent{Username: "anna", Action: models.AuditMemberRemove}
+	if err := NewAuditService(db).Record(event); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := db.Model(&models.AuditEvent{}).Where("username = ?", "anna").Update("username", "boris").Error; err == nil {
+		t.Error("Expected updating an audit event to fail")
+	}
+	if err := db.Where("username = ?", "anna").Delete(&models.AuditEvent{}).Error; err == nil {
+		t.Error("Expected deleting an audit event to fail")
+	}
+
+	var count int64
+	db.Model(&models.AuditEvent{}).Where("username = ?", "anna").Count(&count)
+	if count != 1 {
+		t.Errorf("Expected the event to stay unchanged, got %d matching rows", count)
+	}
+}
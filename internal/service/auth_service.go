@@ -0,0 +1,280 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"social-media-analyzer/internal/config"
+	"social-media-analyzer/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrSessionNotFound    = errors.New("session not found or expired")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserExists         = errors.New("user with this username already exists")
+	ErrUserInvalid        = errors.New("invalid user")
+)
+
+// MinPasswordLength is the shortest password accepted for an account
+const MinPasswordLength = 8
+
+// usernamePattern allows lowercase logins like "anna.k" or "smm-team"
+var usernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,63}$`)
+
+// AuthService manages user accounts and their sign-in sessions
+type AuthService struct {
+	db         *gorm.DB
+	sessionTTL time.Duration
+	now        func() time.Time
+	// dummyHash is compared against on unknown usernames, so a failed login
+	// takes as long whether or not the account exists
+	dummyHash []byte
+}
+
+// LoginSession is a freshly created session with the token for its cookie.
+// The token itself is never stored.
+type LoginSession struct {
+	Token   string
+	Session *models.Session
+}
+
+func NewAuthService(db *gorm.DB, cfg config.AuthConfig) *AuthService {
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	return &AuthService{db: db, sessionTTL: cfg.SessionTTL, now: time.Now, dummyHash: dummyHash}
+}
+
+// CreateUser adds an account; usernames are case-insensitive and stored lowercase
+func (as *AuthService) CreateUser(username, password string) (*models.User, error) {
+	username = NormalizeUsername(username)
+	if !usernamePattern.MatchString(username) {
+		return nil, fmt.Errorf("%w: username must be 3-64 characters of a-z, 0-9, '.', '_' or '-'", ErrUserInvalid)
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.User{Username: username, PasswordHash: hash}
+	if err := as.db.Create(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetPassword changes a user's password and signs out all of their sessions
+func (as *AuthService) SetPassword(username, password string) error {
+	user, err := as.getUser(username)
+	if err != nil {
+		return err
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return as.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("password_hash", hash).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.Session{}).Error
+	})
+}
+
+// ListUsers returns all accounts ordered by username
+func (as *AuthService) ListUsers() ([]models.User, error) {
+	var users []models.User
+	err := as.db.Order("username").Find(&users).Error
+	return users, err
+}
+
+// DeleteUser removes an account together with its sessions, API keys and
+// memberships. Workspaces only the user belonged to are deleted as well; the
+// last owner of a workspace shared with others cannot be deleted.
+func (as *AuthService) DeleteUser(username string) error {
+	user, err := as.getUser(username)
+	if err != nil {
+		return err
+	}
+	return as.db.Transaction(func(tx *gorm.DB) error {
+		var memberships []models.WorkspaceMember
+		if err := tx.Where("user_id = ?", user.ID).Find(&memberships).Error; err != nil {
+			return err
+		}
+		for _, member := range memberships {
+			var others int64
+			err := tx.Model(&models.WorkspaceMember{}).
+				Where("workspace_id = ? AND user_id <> ?", member.WorkspaceID, user.ID).
+				Count(&others).Error
+			if err != nil {
+				return err
+			}
+			if others == 0 {
+				if err := deleteWorkspace(tx, member.WorkspaceID); err != nil {
+					return err
+				}
+				continue
+			}
+			if member.Role == models.WorkspaceRoleOwner {
+				if err := ensureOtherOwner(tx, member.WorkspaceID, user.ID); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, model := range []interface{}{&models.Session{}, &models.APIKey{}, &models.WorkspaceMember{}} {
+			if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(user).Error
+	})
+}
+
+// Login checks the credentials and opens a new session
+func (as *AuthService) Login(username, password string) (*LoginSession, error) {
+	var user models.User
+	err := as.db.Where("username = ?", NormalizeUsername(username)).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		bcrypt.CompareHashAndPassword(as.dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	csrfToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	now := as.now().UTC()
+	session := models.Session{
+		TokenHash:  hashToken(token),
+		UserID:     user.ID,
+		User:       user,
+		CSRFToken:  csrfToken,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(as.sessionTTL),
+	}
+
+	err = as.db.Transaction(func(tx *gorm.DB) error {
+		// Expired sessions are only ever looked up to be rejected, so clean them up here
+		if err := tx.Where("expires_at < ?", now).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("User").Create(&session).Error; err != nil {
+			return err
+		}
+		return tx.Model(&user).Update("last_login_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &LoginSession{Token: token, Session: &session}, nil
+}
+
+// Authenticate returns the live session of a cookie token with its user.
+// Activity extends the session once half of its lifetime has passed;
+// renewed tells the caller to send the cookie again with the new expiry.
+func (as *AuthService) Authenticate(token string) (session *models.Session, renewed bool, err error) {
+	if token == "" {
+		return nil, false, ErrSessionNotFound
+	}
+	session = &models.Session{}
+	err = as.db.Preload("User").Where("token_hash = ?", hashToken(token)).First(session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := as.now().UTC()
+	if !session.ExpiresAt.After(now) {
+		return nil, false, ErrSessionNotFound
+	}
+	if session.ExpiresAt.Sub(now) >= as.sessionTTL/2 {
+		return session, false, nil
+	}
+	session.LastSeenAt = now
+	session.ExpiresAt = now.Add(as.sessionTTL)
+	err = as.db.Model(session).Omit("User").Updates(map[string]interface{}{
+		"last_seen_at": session.LastSeenAt,
+		"expires_at":   session.ExpiresAt,
+	}).Error
+	if err != nil {
+		return nil, false, err
+	}
+	return session, true, nil
+}
+
+// Logout ends the session of a cookie token; unknown tokens are ignored
+func (as *AuthService) Logout(token string) error {
+	if token == "" {
+		return nil
+	}
+	return as.db.Where("token_hash = ?", hashToken(token)).Delete(&models.Session{}).Error
+}
+
+// NormalizeUsername trims and lowercases a username
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func (as *AuthService) getUser(username string) (*models.User, error) {
+	var user models.User
+	if err := as.db.Where("username = ?", NormalizeUsername(username)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("%w: password must be at least %d characters", ErrUserInvalid, MinPasswordLength)
+	}
+	// bcrypt only looks at the first 72 bytes and rejects longer input
+	if len(password) > 72 {
+		return "", fmt.Errorf("%w: password must be at most 72 bytes", ErrUserInvalid)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// randomToken returns 32 random bytes, hex-encoded
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"social-media-analyzer/internal/config"
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+)
+
+// TestCreateUser tests username normalization and validation
+func TestCreateUser(t *testing.T) {
+	as := NewAuthService(dbtest.New(t), config.AuthConfig{SessionTTL: time.Hour})
+
+	user, err := as.CreateUser("  Anna.K ", "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user.Username != "anna.k" || user.PasswordHash == "correct horse" {
+		t.Errorf("Unexpected user %+v", user)
+	}
+
+	if _, err := as.CreateUser("anna.k", "another password"); !errors.Is(err, ErrUserExists) {
+		t.Errorf("Expected ErrUserExists, got %v", err)
+	}
+	for _, tt := range []struct{ username, password string }{
+		{"an", "correct horse"},
+		{"anna k", "correct horse"},
+		{"boris", "short"},
+	} {
+		if _, err := as.CreateUser(tt.username, tt.password); !errors.Is(err, ErrUserInvalid) {
+			t.Errorf("CreateUser(%q, %q): expected ErrUserInvalid, got %v", tt.username, tt.password, err)
+		}
+	}
+}
+
+// TestLoginAndAuthenticate tests the session lifecycle from login to logout
+func TestLoginAndAuthenticate(t *testing.T) {
+	db := dbtest.New(t)
+	as := NewAuthService(db, config.AuthConfig{SessionTTL: time.Hour})
+	if _, err := as.CreateUser("anna", "correct horse"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := as.Login("anna", "wrong password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for a wrong password, got %v", err)
+	}
+	if _, err := as.Login("nobody", "correct horse"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for an unknown user, got %v", err)
+	}
+
+	login, err := as.Login("ANNA", "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var stored models.Session
+	db.First(&stored)
+	if stored.TokenHash == login.Token || stored.CSRFToken == "" {
+		t.Errorf("Expected only the token hash and a CSRF token to be stored, got %+v", stored)
+	}
+
+	session, renewed, err := as.Authenticate(login.Token)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if session.User.Username != "anna" || session.CSRFToken != login.Session.CSRFToken || renewed {
+		t.Errorf("Unexpected session %+v (renewed %v)", session, renewed)
+	}
+	if _, _, err := as.Authenticate("forged"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound for an unknown token, got %v", err)
+	}
+
+	if err := as.Logout(login.Token); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, _, err := as.Authenticate(login.Token); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected the session to end on logout, got %v", err)
+	}
+}
+
+// TestSessionExpiry tests that sessions expire without activity and are extended by it
+func TestSessionExpiry(t *testing.T) {
+	as := NewAuthService(dbtest.New(t), config.AuthConfig{SessionTTL: time.Hour})
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	as.now = func() time.Time { return now }
+	as.CreateUser("anna", "correct horse")
+	login, err := as.Login("anna", "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	now = now.Add(40 * time.Minute)
+	session, renewed, err := as.Authenticate(login.Token)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !renewed || !session.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected the session to be extended to %v, got %v", now.Add(time.Hour), session.ExpiresAt)
+	}
+
+	now = now.Add(61 * time.Minute)
+	if _, _, err := as.Authenticate(login.Token); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected the session to expire, got %v", err)
+	}
+}
+
+// TestSetPasswordEndsSessions tests that changing a password signs the user out everywhere
+func TestSetPasswordEndsSessions(t *testing.T) {
+	as := NewAuthService(dbtest.New(t), config.AuthConfig{SessionTTL: time.Hour})
+	as.CreateUser("anna", "correct horse")
+	login, _ := as.Login("anna", "correct horse")
+
+	if err := as.SetPassword("anna", "battery staple"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, _, err := as.Authenticate(login.Token); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected old sessions to end, got %v", err)
+	}
+	if _, err := as.Login("anna", "battery staple"); err != nil {
+		t.Errorf("Expected the new password to work, got %v", err)
+	}
+	if err := as.DeleteUser("anna"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := as.SetPassword("anna", "battery staple"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
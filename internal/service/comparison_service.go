@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"social-media-analyzer/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidPeriod is returned for malformed or reversed date ranges
+var ErrInvalidPeriod = errors.New("invalid period")
+
+// MinComparisonPosts is the number of posts a period needs for its averages to be meaningful
+const MinComparisonPosts = 5
+
+// ComparisonService builds period-over-period reports from daily rollups
+type ComparisonService struct {
+	db         *gorm.DB
+	dailyStats *DailyStatsService
+}
+
+// Period is an inclusive date range in DayLayout format
+type Period struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// MetricDelta holds a metric's value in both periods and its change.
+// PercentChange is nil when the previous value is zero.
+type MetricDelta struct {
+	Previous      float64  `json:"previous"`
+	Current       float64  `json:"current"`
+	Delta         float64  `json:"delta"`
+	PercentChange *float64 `json:"percent_change"`
+}
+
+// GroupComparison represents one group's change between two periods
+type GroupComparison struct {
+	GroupID     uint        `json:"group_id"`
+	Domain      string      `json:"domain"`
+	Status      string      `json:"status"`
+	Posts       MetricDelta `json:"posts"`
+	AvgViews    MetricDelta `json:"avg_views"`
+	AvgLikes    MetricDelta `json:"avg_likes"`
+	ER          MetricDelta `json:"er"`
+	Subscribers MetricDelta `json:"subscribers"`
+	LowSample   bool        `json:"low_sample"`
+}
+
+// ComparisonReport is the result of comparing two periods across groups
+type ComparisonReport struct {
+	Previous Period            `json:"previous"`
+	Current  Period            `json:"current"`
+	Groups   []GroupComparison `json:"groups"`
+}
+
+func NewComparisonService(db *gorm.DB, dailyStats *DailyStatsService) *ComparisonService {
+	return &ComparisonService{db: db, dailyStats: dailyStats}
+}
+
+// Validate checks that both bounds are dates and From is not after To
+func (p Period) Validate() error {
+	from, err := time.Parse(DayLayout, p.From)
+	if err != nil {
+		return fmt.Errorf("%w: date %q, expected YYYY-MM-DD", ErrInvalidPeriod, p.From)
+	}
+	to, err := time.Parse(DayLayout, p.To)
+	if err != nil {
+		return fmt.Errorf("%w: date %q, expected YYYY-MM-DD", ErrInvalidPeriod, p.To)
+	}
+	if from.After(to) {
+		return fmt.Errorf("%w: start %s is after end %s", ErrInvalidPeriod, p.From, p.To)
+	}
+	return nil
+}
+
+// DefaultPeriods returns the current month to date and the whole previous month
+func DefaultPeriods(now time.Time) (Period, Period) {
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	previous := Period{
+		From: monthStart.AddDate(0, -1, 0).Format(DayLayout),
+		To:   monthStart.AddDate(0, 0, -1).Format(DayLayout),
+	}
+	current := Period{
+		From: monthStart.Format(DayLayout),
+		To:   now.Format(DayLayout),
+	}
+	return previous, current
+}
+
+// Compare builds a per-group report of changes from the previous to the current period
+func (cs *ComparisonService) Compare(previous, current Period, filter GroupFilter) (ComparisonReport, error) {
+	if err := previous.Validate(); err != nil {
+		return ComparisonReport{}, err
+	}
+	if err := current.Validate(); err != nil {
+		return ComparisonReport{}, err
+	}
+
+	var groups []models.Group
+	if err := filter.Apply(cs.db).Order("domain").Find(&groups).Error; err != nil {
+		return ComparisonReport{}, err
+	}
+
+	ids := make([]uint, len(groups))
+	for i, group := range groups {
+		ids[i] = group.ID
+	}
+	prevTotals, err := cs.dailyStats.GetPeriodTotals(ids, previous.From, previous.To)
+	if err != nil {
+		return ComparisonReport{}, err
+	}
+	curTotals, err := cs.dailyStats.GetPeriodTotals(ids, current.From, current.To)
+	if err != nil {
+		return ComparisonReport{}, err
+	}
+
+	report := ComparisonReport{
+		Previous: previous,
+		Current:  current,
+		Groups:   make([]GroupComparison, 0, len(groups)),
+	}
+	for _, group := range groups {
+		comparison := compareTotals(prevTotals[group.ID], curTotals[group.ID])
+		comparison.GroupID = group.ID
+		comparison.Domain = group.Domain
+		comparison.Status = group.Status
+		report.Groups = append(report.Groups, comparison)
+	}
+
+	return report, nil
+}
+
+// compareTotals computes metric deltas between two period totals of the same group
+func compareTotals(prev, cur PeriodTotals) GroupComparison {
+	return GroupComparison{
+		Posts:       newMetricDelta(float64(prev.Posts), float64(cur.Posts)),
+		AvgViews:    newMetricDelta(perPost(prev.Views, prev.Posts), perPost(cur.Views, cur.Posts)),
+		AvgLikes:    newMetricDelta(perPost(prev.Likes, prev.Posts), perPost(cur.Likes, cur.Posts)),
+		ER:          newMetricDelta(engagementRate(prev), engagementRate(cur)),
+		Subscribers: newMetricDelta(float64(prev.Subscribers), float64(cur.Subscribers)),
+		LowSample:   prev.Posts < MinComparisonPosts || cur.Posts < MinComparisonPosts,
+	}
+}
+
+func newMetricDelta(prev, cur float64) MetricDelta {
+	d := MetricDelta{Previous: prev, Current: cur, Delta: cur - prev}
+	if prev != 0 {
+		pct := d.Delta / prev * 100
+		d.PercentChange = &pct
+	}
+	return d
+}
+
+func perPost(total, posts int) float64 {
+	if posts == 0 {
+		return 0
+	}
+	return float64(total) / float64(posts)
+}
+
+// engagementRate is likes, comments and reposts per view, in percent
+func engagementRate(t PeriodTotals) float64 {
+	if t.Views == 0 {
+		return 0
+	}
+	return float64(t.Likes+t.Comments+t.Reposts) / float64(t.Views) * 100
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+)
+
+// TestCompareTotals tests metric deltas between two periods
+func TestCompareTotals(t *testing.T) {
+	prev := PeriodTotals{Posts: 10, Views: 1000, Likes: 100, Comments: 20, Reposts: 10, Subscribers: 500}
+	cur := PeriodTotals{Posts: 20, Views: 3000, Likes: 300, Comments: 30, Reposts: 0, Subscribers: 550}
+
+	c := compareTotals(prev, cur)
+
+	if c.Posts.Delta != 10 {
+		t.Errorf("Expected posts delta 10, got %.2f", c.Posts.Delta)
+	}
+	if c.Posts.PercentChange == nil || *c.Posts.PercentChange != 100 {
+		t.Errorf("Expected posts change 100%%, got %v", c.Posts.PercentChange)
+	}
+	if c.AvgViews.Previous != 100 || c.AvgViews.Current != 150 {
+		t.Errorf("Expected avg views 100 -> 150, got %.2f -> %.2f", c.AvgViews.Previous, c.AvgViews.Current)
+	}
+	if c.ER.Previous != 13 || c.ER.Current != 11 {
+		t.Errorf("Expected ER 13 -> 11, got %.2f -> %.2f", c.ER.Previous, c.ER.Current)
+	}
+	if c.Subscribers.Delta != 50 {
+		t.Errorf("Expected subscribers delta 50, got %.2f", c.Subscribers.Delta)
+	}
+	if c.LowSample {
+		t.Error("Expected sample to be sufficient")
+	}
+}
+
+// TestCompareTotalsLowSample tests flagging of tiny samples and zero baselines
+func TestCompareTotalsLowSample(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev      PeriodTotals
+		cur       PeriodTotals
+		lowSample bool
+	}{
+		{"Both periods empty", PeriodTotals{}, PeriodTotals{}, true},
+		{"Few previous posts", PeriodTotals{Posts: 2}, PeriodTotals{Posts: 30}, true},
+		{"Few current posts", PeriodTotals{Posts: 30}, PeriodTotals{Posts: 4}, true},
+		{"Enough posts", PeriodTotals{Posts: 5}, PeriodTotals{Posts: 5}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := compareTotals(tt.prev, tt.cur)
+			if c.LowSample != tt.lowSample {
+				t.Errorf("Expected low sample %v, got %v", tt.lowSample, c.LowSample)
+			}
+		})
+	}
+
+	c := compareTotals(PeriodTotals{}, PeriodTotals{Posts: 3})
+	if c.Posts.PercentChange != nil {
+		t.Errorf("Expected no percent change from zero baseline, got %.2f", *c.Posts.PercentChange)
+	}
+}
+
+// TestPeriodValidate tests period validation
+func TestPeriodValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		period    Period
+		shouldErr bool
+	}{
+		{"Valid period", Period{From: "2025-11-01", To: "2025-11-30"}, false},
+		{"Single day", Period{From: "2025-11-01", To: "2025-11-01"}, false},
+		{"Reversed", Period{From: "2025-11-30", To: "2025-11-01"}, true},
+		{"Bad format", Period{From: "01.11.2025", To: "2025-11-30"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.period.Validate()
+			if (err != nil) != tt.shouldErr {
+				t.Errorf("Expected error %v, got %v", tt.shouldErr, err)
+			}
+		})
+	}
+}
+
+// TestDefaultPeriods tests default month-over-month periods
+func TestDefaultPeriods(t *testing.T) {
+	now := time.Date(2025, time.March, 15, 10, 0, 0, 0, time.UTC)
+	previous, current := DefaultPeriods(now)
+
+	if previous.From != "2025-02-01" || previous.To != "2025-02-28" {
+		t.Errorf("Unexpected previous period %+v", previous)
+	}
+	if current.From != "2025-03-01" || current.To != "2025-03-15" {
+		t.Errorf("Unexpected current period %+v", current)
+	}
+}
+
+// TestCompareSQLite tests comparing periods for the groups carrying a tag
+func TestCompareSQLite(t *testing.T) {
+	db := dbtest.New(t)
+	groups := []models.Group{
+		{Domain: "tagged"},
+		{Domain: "other"},
+		{Domain: "untracked"},
+	}
+	if err := db.Create(&groups).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	workspaceID := newTestWorkspace(t, db, groups[0].ID, groups[1].ID)
+	if _, err := NewTagService(db).SetGroupTags(workspaceID, groups[0].ID, []string{"client"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stats := []models.GroupDailyStats{
+		{GroupID: groups[0].ID, Day: "2025-11-10", Posts: 2, Views: 200},
+		{GroupID: groups[0].ID, Day: "2025-12-10", Posts: 4, Views: 800},
+		{GroupID: groups[1].ID, Day: "2025-12-10", Posts: 1, Views: 10},
+	}
+	if err := db.Create(&stats).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	svc := NewComparisonService(db, NewDailyStatsService(db))
+	report, err := svc.Compare(
+		Period{From: "2025-11-01", To: "2025-11-30"},
+		Period{From: "2025-12-01", To: "2025-12-31"},
+		GroupFilter{WorkspaceID: workspaceID, Tag: "Client"},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(report.Groups) != 1 || report.Groups[0].Domain != "tagged" {
+		t.Fatalf("Expected only the tagged group, got %+v", report.Groups)
+	}
+	all, err := svc.Compare(report.Previous, report.Current, GroupFilter{WorkspaceID: workspaceID})
+	if err != nil || len(all.Groups) != 2 {
+		t.Errorf("Expected the 2 tracked groups without a tag filter, got %+v, %v", all.Groups, err)
+	}
+	got := report.Groups[0]
+	if got.Posts.Previous != 2 || got.Posts.Current != 4 || got.AvgViews.Current != 200 {
+		t.Errorf("Unexpected comparison %+v", got)
+	}
+}
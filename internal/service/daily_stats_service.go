@@ -0,0 +1,200 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"social-media-analyzer/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DayLayout is the date format used for posts and daily rollups
+const DayLayout = "2006-01-02"
+
+// DailyStatsService maintains and reads the GroupDailyStats rollup table
+type DailyStatsService struct {
+	db *gorm.DB
+}
+
+// PeriodTotals represents summed rollup values of a group over a date range
+type PeriodTotals struct {
+	GroupID          uint
+	Days             int
+	Posts            int
+	Views            int
+	Likes            int
+	Comments         int
+	Reposts          int
+	StartSubscribers int
+	Subscribers      int
+}
+
+func NewDailyStatsService(db *gorm.DB) *DailyStatsService {
+	return &DailyStatsService{db: db}
+}
+
+// RefreshGroup recomputes rollup rows for every day covered by the group's
+// freshly fetched posts and records the current subscriber count on today's
+// row. Days outside the synced window are left untouched, so history survives
+// even though each sync only keeps the latest posts. complete reports
+// whether the posts are the whole wall; otherwise the oldest day may be only
+// partly covered and its counts are never lowered.
+func (s *DailyStatsService) RefreshGroup(group *models.Group, posts []models.Post, complete bool) error {
+	rows := rollUp(group.ID, posts)
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// A complete read without posts still empties the wall's history; a
+		// partial page without posts covers no days
+		if len(rows) > 0 || complete {
+			var from string
+			var boundary []models.GroupDailyStats
+			if !complete {
+				from, _ = dayRange(rows)
+				boundary, rows = splitDay(rows, from)
+			}
+
+			// Days inside the synced window that no longer have posts were
+			// emptied on VK's side; it reaches until now, and back to the
+			// first post when the whole wall was read
+			emptied := tx.Model(&models.GroupDailyStats{}).Where("group_id = ?", group.ID)
+			if !complete {
+				emptied = emptied.Where("day > ?", from)
+			}
+			if err := emptied.Updates(map[string]interface{}{"posts": 0, "views": 0, "likes": 0, "comments": 0, "reposts": 0}).Error; err != nil {
+				return err
+			}
+
+			columns := []clause.Column{{Name: "group_id"}, {Name: "day"}}
+			counts := clause.AssignmentColumns([]string{"posts", "views", "likes", "comments", "reposts"})
+			if len(rows) > 0 {
+				if err := tx.Clauses(clause.OnConflict{Columns: columns, DoUpdates: counts}).Create(&rows).Error; err != nil {
+					return err
+				}
+			}
+			// A partly covered day only replaces a stored count that had no more posts
+			if len(boundary) > 0 {
+				if err := tx.Clauses(clause.OnConflict{
+					Columns:   columns,
+					Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "excluded.posts >= group_daily_stats.posts"}}},
+					DoUpdates: counts,
+				}).Create(&boundary).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		today := models.GroupDailyStats{
+			GroupID:     group.ID,
+			Day:         time.Now().UTC().Format(DayLayout),
+			Subscribers: group.Subscribers,
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "group_id"}, {Name: "day"}},
+			DoUpdates: clause.AssignmentColumns([]string{"subscribers"}),
+		}).Create(&today).Error
+	})
+}
+
+// GetTrend returns the rollup rows of a group for days in [from, to], ordered by day
+func (s *DailyStatsService) GetTrend(groupID uint, from, to string) ([]models.GroupDailyStats, error) {
+	var rows []models.GroupDailyStats
+	err := s.db.Where("group_id = ? AND day BETWEEN ? AND ?", groupID, from, to).
+		Order("day").
+		Find(&rows).Error
+	return rows, err
+}
+
+// GetPeriodTotals sums rollup rows per group for days in [from, to].
+// StartSubscribers and Subscribers are the earliest and latest known
+// non-zero counts within the period.
+func (s *DailyStatsService) GetPeriodTotals(groupIDs []uint, from, to string) (map[uint]PeriodTotals, error) {
+	var rows []models.GroupDailyStats
+	query := s.db.Where("day BETWEEN ? AND ?", from, to)
+	if len(groupIDs) > 0 {
+		query = query.Where("group_id IN ?", groupIDs)
+	}
+	if err := query.Order("day").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	return sumPeriod(rows), nil
+}
+
+// sumPeriod folds day-ordered rollup rows into per-group totals
+func sumPeriod(rows []models.GroupDailyStats) map[uint]PeriodTotals {
+	totals := make(map[uint]PeriodTotals)
+	for _, row := range rows {
+		t := totals[row.GroupID]
+		t.GroupID = row.GroupID
+		t.Posts += row.Posts
+		t.Views += row.Views
+		t.Likes += row.Likes
+		t.Comments += row.Comments
+		t.Reposts += row.Reposts
+		if row.Posts > 0 {
+			t.Days++
+		}
+		if row.Subscribers > 0 {
+			if t.StartSubscribers == 0 {
+				t.StartSubscribers = row.Subscribers
+			}
+			t.Subscribers = row.Subscribers
+		}
+		totals[row.GroupID] = t
+	}
+	return totals
+}
+
+// rollUp sums posts into one rollup row per day, ordered by day
+func rollUp(groupID uint, posts []models.Post) []models.GroupDailyStats {
+	byDay := make(map[string]*models.GroupDailyStats)
+	var days []string
+	for _, post := range posts {
+		row, ok := byDay[post.Date]
+		if !ok {
+			row = &models.GroupDailyStats{GroupID: groupID, Day: post.Date}
+			byDay[post.Date] = row
+			days = append(days, post.Date)
+		}
+		row.Posts++
+		row.Views += post.Views
+		row.Likes += post.Likes
+		row.Comments += post.Comments
+		row.Reposts += post.Reposts
+	}
+
+	sort.Strings(days)
+	rows := make([]models.GroupDailyStats, len(days))
+	for i, day := range days {
+		rows[i] = *byDay[day]
+	}
+	return rows
+}
+
+// splitDay separates the rollup rows of one day from the others
+func splitDay(rows []models.GroupDailyStats, day string) (matching, rest []models.GroupDailyStats) {
+	for _, row := range rows {
+		if row.Day == day {
+			matching = append(matching, row)
+		} else {
+			rest = append(rest, row)
+		}
+	}
+	return matching, rest
+}
+
+// dayRange returns the earliest and latest day among rollup rows
+func dayRange(rows []models.GroupDailyStats) (string, string) {
+	from, to := rows[0].Day, rows[0].Day
+	for _, row := range rows[1:] {
+		if row.Day < from {
+			from = row.Day
+		}
+		if row.Day > to {
+			to = row.Day
+		}
+	}
+	return from, to
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"testing"
+
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+)
+
+// TestSumPeriod tests folding daily rollups into per-group totals
+func TestSumPeriod(t *testing.T) {
+	rows := []models.GroupDailyStats{
+		{GroupID: 1, Day: "2025-12-01", Posts: 2, Views: 100, Likes: 10, Comments: 1, Reposts: 1, Subscribers: 1000},
+		{GroupID: 2, Day: "2025-12-01", Posts: 1, Views: 50, Likes: 5},
+		{GroupID: 1, Day: "2025-12-02", Posts: 0, Subscribers: 0},
+		{GroupID: 1, Day: "2025-12-03", Posts: 3, Views: 300, Likes: 30, Comments: 3, Reposts: 2, Subscribers: 1100},
+	}
+
+	totals := sumPeriod(rows)
+
+	if len(totals) != 2 {
+		t.Fatalf("Expected totals for 2 groups, got %d", len(totals))
+	}
+
+	g1 := totals[1]
+	if g1.Posts != 5 {
+		t.Errorf("Expected 5 posts, got %d", g1.Posts)
+	}
+	if g1.Views != 400 {
+		t.Errorf("Expected 400 views, got %d", g1.Views)
+	}
+	if g1.Likes != 40 {
+		t.Errorf("Expected 40 likes, got %d", g1.Likes)
+	}
+	if g1.Reposts != 3 {
+		t.Errorf("Expected 3 reposts, got %d", g1.Reposts)
+	}
+	if g1.Days != 2 {
+		t.Errorf("Expected 2 active days, got %d", g1.Days)
+	}
+	if g1.StartSubscribers != 1000 {
+		t.Errorf("Expected earliest subscribers 1000, got %d", g1.StartSubscribers)
+	}
+	if g1.Subscribers != 1100 {
+		t.Errorf("Expected latest subscribers 1100, got %d", g1.Subscribers)
+	}
+
+	if totals[2].Subscribers != 0 {
+		t.Errorf("Expected 0 subscribers for group without snapshot, got %d", totals[2].Subscribers)
+	}
+}
+
+// TestDayRange tests detecting the covered day range of rollups
+func TestDayRange(t *testing.T) {
+	rows := []models.GroupDailyStats{
+		{Day: "2025-12-03"},
+		{Day: "2025-11-28"},
+		{Day: "2025-12-10"},
+	}
+
+	from, to := dayRange(rows)
+	if from != "2025-11-28" {
+		t.Errorf("Expected from '2025-11-28', got '%s'", from)
+	}
+	if to != "2025-12-10" {
+		t.Errorf("Expected to '2025-12-10', got '%s'", to)
+	}
+}
+
+// TestRefreshGroupSQLite tests rolling posts up into days and zeroing emptied days
+func TestRefreshGroupSQLite(t *testing.T) {
+	db := dbtest.New(t)
+	group := models.Group{Domain: "club", Subscribers: 500}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	posts := []models.Post{
+		{GroupID: group.ID, Date: "2025-12-01", Views: 100, Likes: 10, Comments: 1, Reposts: 1},
+		{GroupID: group.ID, Date: "2025-12-01", Views: 50, Likes: 5},
+		{GroupID: group.ID, Date: "2025-12-02", Views: 30, Likes: 3},
+	}
+
+	svc := NewDailyStatsService(db)
+	if err := svc.RefreshGroup(&group, posts, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	trend, err := svc.GetTrend(group.ID, "2025-12-01", "2025-12-02")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(trend) != 2 || trend[0].Posts != 2 || trend[0].Views != 150 || trend[1].Likes != 3 {
+		t.Fatalf("Unexpected trend %+v", trend)
+	}
+
+	// The second day lost its post on VK's side
+	posts = append(posts[:2], models.Post{GroupID: group.ID, Date: "2025-12-03", Views: 7})
+	if err := svc.RefreshGroup(&group, posts, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	totals, err := svc.GetPeriodTotals([]uint{group.ID}, "2025-12-01", "2025-12-03")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := totals[group.ID]; got.Posts != 3 || got.Views != 157 || got.Days != 2 {
+		t.Errorf("Expected 3 posts and 157 views over 2 days, got %+v", got)
+	}
+}
+
+// TestRefreshGroupEmptiedWall tests that a complete read of a wall without
+// posts clears the stored counts, while a partial page without posts keeps them
+func TestRefreshGroupEmptiedWall(t *testing.T) {
+	db := dbtest.New(t)
+	group := models.Group{Domain: "club", Subscribers: 500}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	svc := NewDailyStatsService(db)
+	posts := []models.Post{
+		{GroupID: group.ID, Date: "2025-12-01", Views: 100, Likes: 10},
+		{GroupID: group.ID, Date: "2025-12-02", Views: 30, Likes: 3},
+	}
+	if err := svc.RefreshGroup(&group, posts, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := svc.RefreshGroup(&group, nil, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	totals, err := svc.GetPeriodTotals([]uint{group.ID}, "2025-12-01", "2025-12-02")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := totals[group.ID]; got.Posts != 2 || got.Views != 130 {
+		t.Errorf("Expected a partial page without posts to keep the counts, got %+v", got)
+	}
+
+	// Every post was deleted on VK's side
+	if err := svc.RefreshGroup(&group, nil, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	totals, err = svc.GetPeriodTotals([]uint{group.ID}, "2025-12-01", "2025-12-02")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := totals[group.ID]; got.Posts != 0 || got.Views != 0 || got.Likes != 0 {
+		t.Errorf("Expected the emptied wall to clear the counts, got %+v", got)
+	}
+}
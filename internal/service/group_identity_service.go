@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/repo"
+)
+
+// GroupIdentityService stores groups under their numeric VK ID and keeps
+// track of the screen names they have used
+type GroupIdentityService struct {
+	groups repo.GroupRepository
+}
+
+func NewGroupIdentityService(groups repo.GroupRepository) *GroupIdentityService {
+	return &GroupIdentityService{groups: groups}
+}
+
+// Upsert stores a group fetched from VK. An existing group is matched by VK ID,
+// or by screen name if it was added before VK IDs were stored; its metadata is
+// updated and a rename is recorded. created reports whether a new row was inserted.
+func (gs *GroupIdentityService) Upsert(fetched *models.Group) (group *models.Group, created bool, err error) {
+	existing, err := gs.findByIdentity(fetched)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing == nil {
+		if err := gs.groups.Create(fetched); err != nil {
+			return nil, false, err
+		}
+		return fetched, true, nil
+	}
+
+	if err := gs.Refresh(existing, fetched); err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+// Refresh updates a stored group with metadata freshly fetched from VK,
+// recording a rename when the screen name changed
+func (gs *GroupIdentityService) Refresh(group *models.Group, fetched *models.Group) error {
+	var rename *models.GroupScreenName
+	if group.Domain != "" && fetched.Domain != "" && !strings.EqualFold(group.Domain, fetched.Domain) {
+		rename = &models.GroupScreenName{
+			GroupID:    group.ID,
+			ScreenName: group.Domain,
+			RenamedTo:  fetched.Domain,
+			RenamedAt:  time.Now().UTC(),
+		}
+	}
+	return gs.groups.UpdateInfo(group, fetched, rename)
+}
+
+// ScreenNameHistory returns the previous screen names of a group, newest first
+func (gs *GroupIdentityService) ScreenNameHistory(groupID uint) ([]models.GroupScreenName, error) {
+	if _, err := gs.groups.GetByID(groupID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, err
+	}
+	return gs.groups.ScreenNames(groupID)
+}
+
+// findByIdentity looks a group up by VK ID, falling back to legacy rows without one
+func (gs *GroupIdentityService) findByIdentity(fetched *models.Group) (*models.Group, error) {
+	if fetched.VKID != nil {
+		group, err := gs.groups.FindByVKID(*fetched.VKID)
+		if !errors.Is(err, repo.ErrNotFound) {
+			return group, err
+		}
+	}
+
+	group, err := gs.groups.FindUnidentified(fetched.Domain)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, nil
+	}
+	return group, err
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/repo"
+)
+
+func intPtr(v int) *int { return &v }
+
+// TestUpsertClaimsLegacyGroupAndTracksRenames tests matching by screen name, then by VK ID
+func TestUpsertClaimsLegacyGroupAndTracksRenames(t *testing.T) {
+	groups := repo.NewMemoryGroupRepository(models.Group{Domain: "MyBrand"})
+	svc := NewGroupIdentityService(groups)
+
+	group, created, err := svc.Upsert(&models.Group{VKID: intPtr(42), Domain: "mybrand", Name: "My Brand"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if created || group.ID != 1 {
+		t.Fatalf("Expected the legacy group to be claimed, got created=%v id=%d", created, group.ID)
+	}
+
+	group, created, err = svc.Upsert(&models.Group{VKID: intPtr(42), Domain: "newbrand", Name: "New Brand"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if created || group.ID != 1 || group.Domain != "newbrand" || group.Name != "New Brand" {
+		t.Fatalf("Expected group 1 renamed to newbrand, got created=%v %+v", created, group)
+	}
+
+	history, err := svc.ScreenNameHistory(1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(history) != 1 || history[0].ScreenName != "mybrand" || history[0].RenamedTo != "newbrand" {
+		t.Errorf("Expected one rename mybrand -> newbrand, got %+v", history)
+	}
+
+	other, created, err := svc.Upsert(&models.Group{VKID: intPtr(7), Domain: "mybrand"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !created || other.ID == 1 {
+		t.Errorf("Expected a new group for another VK ID, got created=%v id=%d", created, other.ID)
+	}
+}
+
+// TestScreenNameHistoryUnknownGroup tests the not found error
+func TestScreenNameHistoryUnknownGroup(t *testing.T) {
+	svc := NewGroupIdentityService(repo.NewMemoryGroupRepository())
+
+	if _, err := svc.ScreenNameHistory(99); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("Expected ErrGroupNotFound, got %v", err)
+	}
+}
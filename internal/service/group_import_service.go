@@ -0,0 +1,259 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"social-media-analyzer/internal/models"
+)
+
+// MaxImportLinks limits the number of links accepted by a single import
+const MaxImportLinks = 500
+
+// Import line statuses
+const (
+	ImportAdded          = "added"
+	ImportAlreadyTracked = "already_tracked"
+	ImportInvalid        = "invalid"
+	ImportNotFound       = "not_found"
+	ImportNotCommunity   = "not_community"
+	ImportDuplicate      = "duplicate"
+	ImportFailed         = "failed"
+)
+
+var ErrTooManyLinks = fmt.Errorf("too many links, at most %d per import", MaxImportLinks)
+
+// GroupImportService adds many groups at once from a list of links
+type GroupImportService struct {
+	vkService   *VKService
+	identity    *GroupIdentityService
+	syncService *SyncService
+	workspaces  *WorkspaceService
+}
+
+// ImportLine is the outcome of importing one input line
+type ImportLine struct {
+	Line    int    `json:"line"`
+	Input   string `json:"input"`
+	Status  string `json:"status"`
+	GroupID uint   `json:"group_id,omitempty"`
+	Domain  string `json:"domain,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ImportReport summarizes an import with one entry per non-empty input line
+type ImportReport struct {
+	Summary map[string]int `json:"summary"`
+	Lines   []ImportLine   `json:"lines"`
+}
+
+// ImportLink is a link with its line number in the original input
+type ImportLink struct {
+	Line int
+	Link string
+}
+
+func NewGroupImportService(vkService *VKService, identity *GroupIdentityService, syncService *SyncService, workspaces *WorkspaceService) *GroupImportService {
+	return &GroupImportService{vkService: vkService, identity: identity, syncService: syncService, workspaces: workspaces}
+}
+
+// LinksFromList numbers links of a JSON array, skipping blank entries
+func LinksFromList(list []string) []ImportLink {
+	var links []ImportLink
+	for i, link := range list {
+		if link = strings.TrimSpace(link); link != "" {
+			links = append(links, ImportLink{Line: i + 1, Link: link})
+		}
+	}
+	return links
+}
+
+// LinksFromText reads one link per line, skipping blank lines
+func LinksFromText(r io.Reader) ([]ImportLink, error) {
+	var links []ImportLink
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if link := strings.TrimSpace(scanner.Text()); link != "" {
+			links = append(links, ImportLink{Line: line, Link: link})
+		}
+	}
+	return links, scanner.Err()
+}
+
+// LinksFromCSV reads the first non-empty field of each record, skipping a
+// leading "link" or "url" header
+func LinksFromCSV(r io.Reader) ([]ImportLink, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var links []ImportLink
+	line := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		line++
+
+		link := ""
+		for _, field := range record {
+			if field = strings.TrimSpace(field); field != "" {
+				link = field
+				break
+			}
+		}
+		if link == "" {
+			continue
+		}
+		if line == 1 && (strings.EqualFold(link, "link") || strings.EqualFold(link, "url")) {
+			continue
+		}
+		links = append(links, ImportLink{Line: line, Link: link})
+	}
+	return links, nil
+}
+
+// Import validates, de-duplicates and resolves the links, adds the groups to
+// the workspace's list and schedules groups new to the database for parsing.
+// Links are resolved to numeric community IDs first, so personal pages are
+// told apart from missing groups and renamed spellings collapse into one.
+func (is *GroupImportService) Import(ctx context.Context, workspaceID uint, links []ImportLink) (*ImportReport, error) {
+	if len(links) > MaxImportLinks {
+		return nil, ErrTooManyLinks
+	}
+
+	lines := make([]ImportLine, len(links))
+	firstLine := make(map[string]int)
+	pending := make(map[string]int)
+	var names []string
+
+	for i, link := range links {
+		lines[i] = ImportLine{Line: link.Line, Input: link.Link}
+
+		ref, err := ParseVKLink(link.Link)
+		if err != nil {
+			lines[i].Status = ImportInvalid
+			lines[i].Message = err.Error()
+			continue
+		}
+		if ref.User {
+			lines[i].Status = ImportNotCommunity
+			lines[i].Message = ErrNotCommunity.Error()
+			continue
+		}
+
+		key := strings.ToLower(ref.ScreenName)
+		if first, ok := firstLine[key]; ok {
+			lines[i].Status = ImportDuplicate
+			lines[i].Message = fmt.Sprintf("same group as line %d", first)
+			continue
+		}
+		firstLine[key] = link.Line
+		pending[key] = i
+		names = append(names, key)
+	}
+
+	resolved := map[string]*ResolvedObject{}
+	if len(names) > 0 {
+		var err error
+		if resolved, err = is.vkService.ResolveScreenNames(ctx, names); err != nil {
+			return nil, err
+		}
+	}
+
+	// Different spellings may resolve to the same community
+	seenIDs := make(map[int]int)
+	byID := make(map[string]int)
+	var ids []string
+	for _, key := range names {
+		i := pending[key]
+		object, ok := resolved[key]
+		if !ok {
+			lines[i].Status = ImportNotFound
+			lines[i].Message = "group not found"
+			continue
+		}
+		if !object.IsCommunity() {
+			lines[i].Status = ImportNotCommunity
+			lines[i].Message = ErrNotCommunity.Error()
+			continue
+		}
+
+		if first, ok := seenIDs[object.ObjectID]; ok {
+			lines[i].Status = ImportDuplicate
+			lines[i].Message = fmt.Sprintf("same group as line %d", first)
+			continue
+		}
+		seenIDs[object.ObjectID] = lines[i].Line
+
+		id := strconv.Itoa(object.ObjectID)
+		byID[id] = i
+		ids = append(ids, id)
+	}
+
+	found := map[string]*models.Group{}
+	if len(ids) > 0 {
+		var err error
+		if found, err = is.vkService.GetGroupsInfo(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range ids {
+		i := byID[id]
+		group, ok := found[id]
+		if !ok {
+			lines[i].Status = ImportNotFound
+			lines[i].Message = "group not found"
+			continue
+		}
+		lines[i].Domain = group.Domain
+
+		is.importGroup(workspaceID, group, &lines[i])
+	}
+
+	report := &ImportReport{Summary: map[string]int{}, Lines: lines}
+	for _, line := range lines {
+		report.Summary[line.Status]++
+	}
+	return report, nil
+}
+
+// importGroup stores a resolved group and adds it to the workspace's list.
+// Groups another workspace already tracks are not parsed again.
+func (is *GroupImportService) importGroup(workspaceID uint, fetched *models.Group, line *ImportLine) {
+	group, created, err := is.identity.Upsert(fetched)
+	if err != nil {
+		line.Status = ImportFailed
+		line.Message = "failed to add group"
+		return
+	}
+	line.GroupID = group.ID
+	added, err := is.workspaces.Track(workspaceID, group.ID)
+	if err != nil {
+		line.Status = ImportFailed
+		line.Message = "failed to add group"
+		return
+	}
+	if !added {
+		line.Status = ImportAlreadyTracked
+		return
+	}
+	line.Status = ImportAdded
+
+	if created && !is.syncService.Enqueue(workspaceID, *group) {
+		line.Message = "added, but parsing queue is full; refresh the group later"
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/repo"
+)
+
+// TestLinksFromText tests line numbering and skipping of blank lines
+func TestLinksFromText(t *testing.T) {
+	links, err := LinksFromText(strings.NewReader("vk.com/one\n\n  https://vk.com/two  \r\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links, got %d", len(links))
+	}
+	if links[1].Line != 3 || links[1].Link != "https://vk.com/two" {
+		t.Errorf("Unexpected second link %+v", links[1])
+	}
+}
+
+// TestLinksFromCSV tests header skipping and first-column extraction
+func TestLinksFromCSV(t *testing.T) {
+	input := "link,client\nvk.com/one,acme\n,\n\"vk.com/two\",acme\n"
+	links, err := LinksFromCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links, got %d (%+v)", len(links), links)
+	}
+	if links[0].Line != 2 || links[0].Link != "vk.com/one" {
+		t.Errorf("Unexpected first link %+v", links[0])
+	}
+	if links[1].Line != 4 || links[1].Link != "vk.com/two" {
+		t.Errorf("Unexpected second link %+v", links[1])
+	}
+}
+
+// TestImportRejectsInvalidAndDuplicateLinks tests validation before any VK request
+func TestImportRejectsInvalidAndDuplicateLinks(t *testing.T) {
+	svc := NewGroupImportService(&VKService{}, nil, nil, nil)
+
+	report, err := svc.Import(context.Background(), 1, LinksFromList([]string{"https://vk.com/", "vk.com/bad name!"}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Summary[ImportInvalid] != 2 {
+		t.Errorf("Expected 2 invalid lines, got %+v", report.Summary)
+	}
+
+	tooMany := make([]ImportLink, MaxImportLinks+1)
+	if _, err := svc.Import(context.Background(), 1, tooMany); err != ErrTooManyLinks {
+		t.Errorf("Expected ErrTooManyLinks, got %v", err)
+	}
+}
+
+// TestImportResolvesLinks tests that links are classified by
+// utils.resolveScreenName before groups are fetched by their numeric IDs
+func TestImportResolvesLinks(t *testing.T) {
+	objects := map[string]string{
+		"mybrand":   `{"type":"group","object_id":123}`,
+		"club123":   `{"type":"group","object_id":123}`,
+		"ivan.shop": `{"type":"page","object_id":456}`,
+		"durov":     `{"type":"user","object_id":1}`,
+	}
+	var groupIDs string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/execute":
+			code := r.URL.Query().Get("code")
+			var names []string
+			if err := json.Unmarshal([]byte(code[len("var names = "):strings.Index(code, ";")]), &names); err != nil {
+				t.Errorf("Unexpected execute code %q", code)
+			}
+			results := make([]string, len(names))
+			for i, name := range names {
+				if results[i] = objects[name]; results[i] == "" {
+					results[i] = "[]"
+				}
+			}
+			fmt.Fprintf(w, `{"response":[%s]}`, strings.Join(results, ","))
+		case "/groups.getById":
+			groupIDs = r.URL.Query().Get("group_ids")
+			fmt.Fprint(w, `{"response":[{"id":123,"screen_name":"mybrand","type":"group"},{"id":456,"screen_name":"ivan.shop","type":"page"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	vk := &VKService{accessToken: "token", apiVersion: "5.131", apiURL: server.URL, httpClient: server.Client()}
+
+	db := dbtest.New(t)
+	groups := repo.NewGormGroupRepository(db)
+	identity := NewGroupIdentityService(groups)
+	svc := NewGroupImportService(vk, identity, NewSyncService(groups, nil, vk, nil, identity, 0), NewWorkspaceService(db))
+	workspaceID := newTestWorkspace(t, db)
+
+	report, err := svc.Import(context.Background(), workspaceID, LinksFromList([]string{
+		"vk.com/mybrand", "https://vk.com/club123", "vk.com/ivan.shop", "vk.com/durov", "vk.com/id1", "vk.com/nosuch",
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{ImportAdded, ImportDuplicate, ImportAdded, ImportNotCommunity, ImportNotCommunity, ImportNotFound}
+	for i, status := range want {
+		if report.Lines[i].Status != status {
+			t.Errorf("Line %d: expected %s, got %+v", i+1, status, report.Lines[i])
+		}
+	}
+	if groupIDs != "123,456" {
+		t.Errorf("Expected groups to be fetched by numeric ID, got %q", groupIDs)
+	}
+	if report.Lines[2].Domain != "ivan.shop" {
+		t.Errorf("Expected the dotted screen name to be kept, got %q", report.Lines[2].Domain)
+	}
+}
+
+// TestMatchGroups tests matching requested names to VK groups by screen name and numeric aliases
+func TestMatchGroups(t *testing.T) {
+	infos := []VKGroupInfo{
+		{ID: 123, Domain: "MyBrand", Members: 10},
+		{ID: 456, Domain: "other", Members: 20},
+	}
+	found := map[string]*models.Group{}
+	matchGroups([]string{"mybrand", "club456", "missing"}, infos, found)
+
+	if len(found) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(found))
+	}
+	if found["club456"] == nil || found["club456"].Domain != "other" {
+		t.Errorf("Expected club456 to resolve to 'other', got %+v", found["club456"])
+	}
+	if found["mybrand"].Subscribers != 10 {
+		t.Errorf("Expected 10 subscribers, got %d", found["mybrand"].Subscribers)
+	}
+	if found["mybrand"].VKID == nil || *found["mybrand"].VKID != 123 {
+		t.Errorf("Expected VK ID 123, got %v", found["mybrand"].VKID)
+	}
+}
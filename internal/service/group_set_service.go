@@ -0,0 +1,319 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"social-media-analyzer/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrGroupSetNotFound = errors.New("group set not found")
+	ErrGroupSetInvalid  = errors.New("invalid group set")
+	ErrGroupSetExists   = errors.New("group set with this name already exists")
+)
+
+// GroupSetService manages a workspace's group sets and benchmarks their members
+type GroupSetService struct {
+	db         *gorm.DB
+	dailyStats *DailyStatsService
+}
+
+// GroupSetInput represents the editable fields of a group set
+type GroupSetInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	GroupIDs    []uint `json:"group_ids"`
+}
+
+// BenchmarkMetric places one member's metric value within its set.
+// Percentile is the share of members with a lower value; Rank 1 is the best.
+type BenchmarkMetric struct {
+	Value      float64 `json:"value"`
+	Median     float64 `json:"median"`
+	Percentile float64 `json:"percentile"`
+	Rank       int     `json:"rank"`
+}
+
+// MemberBenchmark represents a set member ranked against the rest of the set
+type MemberBenchmark struct {
+	GroupID          uint            `json:"group_id"`
+	Domain           string          `json:"domain"`
+	PostingFrequency BenchmarkMetric `json:"posting_frequency"`
+	ER               BenchmarkMetric `json:"er"`
+	Reach            BenchmarkMetric `json:"reach"`
+	Growth           BenchmarkMetric `json:"growth"`
+	ShareOfVoice     float64         `json:"share_of_voice"`
+}
+
+// ExcludedMember is a set member left out of a benchmark because its wall
+// could not be read on the last sync
+type ExcludedMember struct {
+	GroupID uint   `json:"group_id"`
+	Domain  string `json:"domain"`
+	Status  string `json:"status"`
+}
+
+// SetBenchmark is the benchmarking report of a group set over a period
+type SetBenchmark struct {
+	SetID    uint              `json:"set_id"`
+	Name     string            `json:"name"`
+	Period   Period            `json:"period"`
+	Members  []MemberBenchmark `json:"members"`
+	Excluded []ExcludedMember  `json:"excluded"`
+}
+
+func NewGroupSetService(db *gorm.DB, dailyStats *DailyStatsService) *GroupSetService {
+	return &GroupSetService{db: db, dailyStats: dailyStats}
+}
+
+// List returns the group sets of a workspace with their members
+func (gs *GroupSetService) List(workspaceID uint) ([]models.GroupSet, error) {
+	var sets []models.GroupSet
+	err := gs.db.Preload("Groups").Where("workspace_id = ?", workspaceID).Order("name").Find(&sets).Error
+	return sets, err
+}
+
+// Get returns a group set of a workspace with its members; sets of other
+// workspaces are reported as not found
+func (gs *GroupSetService) Get(workspaceID, id uint) (*models.GroupSet, error) {
+	var set models.GroupSet
+	if err := gs.db.Preload("Groups").Where("workspace_id = ?", workspaceID).First(&set, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupSetNotFound
+		}
+		return nil, err
+	}
+	return &set, nil
+}
+
+// Create stores a new group set in a workspace
+func (gs *GroupSetService) Create(workspaceID uint, input GroupSetInput) (*models.GroupSet, error) {
+	groups, err := gs.resolveInput(workspaceID, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	set := models.GroupSet{
+		WorkspaceID: workspaceID,
+		Name:        input.Name,
+		Description: input.Description,
+		Groups:      groups,
+	}
+	if err := gs.db.Create(&set).Error; err != nil {
+		return nil, setWriteError(err)
+	}
+	return &set, nil
+}
+
+// Update replaces a group set's fields and member list
+func (gs *GroupSetService) Update(workspaceID, id uint, input GroupSetInput) (*models.GroupSet, error) {
+	set, err := gs.Get(workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := gs.resolveInput(workspaceID, &input)
+	if err != nil {
+		return nil, err
+	}
+
+	err = gs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(set).Updates(map[string]interface{}{
+			"name":        input.Name,
+			"description": input.Description,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(set).Association("Groups").Replace(groups)
+	})
+	if err != nil {
+		return nil, setWriteError(err)
+	}
+
+	return gs.Get(workspaceID, id)
+}
+
+// Delete removes a group set; member groups are kept
+func (gs *GroupSetService) Delete(workspaceID, id uint) error {
+	set, err := gs.Get(workspaceID, id)
+	if err != nil {
+		return err
+	}
+	return gs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(set).Association("Groups").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(set).Error
+	})
+}
+
+// Benchmark ranks every member of a set against the set for the given period
+func (gs *GroupSetService) Benchmark(workspaceID, id uint, period Period) (*SetBenchmark, error) {
+	if err := period.Validate(); err != nil {
+		return nil, err
+	}
+	set, err := gs.Get(workspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Groups that can no longer be read would drag the medians down with stale numbers
+	var active []models.Group
+	excluded := []ExcludedMember{}
+	for _, group := range set.Groups {
+		if group.IsActive() {
+			active = append(active, group)
+		} else {
+			excluded = append(excluded, ExcludedMember{GroupID: group.ID, Domain: group.Domain, Status: group.Status})
+		}
+	}
+
+	ids := make([]uint, len(active))
+	for i, group := range active {
+		ids[i] = group.ID
+	}
+	totals, err := gs.dailyStats.GetPeriodTotals(ids, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SetBenchmark{
+		SetID:    set.ID,
+		Name:     set.Name,
+		Period:   period,
+		Members:  benchmarkMembers(active, totals, periodDays(period)),
+		Excluded: excluded,
+	}, nil
+}
+
+// setWriteError reports a unique name violation as ErrGroupSetExists
+func setWriteError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrGroupSetExists
+	}
+	return err
+}
+
+// resolveInput validates the input and loads the referenced groups, which
+// must be tracked by the workspace
+func (gs *GroupSetService) resolveInput(workspaceID uint, input *GroupSetInput) ([]models.Group, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrGroupSetInvalid)
+	}
+
+	var groups []models.Group
+	if len(input.GroupIDs) == 0 {
+		return groups, nil
+	}
+	err := gs.db.Where("id IN ? AND id IN (?)", input.GroupIDs, trackedGroupIDs(gs.db, workspaceID)).Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) != len(uniqueIDs(input.GroupIDs)) {
+		return nil, fmt.Errorf("%w: unknown group id in group_ids", ErrGroupSetInvalid)
+	}
+	return groups, nil
+}
+
+// benchmarkMembers computes each member's metrics and places them within the set
+func benchmarkMembers(groups []models.Group, totals map[uint]PeriodTotals, days int) []MemberBenchmark {
+	n := len(groups)
+	frequency := make([]float64, n)
+	er := make([]float64, n)
+	reach := make([]float64, n)
+	growth := make([]float64, n)
+	engagement := make([]float64, n)
+	totalEngagement := 0.0
+
+	for i, group := range groups {
+		t := totals[group.ID]
+		frequency[i] = float64(t.Posts) / float64(days)
+		er[i] = engagementRate(t)
+		reach[i] = perPost(t.Views, t.Posts)
+		if t.StartSubscribers > 0 {
+			growth[i] = float64(t.Subscribers-t.StartSubscribers) / float64(t.StartSubscribers) * 100
+		}
+		engagement[i] = float64(t.Likes + t.Comments + t.Reposts)
+		totalEngagement += engagement[i]
+	}
+
+	members := make([]MemberBenchmark, n)
+	for i, group := range groups {
+		members[i] = MemberBenchmark{
+			GroupID:          group.ID,
+			Domain:           group.Domain,
+			PostingFrequency: placeInSet(frequency, i),
+			ER:               placeInSet(er, i),
+			Reach:            placeInSet(reach, i),
+			Growth:           placeInSet(growth, i),
+		}
+		if totalEngagement > 0 {
+			members[i].ShareOfVoice = engagement[i] / totalEngagement * 100
+		}
+	}
+
+	sort.SliceStable(members, func(a, b int) bool {
+		return members[a].ShareOfVoice > members[b].ShareOfVoice
+	})
+	return members
+}
+
+// placeInSet compares values[i] with the rest of the set
+func placeInSet(values []float64, i int) BenchmarkMetric {
+	v := values[i]
+	below, above := 0, 0
+	for j, other := range values {
+		if j == i {
+			continue
+		}
+		if other < v {
+			below++
+		} else if other > v {
+			above++
+		}
+	}
+
+	m := BenchmarkMetric{Value: v, Median: median(values), Rank: above + 1}
+	if len(values) > 1 {
+		m.Percentile = float64(below) / float64(len(values)-1) * 100
+	}
+	return m
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// periodDays returns the number of calendar days in a validated period
+func periodDays(p Period) int {
+	from, _ := time.Parse(DayLayout, p.From)
+	to, _ := time.Parse(DayLayout, p.To)
+	return int(to.Sub(from).Hours()/24) + 1
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	var out []uint
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+)
+
+// TestMedian tests median calculation for odd, even and empty inputs
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		expected float64
+	}{
+		{"Empty", []float64{}, 0},
+		{"Single", []float64{7}, 7},
+		{"Odd count", []float64{3, 1, 2}, 2},
+		{"Even count", []float64{4, 1, 3, 2}, 2.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := median(tt.values); got != tt.expected {
+				t.Errorf("Expected median %.2f, got %.2f", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestPlaceInSet tests rank and percentile of a value within the set
+func TestPlaceInSet(t *testing.T) {
+	values := []float64{10, 30, 20, 30}
+
+	top := placeInSet(values, 1)
+	if top.Rank != 1 {
+		t.Errorf("Expected rank 1 for tied top value, got %d", top.Rank)
+	}
+	if math.Abs(top.Percentile-200.0/3) > 1e-9 {
+		t.Errorf("Expected percentile %.2f, got %.2f", 200.0/3, top.Percentile)
+	}
+	if top.Median != 25 {
+		t.Errorf("Expected median 25, got %.2f", top.Median)
+	}
+
+	bottom := placeInSet(values, 0)
+	if bottom.Rank != 4 {
+		t.Errorf("Expected rank 4 for lowest value, got %d", bottom.Rank)
+	}
+	if bottom.Percentile != 0 {
+		t.Errorf("Expected percentile 0, got %.2f", bottom.Percentile)
+	}
+}
+
+// TestBenchmarkMembers tests share of voice, growth and ordering of members
+func TestBenchmarkMembers(t *testing.T) {
+	groups := []models.Group{
+		{ID: 1, Domain: "client"},
+		{ID: 2, Domain: "competitor"},
+		{ID: 3, Domain: "silent"},
+	}
+	totals := map[uint]PeriodTotals{
+		1: {Posts: 30, Views: 3000, Likes: 200, Comments: 50, Reposts: 50, StartSubscribers: 1000, Subscribers: 1100},
+		2: {Posts: 10, Views: 5000, Likes: 600, Comments: 100, Reposts: 0, StartSubscribers: 2000, Subscribers: 1900},
+	}
+
+	members := benchmarkMembers(groups, totals, 30)
+
+	if len(members) != 3 {
+		t.Fatalf("Expected 3 members, got %d", len(members))
+	}
+	if members[0].Domain != "competitor" {
+		t.Errorf("Expected competitor first by share of voice, got %s", members[0].Domain)
+	}
+	if members[0].ShareOfVoice != 70 {
+		t.Errorf("Expected share of voice 70, got %.2f", members[0].ShareOfVoice)
+	}
+	if members[1].Growth.Value != 10 {
+		t.Errorf("Expected client growth 10%%, got %.2f", members[1].Growth.Value)
+	}
+	if members[1].PostingFrequency.Value != 1 || members[1].PostingFrequency.Rank != 1 {
+		t.Errorf("Expected client to post daily and rank first, got %+v", members[1].PostingFrequency)
+	}
+	if members[2].ShareOfVoice != 0 || members[2].Reach.Value != 0 {
+		t.Errorf("Expected silent group to have no share of voice or reach, got %+v", members[2])
+	}
+}
+
+// TestPeriodDays tests inclusive day counting
+func TestPeriodDays(t *testing.T) {
+	if got := periodDays(Period{From: "2025-11-01", To: "2025-11-30"}); got != 30 {
+		t.Errorf("Expected 30 days, got %d", got)
+	}
+	if got := periodDays(Period{From: "2025-11-01", To: "2025-11-01"}); got != 1 {
+		t.Errorf("Expected 1 day, got %d", got)
+	}
+}
+
+// TestGroupSetBenchmarkSQLite tests creating a set and excluding inactive members from its benchmark
+func TestGroupSetBenchmarkSQLite(t *testing.T) {
+	db := dbtest.New(t)
+	groups := []models.Group{
+		{Domain: "leader", Status: models.GroupStatusActive},
+		{Domain: "runner", Status: models.GroupStatusActive},
+		{Domain: "gone", Status: models.GroupStatusDeleted},
+	}
+	if err := db.Create(&groups).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stats := []models.GroupDailyStats{
+		{GroupID: groups[0].ID, Day: "2025-12-01", Posts: 4, Views: 400, Likes: 40},
+		{GroupID: groups[1].ID, Day: "2025-12-01", Posts: 1, Views: 100, Likes: 1},
+	}
+	if err := db.Create(&stats).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	workspaceID := newTestWorkspace(t, db, groups[0].ID, groups[1].ID, groups[2].ID)
+	otherID := newTestWorkspace(t, db, groups[0].ID)
+
+	svc := NewGroupSetService(db, NewDailyStatsService(db))
+	set, err := svc.Create(workspaceID, GroupSetInput{Name: "Конкуренты", GroupIDs: []uint{groups[0].ID, groups[1].ID, groups[2].ID}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := svc.Create(workspaceID, GroupSetInput{Name: "Конкуренты", GroupIDs: []uint{groups[0].ID}}); !errors.Is(err, ErrGroupSetExists) {
+		t.Errorf("Expected ErrGroupSetExists, got %v", err)
+	}
+	if _, err := svc.Create(otherID, GroupSetInput{Name: "Конкуренты", GroupIDs: []uint{groups[1].ID}}); !errors.Is(err, ErrGroupSetInvalid) {
+		t.Errorf("Expected ErrGroupSetInvalid for a group the workspace does not track, got %v", err)
+	}
+	if _, err := svc.Create(otherID, GroupSetInput{Name: "Конкуренты", GroupIDs: []uint{groups[0].ID}}); err != nil {
+		t.Errorf("Expected another workspace to reuse the name, got %v", err)
+	}
+	if _, err := svc.Get(otherID, set.ID); !errors.Is(err, ErrGroupSetNotFound) {
+		t.Errorf("Expected ErrGroupSetNotFound for another workspace's set, got %v", err)
+	}
+
+	benchmark, err := svc.Benchmark(workspaceID, set.ID, Period{From: "2025-12-01", To: "2025-12-07"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(benchmark.Members) != 2 || len(benchmark.Excluded) != 1 || benchmark.Excluded[0].Domain != "gone" {
+		t.Fatalf("Expected 2 members and gone excluded, got %+v", benchmark)
+	}
+	for _, member := range benchmark.Members {
+		if member.Domain == "leader" && member.PostingFrequency.Rank != 1 {
+			t.Errorf("Expected leader to rank first by posting frequency, got %+v", member.PostingFrequency)
+		}
+	}
+}
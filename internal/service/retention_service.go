@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"social-media-analyzer/internal/config"
+	"social-media-analyzer/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RetentionService expires old data according to the configured policy.
+// Posts need no downsampling before they expire: every sync already rolls
+// them up into GroupDailyStats, which outlives them.
+type RetentionService struct {
+	db     *gorm.DB
+	policy config.RetentionConfig
+	now    func() time.Time
+
+	mu      sync.Mutex
+	lastRun *RetentionReport
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// RetentionReport lists what a maintenance run removed, or would remove on a dry run
+type RetentionReport struct {
+	DryRun     bool             `json:"dry_run"`
+	RanAt      time.Time        `json:"ran_at"`
+	Cutoffs    RetentionCutoffs `json:"cutoffs"`
+	Posts      int64            `json:"posts"`
+	DailyStats int64            `json:"daily_stats"`
+	Groups     []PurgedGroup    `json:"groups"`
+
+	// byWorkspace breaks a real run down by the workspaces tracking the
+	// affected groups, see LastRunFor
+	byWorkspace map[uint]*RetentionReport
+}
+
+// RetentionCutoffs are the dates before which each kind of data expires;
+// empty when that kind is kept forever
+type RetentionCutoffs struct {
+	Posts         string `json:"posts,omitempty"`
+	DailyStats    string `json:"daily_stats,omitempty"`
+	DeletedGroups string `json:"deleted_groups,omitempty"`
+}
+
+// PurgedGroup is a group deleted on VK long enough ago to be removed with all its data
+type PurgedGroup struct {
+	ID              uint       `json:"id"`
+	Domain          string     `json:"domain"`
+	Name            string     `json:"name"`
+	StatusChangedAt *time.Time `json:"status_changed_at"`
+}
+
+func NewRetentionService(db *gorm.DB, policy config.RetentionConfig) *RetentionService {
+	return &RetentionService{db: db, policy: policy, now: time.Now}
+}
+
+// Policy returns the configured retention periods
+func (rs *RetentionService) Policy() config.RetentionConfig {
+	return rs.policy
+}
+
+// Enabled reports whether any kind of data expires
+func (rs *RetentionService) Enabled() bool {
+	return rs.policy.PostDays > 0 || rs.policy.DailyStatsDays > 0 || rs.policy.DeletedGroupDays > 0
+}
+
+// LastRun returns the report of the last run of the background job, nil before the first one
+func (rs *RetentionService) LastRun() *RetentionReport {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.lastRun
+}
+
+// LastRunFor returns the last run of the background job as far as it
+// affected the groups a workspace tracked, nil before the first run
+func (rs *RetentionService) LastRunFor(workspaceID uint) *RetentionReport {
+	last := rs.LastRun()
+	if last == nil {
+		return nil
+	}
+	scoped := &RetentionReport{RanAt: last.RanAt, Cutoffs: last.Cutoffs, Groups: []PurgedGroup{}}
+	if part := last.byWorkspace[workspaceID]; part != nil {
+		scoped.Posts = part.Posts
+		scoped.DailyStats = part.DailyStats
+		scoped.Groups = part.Groups
+	}
+	return scoped
+}
+
+// Start runs the policy now and then every interval until Stop is called.
+// It does nothing when no retention is configured or the interval is zero.
+func (rs *RetentionService) Start(interval time.Duration) {
+	if !rs.Enabled() || interval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rs.cancel = cancel
+	rs.done = make(chan struct{})
+
+	go func() {
+		defer close(rs.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			rs.runScheduled(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop cancels a running maintenance pass and waits for the job to exit
+func (rs *RetentionService) Stop() {
+	if rs.cancel == nil {
+		return
+	}
+	rs.cancel()
+	<-rs.done
+}
+
+func (rs *RetentionService) runScheduled(ctx context.Context) {
+	report, err := rs.Run(ctx, false)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Retention run failed: %v\n", err)
+		}
+		return
+	}
+	log.Printf("Retention run removed %d posts, %d daily stats rows and %d deleted groups\n",
+		report.Posts, report.DailyStats, len(report.Groups))
+
+	rs.mu.Lock()
+	rs.lastRun = report
+	rs.mu.Unlock()
+}
+
+// Run applies the retention policy. With dryRun nothing is deleted and the
+// report lists what a real run would remove.
+func (rs *RetentionService) Run(ctx context.Context, dryRun bool) (*RetentionReport, error) {
+	return rs.run(ctx, dryRun, 0)
+}
+
+// Preview reports what a run would remove from the groups a workspace tracks
+func (rs *RetentionService) Preview(ctx context.Context, workspaceID uint) (*RetentionReport, error) {
+	return rs.run(ctx, true, workspaceID)
+}
+
+// run applies the policy to the groups a workspace tracks, or to all groups
+// when workspaceID is zero. A dry run only reads and runs outside a
+// transaction, so that it never takes the database's write lock.
+func (rs *RetentionService) run(ctx context.Context, dryRun bool, workspaceID uint) (*RetentionReport, error) {
+	now := rs.now().UTC()
+	report := &RetentionReport{DryRun: dryRun, RanAt: now, Groups: []PurgedGroup{}}
+
+	apply := func(tx *gorm.DB) error {
+		scope := func(query *gorm.DB, column string) *gorm.DB {
+			if workspaceID == 0 {
+				return query
+			}
+			return query.Where(column+" IN (?)", trackedGroupIDs(tx, workspaceID))
+		}
+
+		if days := rs.policy.DeletedGroupDays; days > 0 {
+			cutoff := now.AddDate(0, 0, -days)
+			report.Cutoffs.DeletedGroups = cutoff.Format(DayLayout)
+			if err := purgeDeletedGroups(tx, scope(tx, "id"), cutoff, dryRun, report); err != nil {
+				return fmt.Errorf("failed to purge deleted groups: %w", err)
+			}
+		}
+
+		// Rows of purged groups are already accounted for, even when a dry run left them in place
+		remaining := func() *gorm.DB {
+			query := scope(tx, "group_id")
+			if len(report.Groups) == 0 {
+				return query
+			}
+			return query.Where("group_id NOT IN ?", purgedIDs(report.Groups))
+		}
+
+		if days := rs.policy.PostDays; days > 0 {
+			report.Cutoffs.Posts = now.AddDate(0, 0, -days).Format(DayLayout)
+			count, err := expire(tx, remaining().Where("date < ?", report.Cutoffs.Posts), &models.Post{}, dryRun,
+				func(part *RetentionReport, n int64) { part.Posts += n }, report)
+			if err != nil {
+				return fmt.Errorf("failed to expire posts: %w", err)
+			}
+			report.Posts = count
+		}
+
+		if days := rs.policy.DailyStatsDays; days > 0 {
+			report.Cutoffs.DailyStats = now.AddDate(0, 0, -days).Format(DayLayout)
+			count, err := expire(tx, remaining().Where("day < ?", report.Cutoffs.DailyStats), &models.GroupDailyStats{}, dryRun,
+				func(part *RetentionReport, n int64) { part.DailyStats += n }, report)
+			if err != nil {
+				return fmt.Errorf("failed to expire daily stats: %w", err)
+			}
+			report.DailyStats = count
+		}
+		return nil
+	}
+
+	db := rs.db.WithContext(ctx)
+	var err error
+	if dryRun {
+		err = apply(db)
+	} else {
+		err = db.Transaction(apply)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// expire deletes the rows of model matched by query, or only counts them on a
+// dry run. A real run first counts the rows per group and adds them to the
+// report of every workspace tracking the group.
+func expire(tx, query *gorm.DB, model interface{}, dryRun bool, add func(*RetentionReport, int64), report *RetentionReport) (int64, error) {
+	if dryRun {
+		var count int64
+		err := query.Model(model).Count(&count).Error
+		return count, err
+	}
+
+	var perGroup []struct {
+		GroupID uint
+		Count   int64
+	}
+	if err := query.Session(&gorm.Session{}).Model(model).Select("group_id, COUNT(*) AS count").Group("group_id").Scan(&perGroup).Error; err != nil {
+		return 0, err
+	}
+	if len(perGroup) == 0 {
+		return 0, nil
+	}
+	counts := make(map[uint]int64, len(perGroup))
+	ids := make([]uint, len(perGroup))
+	for i, row := range perGroup {
+		counts[row.GroupID] = row.Count
+		ids[i] = row.GroupID
+	}
+	links, err := workspaceLinks(tx, ids)
+	if err != nil {
+		return 0, err
+	}
+	for _, link := range links {
+		add(report.part(link.WorkspaceID), counts[link.GroupID])
+	}
+
+	result := query.Delete(model)
+	return result.RowsAffected, result.Error
+}
+
+// part returns the breakdown of a real run for one workspace
+func (report *RetentionReport) part(workspaceID uint) *RetentionReport {
+	if report.byWorkspace == nil {
+		report.byWorkspace = make(map[uint]*RetentionReport)
+	}
+	part, ok := report.byWorkspace[workspaceID]
+	if !ok {
+		part = &RetentionReport{Groups: []PurgedGroup{}}
+		report.byWorkspace[workspaceID] = part
+	}
+	return part
+}
+
+// workspaceLinks returns which workspaces track the given groups
+func workspaceLinks(tx *gorm.DB, groupIDs []uint) ([]models.WorkspaceGroup, error) {
+	var links []models.WorkspaceGroup
+	err := tx.Session(&gorm.Session{NewDB: true}).
+		Where("group_id IN ?", groupIDs).
+		Order("workspace_id, group_id").
+		Find(&links).Error
+	return links, err
+}
+
+// purgeDeletedGroups removes groups among candidates deleted on VK before
+// cutoff together with their posts, rollups, rename history, tags, set
+// memberships and the workspaces' links to them. Every workspace that tracked
+// a purged group gets an audit event.
+func purgeDeletedGroups(tx, candidates *gorm.DB, cutoff time.Time, dryRun bool, report *RetentionReport) error {
+	var groups []models.Group
+	err := candidates.Where("status = ? AND status_changed_at < ?", models.GroupStatusDeleted, cutoff).
+		Order("id").
+		Find(&groups).Error
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		report.Groups = append(report.Groups, PurgedGroup{
+			ID:              group.ID,
+			Domain:          group.Domain,
+			Name:            group.Name,
+			StatusChangedAt: group.StatusChangedAt,
+		})
+	}
+	if dryRun || len(groups) == 0 {
+		return nil
+	}
+
+	ids := purgedIDs(report.Groups)
+	links, err := workspaceLinks(tx, ids)
+	if err != nil {
+		return err
+	}
+	if err := auditPurge(tx, groups, links); err != nil {
+		return err
+	}
+	for i, group := range groups {
+		for _, link := range links {
+			if link.GroupID == group.ID {
+				part := report.part(link.WorkspaceID)
+				part.Groups = append(part.Groups, report.Groups[i])
+			}
+		}
+	}
+	for _, table := range []string{"posts", "group_daily_stats", "group_screen_names", "group_tags", "group_set_members", "workspace_groups"} {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE group_id IN ?", ids).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Delete(&models.Group{}, ids).Error
+}
+
+// auditPurge records the purge of groups in the workspaces tracking them
+func auditPurge(tx *gorm.DB, groups []models.Group, links []models.WorkspaceGroup) error {
+	byID := make(map[uint]models.Group, len(groups))
+	for _, group := range groups {
+		byID[group.ID] = group
+	}
+	if len(links) == 0 {
+		return nil
+	}
+
+	events := make([]models.AuditEvent, len(links))
+	for i, link := range links {
+		workspaceID := link.WorkspaceID
+		group := byID[link.GroupID]
+		events[i] = models.AuditEvent{
+			WorkspaceID: &workspaceID,
+			Action:      models.AuditGroupPurge,
+			TargetType:  models.AuditTargetGroup,
+			TargetID:    AuditTargetID(group.ID),
+			Details: AuditDetails(map[string]interface{}{
+				"domain":            group.Domain,
+				"status_changed_at": group.StatusChangedAt,
+			}),
+		}
+	}
+	return tx.Create(&events).Error
+}
+
+func purgedIDs(groups []PurgedGroup) []uint {
+	ids := make([]uint, len(groups))
+	for i, group := range groups {
+		ids[i] = group.ID
+	}
+	return ids
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"social-media-analyzer/internal/config"
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/repo"
+
+	"gorm.io/gorm"
+)
+
+// seedRetention stores an active group with old and recent data, a group deleted
+// on VK long ago and one deleted recently; their tag and set belong to a
+// workspace that tracks none of them
+func seedRetention(t *testing.T, db *gorm.DB, now time.Time) (active, purged, recent models.Group) {
+	owner := newTestWorkspace(t, db)
+	longAgo := now.AddDate(0, 0, -100)
+	lately := now.AddDate(0, 0, -5)
+	active = models.Group{Domain: "active"}
+	purged = models.Group{Domain: "gone", Status: models.GroupStatusDeleted, StatusChangedAt: &longAgo, Tags: []models.Tag{{WorkspaceID: owner, Name: "client"}}}
+	recent = models.Group{Domain: "recent", Status: models.GroupStatusDeleted, StatusChangedAt: &lately}
+	for _, group := range []*models.Group{&active, &purged, &recent} {
+		if err := db.Create(group).Error; err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	rows := []interface{}{
+		&[]models.Post{
+			{GroupID: active.ID, Date: "2025-01-01"},
+			{GroupID: active.ID, Date: "2025-12-30"},
+			{GroupID: purged.ID, Date: "2025-01-01"},
+		},
+		&[]models.GroupDailyStats{
+			{GroupID: active.ID, Day: "2024-01-01"},
+			{GroupID: active.ID, Day: "2025-12-30"},
+			{GroupID: purged.ID, Day: "2024-01-01"},
+		},
+		&models.GroupScreenName{GroupID: purged.ID, ScreenName: "old", RenamedTo: "gone", RenamedAt: longAgo},
+		&models.GroupSet{WorkspaceID: owner, Name: "set", Groups: []models.Group{active, purged}},
+	}
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	return active, purged, recent
+}
+
+// TestRetentionDryRunMatchesRun tests that a dry run reports exactly what a real run removes
+func TestRetentionDryRunMatchesRun(t *testing.T) {
+	db := dbtest.New(t)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	active, purged, recent := seedRetention(t, db, now)
+	workspaceID := newTestWorkspace(t, db, purged.ID)
+
+	rs := NewRetentionService(db, config.RetentionConfig{PostDays: 30, DailyStatsDays: 365, DeletedGroupDays: 30})
+	rs.now = func() time.Time { return now }
+
+	preview, err := rs.Run(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if preview.Posts != 1 || preview.DailyStats != 1 || len(preview.Groups) != 1 || preview.Groups[0].ID != purged.ID {
+		t.Fatalf("Unexpected dry-run report %+v", preview)
+	}
+	var posts, events int64
+	db.Model(&models.Post{}).Count(&posts)
+	if posts != 3 {
+		t.Fatalf("Expected a dry run to keep all 3 posts, got %d", posts)
+	}
+	db.Model(&models.AuditEvent{}).Count(&events)
+	if events != 0 {
+		t.Fatalf("Expected a dry run to record no audit events, got %d", events)
+	}
+
+	report, err := rs.Run(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Posts != preview.Posts || report.DailyStats != preview.DailyStats || len(report.Groups) != len(preview.Groups) {
+		t.Errorf("Expected the run to match the dry run %+v, got %+v", preview, report)
+	}
+
+	for _, check := range []struct {
+		name  string
+		query *gorm.DB
+		want  int64
+	}{
+		{"posts of active group", db.Model(&models.Post{}).Where("group_id = ?", active.ID), 1},
+		{"daily stats of active group", db.Model(&models.GroupDailyStats{}).Where("group_id = ?", active.ID), 1},
+		{"purged group", db.Model(&models.Group{}).Where("id = ?", purged.ID), 0},
+		{"recently deleted group", db.Model(&models.Group{}).Where("id = ?", recent.ID), 1},
+		{"purged posts", db.Model(&models.Post{}).Where("group_id = ?", purged.ID), 0},
+		{"purged rollups", db.Model(&models.GroupDailyStats{}).Where("group_id = ?", purged.ID), 0},
+		{"purged screen names", db.Model(&models.GroupScreenName{}).Where("group_id = ?", purged.ID), 0},
+		{"purged tags", db.Table("group_tags").Where("group_id = ?", purged.ID), 0},
+		{"set members", db.Table("group_set_members"), 1},
+		{"purge audit events", db.Model(&models.AuditEvent{}).Where("workspace_id = ? AND action = ? AND target_id = ?",
+			workspaceID, models.AuditGroupPurge, AuditTargetID(purged.ID)), 1},
+	} {
+		var count int64
+		if err := check.query.Count(&count).Error; err != nil {
+			t.Fatalf("%s: unexpected error: %v", check.name, err)
+		}
+		if count != check.want {
+			t.Errorf("%s: expected %d rows, got %d", check.name, check.want, count)
+		}
+	}
+}
+
+// TestRetentionDisabled tests that an empty policy keeps everything and starts no job
+func TestRetentionDisabled(t *testing.T) {
+	db := dbtest.New(t)
+	seedRetention(t, db, time.Now().UTC())
+
+	rs := NewRetentionService(db, config.RetentionConfig{Interval: time.Hour})
+	if rs.Enabled() {
+		t.Error("Expected retention to be disabled")
+	}
+	rs.Start(time.Millisecond)
+	rs.Stop()
+
+	report, err := rs.Run(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Posts != 0 || report.DailyStats != 0 || len(report.Groups) != 0 {
+		t.Errorf("Expected nothing removed, got %+v", report)
+	}
+}
+
+// TestRetentionAfterSync tests that a sync does not store posts again that
+// retention has already removed
+func TestRetentionAfterSync(t *testing.T) {
+	now := time.Now().UTC()
+	old, recent := now.AddDate(0, 0, -60), now.AddDate(0, 0, -1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/groups.getById":
+			fmt.Fprint(w, `{"response":[{"id":42,"name":"Brand","screen_name":"brand","members_count":900,"type":"page"}]}`)
+		case "/wall.get":
+			fmt.Fprintf(w, `{"response":{"count":2,"items":[
+				{"id":2,"date":%d,"text":"new","views":{"count":20}},
+				{"id":1,"date":%d,"text":"old","views":{"count":10}}]}}`, recent.Unix(), old.Unix())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	vk := &VKService{accessToken: "token", apiVersion: "5.131", apiURL: server.URL, httpClient: server.Client()}
+
+	db := dbtest.New(t)
+	policy := config.RetentionConfig{PostDays: 30}
+	groups := repo.NewGormGroupRepository(db)
+	ss := NewSyncService(groups, repo.NewGormPostRepository(db), vk, NewDailyStatsService(db), NewGroupIdentityService(groups), policy.PostDays)
+	rs := NewRetentionService(db, policy)
+
+	group := &models.Group{VKID: intPtr(42), Domain: "brand"}
+	if err := groups.Create(group); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// A post stored before retention was enabled
+	if err := db.Create(&models.Post{GroupID: group.ID, Date: old.Format(DayLayout)}).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i, want := range []int64{1, 0} {
+		if i > 0 {
+			if err := ss.SyncGroup(context.Background(), group); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		report, err := rs.Run(context.Background(), false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if report.Posts != want {
+			t.Errorf("Run %d: expected %d removed posts, got %d", i+1, want, report.Posts)
+		}
+	}
+
+	var posts int64
+	db.Model(&models.Post{}).Where("group_id = ?", group.ID).Count(&posts)
+	if posts != 1 {
+		t.Errorf("Expected only the recent post to be stored, got %d", posts)
+	}
+	trend, _ := NewDailyStatsService(db).GetTrend(group.ID, old.Format(DayLayout), old.Format(DayLayout))
+	if len(trend) != 1 || trend[0].Views != 10 {
+		t.Errorf("Expected the expired post to be rolled up, got %+v", trend)
+	}
+}
+
+// TestRetentionScopedToWorkspace tests that previews and the last run only
+// show a workspace the groups it tracks
+func TestRetentionScopedToWorkspace(t *testing.T) {
+	db := dbtest.New(t)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	active, purged, recent := seedRetention(t, db, now)
+	first := newTestWorkspace(t, db, active.ID, purged.ID)
+	second := newTestWorkspace(t, db, recent.ID)
+
+	rs := NewRetentionService(db, config.RetentionConfig{PostDays: 30, DailyStatsDays: 365, DeletedGroupDays: 30})
+	rs.now = func() time.Time { return now }
+
+	cases := []struct {
+		name        string
+		workspaceID uint
+		posts       int64
+		groups      int
+	}{
+		{"tracking the purged group", first, 1, 1},
+		{"tracking neither", second, 0, 0},
+	}
+	for _, tc := range cases {
+		preview, err := rs.Preview(context.Background(), tc.workspaceID)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if preview.Posts != tc.posts || len(preview.Groups) != tc.groups {
+			t.Errorf("%s: expected %d posts and %d groups in the preview, got %+v", tc.name, tc.posts, tc.groups, preview)
+		}
+	}
+
+	rs.runScheduled(context.Background())
+	for _, tc := range cases {
+		last := rs.LastRunFor(tc.workspaceID)
+		if last == nil || last.Posts != tc.posts || len(last.Groups) != tc.groups {
+			t.Errorf("%s: expected %d posts and %d groups in the last run, got %+v", tc.name, tc.posts, tc.groups, last)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"social-media-analyzer/internal/config"
+	"social-media-analyzer/internal/repo"
+
 	"gorm.io/gorm"
 )
 
@@ -14,12 +16,24 @@ type ServiceFactory struct {
 
 // ServiceContainer holds all initialized services
 type ServiceContainer struct {
-	VKService             *VKService
-	AnalyticsService      *AnalyticsService
-	TemplateDataService   *TemplateDataService
-	AggregateStrategy     StatisticsStrategy
-	EngagementStrategy    StatisticsStrategy
-	PerformanceStrategy   StatisticsStrategy
+	VKService            *VKService
+	AnalyticsService     *AnalyticsService
+	DailyStatsService    *DailyStatsService
+	ComparisonService    *ComparisonService
+	GroupSetService      *GroupSetService
+	TagService           *TagService
+	GroupIdentityService *GroupIdentityService
+	SyncService          *SyncService
+	RetentionService     *RetentionService
+	AuthService          *AuthService
+	APIKeyService        *APIKeyService
+	WorkspaceService     *WorkspaceService
+	AuditService         *AuditService
+	GroupImportService   *GroupImportService
+	TemplateDataService  *TemplateDataService
+	AggregateStrategy    StatisticsStrategy
+	EngagementStrategy   StatisticsStrategy
+	PerformanceStrategy  StatisticsStrategy
 }
 
 // NewServiceFactory creates a new service factory
@@ -32,9 +46,25 @@ func NewServiceFactory(cfg *config.Config, db *gorm.DB) *ServiceFactory {
 
 // CreateServices creates and initializes all application services
 func (sf *ServiceFactory) CreateServices() *ServiceContainer {
+	// Create repositories
+	groupRepo := repo.NewGormGroupRepository(sf.db)
+	postRepo := repo.NewGormPostRepository(sf.db)
+
 	// Create core services
 	vkService := sf.createVKService()
-	analyticsService := sf.createAnalyticsService()
+	analyticsService := sf.createAnalyticsService(groupRepo, postRepo)
+	dailyStatsService := sf.createDailyStatsService()
+	comparisonService := sf.createComparisonService(dailyStatsService)
+	groupSetService := sf.createGroupSetService(dailyStatsService)
+	tagService := sf.createTagService()
+	groupIdentityService := sf.createGroupIdentityService(groupRepo)
+	syncService := sf.createSyncService(groupRepo, postRepo, vkService, dailyStatsService, groupIdentityService)
+	retentionService := sf.createRetentionService()
+	authService := sf.createAuthService()
+	apiKeyService := sf.createAPIKeyService()
+	workspaceService := sf.createWorkspaceService()
+	auditService := sf.createAuditService()
+	groupImportService := sf.createGroupImportService(vkService, groupIdentityService, syncService, workspaceService)
 	templateDataService := sf.createTemplateDataService(analyticsService)
 
 	// Create statistics strategies
@@ -43,12 +73,24 @@ func (sf *ServiceFactory) CreateServices() *ServiceContainer {
 	performanceStrategy := &PerformanceStatsStrategy{}
 
 	return &ServiceContainer{
-		VKService:           vkService,
-		AnalyticsService:    analyticsService,
-		TemplateDataService: templateDataService,
-		AggregateStrategy:   aggregateStrategy,
-		EngagementStrategy:  engagementStrategy,
-		PerformanceStrategy: performanceStrategy,
+		VKService:            vkService,
+		AnalyticsService:     analyticsService,
+		DailyStatsService:    dailyStatsService,
+		ComparisonService:    comparisonService,
+		GroupSetService:      groupSetService,
+		TagService:           tagService,
+		GroupIdentityService: groupIdentityService,
+		SyncService:          syncService,
+		RetentionService:     retentionService,
+		AuthService:          authService,
+		APIKeyService:        apiKeyService,
+		WorkspaceService:     workspaceService,
+		AuditService:         auditService,
+		GroupImportService:   groupImportService,
+		TemplateDataService:  templateDataService,
+		AggregateStrategy:    aggregateStrategy,
+		EngagementStrategy:   engagementStrategy,
+		PerformanceStrategy:  performanceStrategy,
 	}
 }
 
@@ -58,8 +100,68 @@ func (sf *ServiceFactory) createVKService() *VKService {
 }
 
 // createAnalyticsService creates and configures analytics service
-func (sf *ServiceFactory) createAnalyticsService() *AnalyticsService {
-	return NewAnalyticsService(sf.db)
+func (sf *ServiceFactory) createAnalyticsService(groupRepo repo.GroupRepository, postRepo repo.PostRepository) *AnalyticsService {
+	return NewAnalyticsService(groupRepo, postRepo)
+}
+
+// createDailyStatsService creates and configures daily rollup service
+func (sf *ServiceFactory) createDailyStatsService() *DailyStatsService {
+	return NewDailyStatsService(sf.db)
+}
+
+// createComparisonService creates and configures period comparison service
+func (sf *ServiceFactory) createComparisonService(dailyStatsService *DailyStatsService) *ComparisonService {
+	return NewComparisonService(sf.db, dailyStatsService)
+}
+
+// createGroupSetService creates and configures group set service
+func (sf *ServiceFactory) createGroupSetService(dailyStatsService *DailyStatsService) *GroupSetService {
+	return NewGroupSetService(sf.db, dailyStatsService)
+}
+
+// createTagService creates and configures group tag service
+func (sf *ServiceFactory) createTagService() *TagService {
+	return NewTagService(sf.db)
+}
+
+// createGroupIdentityService creates and configures group identity service
+func (sf *ServiceFactory) createGroupIdentityService(groupRepo repo.GroupRepository) *GroupIdentityService {
+	return NewGroupIdentityService(groupRepo)
+}
+
+// createSyncService creates and configures background post sync service
+func (sf *ServiceFactory) createSyncService(groupRepo repo.GroupRepository, postRepo repo.PostRepository, vkService *VKService, dailyStatsService *DailyStatsService, groupIdentityService *GroupIdentityService) *SyncService {
+	return NewSyncService(groupRepo, postRepo, vkService, dailyStatsService, groupIdentityService, sf.config.Retention.PostDays)
+}
+
+// createRetentionService creates and configures data retention service
+func (sf *ServiceFactory) createRetentionService() *RetentionService {
+	return NewRetentionService(sf.db, sf.config.Retention)
+}
+
+// createAuthService creates and configures user account and session service
+func (sf *ServiceFactory) createAuthService() *AuthService {
+	return NewAuthService(sf.db, sf.config.Auth)
+}
+
+// createAPIKeyService creates and configures API key service
+func (sf *ServiceFactory) createAPIKeyService() *APIKeyService {
+	return NewAPIKeyService(sf.db)
+}
+
+// createWorkspaceService creates and configures workspace and membership service
+func (sf *ServiceFactory) createWorkspaceService() *WorkspaceService {
+	return NewWorkspaceService(sf.db)
+}
+
+// createAuditService creates and configures audit log service
+func (sf *ServiceFactory) createAuditService() *AuditService {
+	return NewAuditService(sf.db)
+}
+
+// createGroupImportService creates and configures bulk group import service
+func (sf *ServiceFactory) createGroupImportService(vkService *VKService, groupIdentityService *GroupIdentityService, syncService *SyncService, workspaceService *WorkspaceService) *GroupImportService {
+	return NewGroupImportService(vkService, groupIdentityService, syncService, workspaceService)
 }
 
 // createTemplateDataService creates and configures template data service
@@ -74,6 +176,6 @@ func (sf *ServiceFactory) CreateVKServiceOnly() *VKService {
 
 // CreateAnalyticsServicesOnly creates analytics service with strategies
 func (sf *ServiceFactory) CreateAnalyticsServicesOnly() (*AnalyticsService, StatisticsStrategy, StatisticsStrategy, StatisticsStrategy) {
-	analyticsService := sf.createAnalyticsService()
+	analyticsService := sf.createAnalyticsService(repo.NewGormGroupRepository(sf.db), repo.NewGormPostRepository(sf.db))
 	return analyticsService, &AggregateStatsStrategy{}, &EngagementRateStrategy{}, &PerformanceStatsStrategy{}
 }
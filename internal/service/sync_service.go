@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/repo"
+)
+
+// syncQueueSize bounds the number of groups waiting to be parsed
+const syncQueueSize = 1000
+
+var (
+	ErrSyncNotRunning     = errors.New("no sync scheduled for this group")
+	ErrSyncOtherWorkspace = errors.New("sync was scheduled by another workspace")
+)
+
+// SyncService fetches wall posts of groups in the background using a fixed worker pool
+type SyncService struct {
+	groups     repo.GroupRepository
+	posts      repo.PostRepository
+	vkService  *VKService
+	dailyStats *DailyStatsService
+	identity   *GroupIdentityService
+	queue      chan *syncJob
+	// postDays is the post retention period; older posts are not stored
+	// again, so that retention does not remove them after every sync
+	postDays int
+
+	// ctx is the parent of every job; cancelling it aborts all syncs
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	mu      sync.Mutex
+	jobs    map[uint]*syncJob
+	stopped bool
+}
+
+// syncJob is a queued or running sync of one group
+type syncJob struct {
+	group  models.Group
+	ctx    context.Context
+	cancel context.CancelFunc
+	// workspaces scheduled the sync and may cancel it
+	workspaces map[uint]bool
+	started    bool
+}
+
+func NewSyncService(groups repo.GroupRepository, posts repo.PostRepository, vkService *VKService, dailyStats *DailyStatsService, identity *GroupIdentityService, postDays int) *SyncService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SyncService{
+		groups:     groups,
+		posts:      posts,
+		vkService:  vkService,
+		dailyStats: dailyStats,
+		identity:   identity,
+		queue:      make(chan *syncJob, syncQueueSize),
+		postDays:   postDays,
+		ctx:        ctx,
+		cancel:     cancel,
+		jobs:       make(map[uint]*syncJob),
+	}
+}
+
+// Start launches the given number of workers consuming the sync queue
+func (ss *SyncService) Start(workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		ss.workers.Add(1)
+		go ss.worker()
+	}
+}
+
+// Enqueue schedules a group for parsing on behalf of a workspace; a group that
+// is already queued or running is not scheduled twice, the workspace joins its
+// sync instead. It returns false if the queue is full or shut down.
+func (ss *SyncService) Enqueue(workspaceID uint, group models.Group) bool {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.stopped {
+		return false
+	}
+	if job, ok := ss.jobs[group.ID]; ok {
+		job.workspaces[workspaceID] = true
+		return true
+	}
+
+	ctx, cancel := context.WithCancel(ss.ctx)
+	job := &syncJob{group: group, ctx: ctx, cancel: cancel, workspaces: map[uint]bool{workspaceID: true}}
+	select {
+	case ss.queue <- job:
+		ss.jobs[group.ID] = job
+		return true
+	default:
+		cancel()
+		log.Printf("Sync queue is full, dropping group %s\n", group.Domain)
+		return false
+	}
+}
+
+// Cancel aborts the queued or running sync of a group. Groups are shared, so
+// only a workspace that scheduled the sync may cancel it.
+func (ss *SyncService) Cancel(workspaceID, groupID uint) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	job, ok := ss.jobs[groupID]
+	if !ok {
+		return ErrSyncNotRunning
+	}
+	if !job.workspaces[workspaceID] {
+		return ErrSyncOtherWorkspace
+	}
+	job.cancel()
+	delete(ss.jobs, groupID)
+	return nil
+}
+
+// Shutdown stops accepting groups, drops queued syncs and waits for running ones
+// to finish. When ctx expires first, running syncs are cancelled.
+func (ss *SyncService) Shutdown(ctx context.Context) error {
+	ss.mu.Lock()
+	if !ss.stopped {
+		ss.stopped = true
+		close(ss.queue)
+		for id, job := range ss.jobs {
+			if !job.started {
+				job.cancel()
+				delete(ss.jobs, id)
+			}
+		}
+	}
+	ss.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		ss.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		ss.cancel()
+		return nil
+	case <-ctx.Done():
+		ss.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (ss *SyncService) worker() {
+	defer ss.workers.Done()
+
+	for job := range ss.queue {
+		if !ss.begin(job) {
+			continue
+		}
+		err := ss.SyncGroup(job.ctx, &job.group)
+		switch {
+		case errors.Is(err, context.Canceled):
+			log.Printf("Sync of group %s was cancelled\n", job.group.Domain)
+		case err != nil:
+			log.Printf("Failed to fetch wall posts for group %s: %v\n", job.group.Domain, err)
+		}
+		ss.finish(job)
+	}
+}
+
+// begin marks a job as running unless it was cancelled while queued
+func (ss *SyncService) begin(job *syncJob) bool {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if job.ctx.Err() != nil {
+		return false
+	}
+	job.started = true
+	return true
+}
+
+// finish releases a job once its sync has returned
+func (ss *SyncService) finish(job *syncJob) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	job.cancel()
+	if ss.jobs[job.group.ID] == job {
+		delete(ss.jobs, job.group.ID)
+	}
+}
+
+// SyncGroup refreshes group metadata, fetches wall posts from VK API, replaces the
+// stored posts and refreshes rollups. Groups whose wall can't be read keep their
+// posts and get a status explaining why.
+func (ss *SyncService) SyncGroup(ctx context.Context, group *models.Group) error {
+	// Pick up renames, deactivation and other metadata changes; posts can still be fetched if this fails
+	closed := group.IsClosed != 0
+	fetched, err := ss.vkService.RefreshGroupInfo(ctx, group)
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case errors.Is(err, ErrGroupNotFound):
+		return ss.setStatus(group, models.GroupStatusDeleted)
+	case err != nil:
+		log.Printf("Failed to refresh info for group %s: %v\n", group.Domain, err)
+	default:
+		if err := ss.identity.Refresh(group, fetched); err != nil {
+			log.Printf("Failed to update info for group %s: %v\n", group.Domain, err)
+		}
+		if fetched.Status == models.GroupStatusBanned || fetched.Status == models.GroupStatusDeleted {
+			return ss.setStatus(group, fetched.Status)
+		}
+		closed = fetched.IsClosed != 0
+	}
+
+	// Fetch wall posts from VK API
+	wallPosts, total, err := ss.vkService.GetWallPosts(ctx, group, 100)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		status, ok := WallErrorStatus(err)
+		if !ok {
+			return err
+		}
+		// Closed communities deny access to non-members the same way a disabled wall does
+		if status == models.GroupStatusWallDisabled && closed {
+			status = models.GroupStatusClosed
+		}
+		return ss.setStatus(group, status)
+	}
+	// Past this point the stored posts are replaced; a cancellation no longer applies
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := ss.setStatus(group, models.GroupStatusActive); err != nil {
+		return err
+	}
+
+	// Replace the stored posts in one transaction so that an interrupted sync
+	// never leaves a group with half of its posts
+	wallPosts, complete := contiguousPosts(wallPosts, total)
+	posts := make([]models.Post, len(wallPosts))
+	for i, vkPost := range wallPosts {
+		posts[i] = models.Post{
+			GroupID:   group.ID,
+			Date:      time.Unix(int64(vkPost.Date), 0).UTC().Format(DayLayout),
+			Text:      vkPost.Text,
+			Views:     vkPost.Views.Count,
+			Reactions: vkPost.Likes.Count, // Using likes as reactions for now
+			Likes:     vkPost.Likes.Count,
+			Comments:  vkPost.Comments.Count,
+			Reposts:   vkPost.Reposts.Count,
+		}
+	}
+	err = ss.posts.ReplaceForGroup(group.ID, ss.unexpired(posts))
+	if err != nil {
+		return fmt.Errorf("failed to save posts for group %s: %w", group.Domain, err)
+	}
+
+	// Keep daily rollups in sync with the fetched posts, expired ones included
+	if err := ss.dailyStats.RefreshGroup(group, posts, complete); err != nil {
+		return fmt.Errorf("failed to refresh daily stats: %w", err)
+	}
+
+	return nil
+}
+
+// unexpired returns the posts the post retention period keeps
+func (ss *SyncService) unexpired(posts []models.Post) []models.Post {
+	if ss.postDays <= 0 {
+		return posts
+	}
+	cutoff := time.Now().UTC().AddDate(0, 0, -ss.postDays).Format(DayLayout)
+	kept := make([]models.Post, 0, len(posts))
+	for _, post := range posts {
+		if post.Date >= cutoff {
+			kept = append(kept, post)
+		}
+	}
+	return kept
+}
+
+// contiguousPosts drops a pinned post older than the rest of a wall.get page,
+// so that the page covers every post from its oldest one until now. complete
+// reports whether the page holds the whole wall, whose oldest day then has
+// all of its posts too.
+func contiguousPosts(items []VKWallPost, total int) ([]VKWallPost, bool) {
+	if len(items) >= total {
+		return items, true
+	}
+
+	oldest := 0
+	for _, item := range items {
+		if item.IsPinned == 0 && (oldest == 0 || item.Date < oldest) {
+			oldest = item.Date
+		}
+	}
+	posts := make([]VKWallPost, 0, len(items))
+	for _, item := range items {
+		if item.IsPinned != 0 && item.Date < oldest {
+			continue
+		}
+		posts = append(posts, item)
+	}
+	return posts, false
+}
+
+// setStatus stores a group's status, stamping the time when it changes
+func (ss *SyncService) setStatus(group *models.Group, status string) error {
+	if group.Status == status {
+		return nil
+	}
+	if !group.IsActive() || status != models.GroupStatusActive {
+		log.Printf("Group %s status changed from %q to %q\n", group.Domain, group.Status, status)
+	}
+
+	if err := ss.groups.SetStatus(group, status, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to update group status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/repo"
+)
+
+// newBlockingVKService returns a VK client whose requests hang until cancelled,
+// signalling on started whenever a request arrives
+func newBlockingVKService(t *testing.T) (*VKService, chan struct{}) {
+	started := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	return &VKService{accessToken: "token", apiVersion: "5.131", apiURL: server.URL, httpClient: server.Client()}, started
+}
+
+func waitStarted(t *testing.T, started chan struct{}) {
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Sync did not start")
+	}
+}
+
+// TestSyncServiceCancel tests cancelling a running sync through the API method,
+// which only the workspace that scheduled it may do
+func TestSyncServiceCancel(t *testing.T) {
+	vk, started := newBlockingVKService(t)
+	ss := NewSyncService(nil, nil, vk, nil, nil, 0)
+	ss.Start(1)
+
+	group := models.Group{ID: 1, Domain: "slow"}
+	if !ss.Enqueue(1, group) {
+		t.Fatal("Expected group to be enqueued")
+	}
+	if !ss.Enqueue(1, group) {
+		t.Error("Expected a second enqueue of the same group to be accepted")
+	}
+	waitStarted(t, started)
+
+	if err := ss.Cancel(2, 1); !errors.Is(err, ErrSyncOtherWorkspace) {
+		t.Errorf("Expected ErrSyncOtherWorkspace, got %v", err)
+	}
+	if err := ss.Cancel(1, 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := ss.Cancel(1, 1); !errors.Is(err, ErrSyncNotRunning) {
+		t.Errorf("Expected ErrSyncNotRunning, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := ss.Shutdown(ctx); err != nil {
+		t.Errorf("Expected workers to drain, got %v", err)
+	}
+}
+
+// TestSyncServiceShutdownDeadline tests that running syncs are aborted when the drain deadline passes
+func TestSyncServiceShutdownDeadline(t *testing.T) {
+	vk, started := newBlockingVKService(t)
+	ss := NewSyncService(nil, nil, vk, nil, nil, 0)
+	ss.Start(1)
+
+	ss.Enqueue(1, models.Group{ID: 1, Domain: "slow"})
+	ss.Enqueue(1, models.Group{ID: 2, Domain: "queued"})
+	waitStarted(t, started)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := ss.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+
+	if ss.Enqueue(1, models.Group{ID: 3, Domain: "late"}) {
+		t.Error("Expected enqueue after shutdown to be rejected")
+	}
+	select {
+	case <-started:
+		t.Error("Expected the queued group to be dropped on shutdown")
+	default:
+	}
+}
+
+// TestSyncGroupSQLite tests a full sync against the GORM repositories: a rename
+// is recorded, posts are stored and rolled up into daily stats
+func TestSyncGroupSQLite(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/groups.getById":
+			fmt.Fprint(w, `{"response":[{"id":42,"name":"New Brand","screen_name":"newbrand","members_count":900,"type":"page"}]}`)
+		case "/wall.get":
+			fmt.Fprint(w, `{"response":{"count":2,"items":[
+				{"id":1,"date":1764590400,"text":"a","likes":{"count":10},"views":{"count":100}},
+				{"id":2,"date":1764594000,"text":"b","likes":{"count":5},"views":{"count":50}}]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	vk := &VKService{accessToken: "token", apiVersion: "5.131", apiURL: server.URL, httpClient: server.Client()}
+
+	db := dbtest.New(t)
+	groups := repo.NewGormGroupRepository(db)
+	identity := NewGroupIdentityService(groups)
+	ss := NewSyncService(groups, repo.NewGormPostRepository(db), vk, NewDailyStatsService(db), identity, 0)
+
+	group := &models.Group{VKID: intPtr(42), Domain: "oldbrand"}
+	if err := groups.Create(group); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := ss.SyncGroup(context.Background(), group); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stored, err := groups.GetByID(group.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stored.Domain != "newbrand" || stored.Subscribers != 900 || stored.Status != models.GroupStatusActive {
+		t.Errorf("Unexpected stored group %+v", stored)
+	}
+	history, _ := identity.ScreenNameHistory(group.ID)
+	if len(history) != 1 || history[0].ScreenName != "oldbrand" {
+		t.Errorf("Expected rename from oldbrand, got %+v", history)
+	}
+
+	trend, err := NewDailyStatsService(db).GetTrend(group.ID, "2025-12-01", "2025-12-01")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(trend) != 1 || trend[0].Posts != 2 || trend[0].Views != 150 {
+		t.Errorf("Expected 2 posts with 150 views on 2025-12-01, got %+v", trend)
+	}
+}
+
+// TestSyncGroupKeepsHistoryOutsideWindow tests that an old pinned post and a
+// partly fetched oldest day don't wipe or lower stored rollups
+func TestSyncGroupKeepsHistoryOutsideWindow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/groups.getById":
+			fmt.Fprint(w, `{"response":[{"id":42,"name":"Brand","screen_name":"brand","members_count":900,"type":"page"}]}`)
+		case "/wall.get":
+			// The wall has more posts than fit into one page; the pinned one is a year old
+			fmt.Fprint(w, `{"response":{"count":10,"items":[
+				{"id":1,"date":1733054400,"is_pinned":1,"text":"pinned","views":{"count":999}},
+				{"id":9,"date":1764676800,"text":"c","views":{"count":20}},
+				{"id":8,"date":1764590400,"text":"b","views":{"count":10}},
+				{"id":7,"date":1764504000,"text":"a","views":{"count":5}}]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	vk := &VKService{accessToken: "token", apiVersion: "5.131", apiURL: server.URL, httpClient: server.Client()}
+
+	db := dbtest.New(t)
+	groups := repo.NewGormGroupRepository(db)
+	stats := NewDailyStatsService(db)
+	ss := NewSyncService(groups, repo.NewGormPostRepository(db), vk, stats, NewGroupIdentityService(groups), 0)
+
+	group := &models.Group{VKID: intPtr(42), Domain: "brand"}
+	if err := groups.Create(group); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Rollups of earlier syncs, when these days were fully fetched
+	history := []models.GroupDailyStats{
+		{GroupID: group.ID, Day: "2024-12-01", Posts: 3, Views: 300},
+		{GroupID: group.ID, Day: "2025-11-30", Posts: 2, Views: 80},
+		{GroupID: group.ID, Day: "2025-12-01", Posts: 5, Views: 500},
+	}
+	if err := db.Create(&history).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := ss.SyncGroup(context.Background(), group); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var posts int64
+	db.Model(&models.Post{}).Where("group_id = ?", group.ID).Count(&posts)
+	if posts != 3 {
+		t.Errorf("Expected the old pinned post to be skipped, got %d stored posts", posts)
+	}
+
+	trend, err := stats.GetTrend(group.ID, "2024-01-01", "2025-12-02")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[string][2]int{
+		"2024-12-01": {3, 300}, // before the window, kept despite the pinned post
+		"2025-11-30": {2, 80},  // partly fetched, not lowered
+		"2025-12-01": {1, 10},  // fully fetched, replaced
+		"2025-12-02": {1, 20},
+	}
+	if len(trend) != len(want) {
+		t.Fatalf("Expected %d days, got %+v", len(want), trend)
+	}
+	for _, row := range trend {
+		if got := [2]int{row.Posts, row.Views}; got != want[row.Day] {
+			t.Errorf("%s: expected posts and views %v, got %v", row.Day, want[row.Day], got)
+		}
+	}
+}
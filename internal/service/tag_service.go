@@ -0,0 +1,230 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/repo"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrGroupNotFound = errors.New("group not found")
+	ErrInvalidTag    = errors.New("invalid tag")
+)
+
+// MaxTagLength limits the length of a single tag name
+const MaxTagLength = 64
+
+// GroupFilter narrows group listings; zero value matches every group
+type GroupFilter = repo.GroupFilter
+
+// TagService manages the tags and notes a workspace attaches to its groups
+type TagService struct {
+	db *gorm.DB
+}
+
+// TagUsage represents a tag with the number of groups carrying it
+type TagUsage struct {
+	Name       string `json:"name"`
+	GroupCount int    `json:"groups"`
+}
+
+// BulkTagInput adds and removes tags on several groups at once
+type BulkTagInput struct {
+	GroupIDs []uint   `json:"group_ids"`
+	Add      []string `json:"add"`
+	Remove   []string `json:"remove"`
+}
+
+func NewTagService(db *gorm.DB) *TagService {
+	return &TagService{db: db}
+}
+
+// NormalizeTag trims and lower-cases a tag name
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTags normalizes, validates and de-duplicates tag names
+func normalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	var out []string
+	for _, name := range names {
+		tag := NormalizeTag(name)
+		if tag == "" {
+			continue
+		}
+		if len([]rune(tag)) > MaxTagLength {
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidTag, tag, MaxTagLength)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// ListTags returns the tags of a workspace with their usage counts, most used first
+func (ts *TagService) ListTags(workspaceID uint) ([]TagUsage, error) {
+	var usage []TagUsage
+	err := ts.db.Table("tags").
+		Select("tags.name AS name, COUNT(group_tags.group_id) AS group_count").
+		Joins("LEFT JOIN group_tags ON group_tags.tag_id = tags.id").
+		Where("tags.workspace_id = ?", workspaceID).
+		Group("tags.name").
+		Order("group_count DESC, tags.name").
+		Scan(&usage).Error
+	return usage, err
+}
+
+// SetGroupTags replaces the workspace's tags of a group; tags other
+// workspaces put on the same group are left alone
+func (ts *TagService) SetGroupTags(workspaceID, groupID uint, names []string) ([]string, error) {
+	tags, err := normalizeTags(names)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ts.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findTrackedGroup(tx, workspaceID, groupID); err != nil {
+			return err
+		}
+		records, err := ensureTags(tx, workspaceID, tags)
+		if err != nil {
+			return err
+		}
+		own := tx.Model(&models.Tag{}).Select("id").Where("workspace_id = ?", workspaceID)
+		if err := tx.Exec("DELETE FROM group_tags WHERE group_id = ? AND tag_id IN (?)", groupID, own).Error; err != nil {
+			return err
+		}
+		return linkTags(tx, []uint{groupID}, records)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// SetGroupNotes replaces the workspace's free-form notes on a group
+func (ts *TagService) SetGroupNotes(workspaceID, groupID uint, notes string) error {
+	result := ts.db.Model(&models.WorkspaceGroup{}).
+		Where("workspace_id = ? AND group_id = ?", workspaceID, groupID).
+		Update("notes", strings.TrimSpace(notes))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrGroupNotFound
+	}
+	return nil
+}
+
+// BulkTag adds and removes a workspace's tags on every listed group
+func (ts *TagService) BulkTag(workspaceID uint, input BulkTagInput) error {
+	add, err := normalizeTags(input.Add)
+	if err != nil {
+		return err
+	}
+	remove, err := normalizeTags(input.Remove)
+	if err != nil {
+		return err
+	}
+	ids := uniqueIDs(input.GroupIDs)
+	if len(ids) == 0 {
+		return fmt.Errorf("%w: group_ids is required", ErrInvalidTag)
+	}
+
+	return ts.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&models.WorkspaceGroup{}).
+			Where("workspace_id = ? AND group_id IN ?", workspaceID, ids).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if int(count) != len(ids) {
+			return ErrGroupNotFound
+		}
+
+		if len(add) > 0 {
+			records, err := ensureTags(tx, workspaceID, add)
+			if err != nil {
+				return err
+			}
+			if err := linkTags(tx, ids, records); err != nil {
+				return err
+			}
+		}
+
+		if len(remove) > 0 {
+			sub := tx.Model(&models.Tag{}).Select("id").Where("workspace_id = ? AND name IN ?", workspaceID, remove)
+			if err := tx.Exec("DELETE FROM group_tags WHERE group_id IN ? AND tag_id IN (?)", ids, sub).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ensureTags returns a workspace's tag records for the given names, creating missing ones
+func ensureTags(tx *gorm.DB, workspaceID uint, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return []models.Tag{}, nil
+	}
+	records := make([]models.Tag, len(names))
+	for i, name := range names {
+		records[i] = models.Tag{WorkspaceID: workspaceID, Name: name}
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&records).Error; err != nil {
+		return nil, err
+	}
+
+	var tags []models.Tag
+	err := tx.Where("workspace_id = ? AND name IN ?", workspaceID, names).Find(&tags).Error
+	return tags, err
+}
+
+// linkTags puts every tag on every group, skipping links that already exist
+func linkTags(tx *gorm.DB, groupIDs []uint, tags []models.Tag) error {
+	if len(groupIDs) == 0 || len(tags) == 0 {
+		return nil
+	}
+	links := make([]map[string]interface{}, 0, len(groupIDs)*len(tags))
+	for _, id := range groupIDs {
+		for _, tag := range tags {
+			links = append(links, map[string]interface{}{"group_id": id, "tag_id": tag.ID})
+		}
+	}
+	return tx.Table("group_tags").Clauses(clause.OnConflict{DoNothing: true}).Create(links).Error
+}
+
+// findTrackedGroup loads a group on the workspace's list; other groups are reported as not found
+func findTrackedGroup(tx *gorm.DB, workspaceID, id uint) (*models.Group, error) {
+	var group models.Group
+	err := tx.Where("id = ? AND id IN (?)", id, trackedGroupIDs(tx, workspaceID)).First(&group).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+// tagNames flattens tag records into their names
+func tagNames(tags []models.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+)
+
+// TestNormalizeTags tests trimming, lower-casing, de-duplication and sorting of tags
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{" Client X ", "region:msk", "client x", "", "  "})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"client x", "region:msk"}
+	if len(tags) != len(expected) {
+		t.Fatalf("Expected %d tags, got %d (%v)", len(expected), len(tags), tags)
+	}
+	for i := range expected {
+		if tags[i] != expected[i] {
+			t.Errorf("Tag %d: expected '%s', got '%s'", i, expected[i], tags[i])
+		}
+	}
+}
+
+// TestNormalizeTagsTooLong tests rejection of overly long tags
+func TestNormalizeTagsTooLong(t *testing.T) {
+	_, err := normalizeTags([]string{strings.Repeat("я", MaxTagLength+1)})
+	if !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("Expected ErrInvalidTag, got %v", err)
+	}
+
+	if _, err := normalizeTags([]string{strings.Repeat("я", MaxTagLength)}); err != nil {
+		t.Errorf("Expected tag of max length to be accepted, got %v", err)
+	}
+}
+
+// TestTagNames tests flattening tag records into sorted names
+func TestTagNames(t *testing.T) {
+	names := tagNames([]models.Tag{{Name: "niche"}, {Name: "client"}})
+	if len(names) != 2 || names[0] != "client" || names[1] != "niche" {
+		t.Errorf("Expected [client niche], got %v", names)
+	}
+
+	if len(tagNames(nil)) != 0 {
+		t.Error("Expected no names for group without tags")
+	}
+}
+
+// TestTagServiceSQLite tests setting, bulk editing and counting tags
+func TestTagServiceSQLite(t *testing.T) {
+	db := dbtest.New(t)
+	groups := []models.Group{{Domain: "one"}, {Domain: "two"}}
+	if err := db.Create(&groups).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	workspaceID := newTestWorkspace(t, db, groups[0].ID, groups[1].ID)
+	svc := NewTagService(db)
+
+	if _, err := svc.SetGroupTags(workspaceID, groups[0].ID, []string{"Новости", "client"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	err := svc.BulkTag(workspaceID, BulkTagInput{
+		GroupIDs: []uint{groups[0].ID, groups[1].ID},
+		Add:      []string{"новости"},
+		Remove:   []string{"client"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	usage, err := svc.ListTags(workspaceID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(usage) != 2 || usage[0].Name != "новости" || usage[0].GroupCount != 2 || usage[1].GroupCount != 0 {
+		t.Errorf("Unexpected tag usage %+v", usage)
+	}
+
+	if err := svc.BulkTag(workspaceID, BulkTagInput{GroupIDs: []uint{99}, Add: []string{"x"}}); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("Expected ErrGroupNotFound, got %v", err)
+	}
+	if err := svc.SetGroupNotes(workspaceID, 99, "note"); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("Expected ErrGroupNotFound, got %v", err)
+	}
+}
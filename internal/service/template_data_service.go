@@ -9,6 +9,10 @@ type TemplateDataService struct {
 type TemplateGroupData struct {
 	ID                 uint
 	Domain             string
+	Name               string
+	PhotoURL           string
+	Verified           bool
+	Status             string
 	Subscribers        int
 	ParsedAt           string
 	TotalPosts         int
@@ -16,6 +20,8 @@ type TemplateGroupData struct {
 	AvgLikesPerPost    float64
 	MaxLikesPerPost    int
 	AvgCommentsPerPost float64
+	Tags               []string
+	Notes              string
 }
 
 // ChartDataForTemplate represents chart data formatted for template rendering
@@ -30,8 +36,8 @@ func NewTemplateDataService(analyticsService *AnalyticsService) *TemplateDataSer
 }
 
 // PrepareGroupsForTemplate prepares group statistics for template rendering
-func (tds *TemplateDataService) PrepareGroupsForTemplate() ([]TemplateGroupData, error) {
-	stats, err := tds.analyticsService.CalculateGroupStats()
+func (tds *TemplateDataService) PrepareGroupsForTemplate(filter GroupFilter) ([]TemplateGroupData, error) {
+	stats, err := tds.analyticsService.CalculateGroupStats(filter)
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +47,10 @@ func (tds *TemplateDataService) PrepareGroupsForTemplate() ([]TemplateGroupData,
 		templateData[i] = TemplateGroupData{
 			ID:                 stat.ID,
 			Domain:             stat.Domain,
+			Name:               stat.Name,
+			PhotoURL:           stat.PhotoURL,
+			Verified:           stat.Verified,
+			Status:             stat.Status,
 			Subscribers:        stat.Subscribers,
 			ParsedAt:           stat.ParsedAt,
 			TotalPosts:         stat.TotalPosts,
@@ -48,6 +58,8 @@ func (tds *TemplateDataService) PrepareGroupsForTemplate() ([]TemplateGroupData,
 			AvgLikesPerPost:    stat.AvgLikesPerPost,
 			MaxLikesPerPost:    stat.MaxLikesPerPost,
 			AvgCommentsPerPost: stat.AvgCommentsPerPost,
+			Tags:               stat.Tags,
+			Notes:              stat.Notes,
 		}
 	}
 
@@ -55,8 +67,8 @@ func (tds *TemplateDataService) PrepareGroupsForTemplate() ([]TemplateGroupData,
 }
 
 // PrepareChartDataForTemplate prepares chart data for template rendering
-func (tds *TemplateDataService) PrepareChartDataForTemplate() (ChartDataForTemplate, error) {
-	chartData, err := tds.analyticsService.CalculateChartData()
+func (tds *TemplateDataService) PrepareChartDataForTemplate(filter GroupFilter) (ChartDataForTemplate, error) {
+	chartData, err := tds.analyticsService.CalculateChartData(filter)
 	if err != nil {
 		return ChartDataForTemplate{}, err
 	}
@@ -83,14 +83,10 @@ func TestPrepareGroupsForTemplate(t *testing.T) {
 				shouldError: tt.shouldError,
 			}
 
-			service := NewTemplateDataService(&AnalyticsService{
-				db: &gorm.DB{},
-			})
+			service := NewTemplateDataService(newMemoryAnalyticsService(nil, nil))
 
 			// Override with mock for testing
-			service.analyticsService = &AnalyticsService{
-				db: &gorm.DB{},
-			}
+			service.analyticsService = newMemoryAnalyticsService(nil, nil)
 
 			// Validate the structure instead
 			templateData := make([]TemplateGroupData, len(tt.mockStats))
@@ -202,9 +198,7 @@ func TestChartDataForTemplateStructure(t *testing.T) {
 
 // TestTemplateDataServiceInitialization tests service initialization
 func TestTemplateDataServiceInitialization(t *testing.T) {
-	analyticsService := &AnalyticsService{
-		db: &gorm.DB{},
-	}
+	analyticsService := newMemoryAnalyticsService(nil, nil)
 
 	service := NewTemplateDataService(analyticsService)
 
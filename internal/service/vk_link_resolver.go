@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidLink  = errors.New("invalid group link")
+	ErrNotCommunity = errors.New("link points to a personal page, not a community")
+)
+
+// VK object types returned by utils.resolveScreenName
+const (
+	VKObjectGroup       = "group"
+	VKObjectPage        = "page"
+	VKObjectEvent       = "event"
+	VKObjectUser        = "user"
+	VKObjectApplication = "application"
+)
+
+// vkHosts are the hosts VK links are accepted from, without www./m. prefixes
+var vkHosts = map[string]bool{
+	"vk.com":       true,
+	"vk.ru":        true,
+	"vkontakte.ru": true,
+}
+
+var (
+	mentionPattern  = regexp.MustCompile(`^\[([a-zA-Z0-9_.]+)\|[^\]]*\]$`)
+	wallRefPattern  = regexp.MustCompile(`^wall(-?)(\d+)_\d+$`)
+	groupRefPattern = regexp.MustCompile(`^(club|public|event)(\d+)$`)
+	userRefPattern  = regexp.MustCompile(`^id(\d+)$`)
+	numericPattern  = regexp.MustCompile(`^-?(\d+)$`)
+)
+
+// ResolveBatchSize is the number of screen names resolved per execute call,
+// the limit of API calls in one execute
+const ResolveBatchSize = 25
+
+// resolveScript resolves a JSON array of screen names in one execute call
+const resolveScript = `var names = %s;
+var out = [];
+var i = 0;
+while (i < names.length) {
+  out.push(API.utils.resolveScreenName({"screen_name": names[i]}));
+  i = i + 1;
+}
+return out;`
+
+// LinkRef is a VK object reference extracted from a link without calling the API
+type LinkRef struct {
+	// ScreenName is the canonical name to resolve, e.g. "mybrand", "club123" or "id1"
+	ScreenName string
+	// User is set when the link is known to point to a personal page
+	User bool
+}
+
+// ResolvedObject is a VK object classified by utils.resolveScreenName
+type ResolvedObject struct {
+	Type       string
+	ObjectID   int
+	ScreenName string
+	// OwnerID is the owner ID used by wall methods: negative for communities
+	OwnerID int
+}
+
+type VKResolveResponse struct {
+	Response json.RawMessage `json:"response"`
+	Error    struct {
+		ErrorCode int    `json:"error_code"`
+		ErrorMsg  string `json:"error_msg"`
+	} `json:"error"`
+}
+
+// VKExecuteResponse holds one result per API call made by an execute script
+type VKExecuteResponse struct {
+	Response []json.RawMessage `json:"response"`
+	Error    struct {
+		ErrorCode int    `json:"error_code"`
+		ErrorMsg  string `json:"error_msg"`
+	} `json:"error"`
+}
+
+// IsCommunity reports whether the object is a group, public page or event
+func (o *ResolvedObject) IsCommunity() bool {
+	return o.Type == VKObjectGroup || o.Type == VKObjectPage || o.Type == VKObjectEvent
+}
+
+// ParseVKLink normalises the many spellings of a VK link: full and mobile
+// URLs on vk.com/vk.ru, wall post links, club/public/event IDs, @mentions,
+// [club1|Name] markup and bare screen names or numeric IDs.
+// Bare numbers are treated as community IDs.
+func ParseVKLink(link string) (LinkRef, error) {
+	s := strings.TrimSpace(link)
+	if m := mentionPattern.FindStringSubmatch(s); m != nil {
+		s = m[1]
+	}
+	s = strings.TrimPrefix(s, "@")
+	if s == "" {
+		return LinkRef{}, ErrInvalidLink
+	}
+
+	if !strings.Contains(s, "://") {
+		first := strings.FieldsFunc(s, func(r rune) bool { return r == '/' || r == '?' || r == '#' })
+		if len(first) > 0 && strings.Contains(first[0], ".") {
+			s = "https://" + s
+		} else {
+			s = "https://vk.com/" + s
+		}
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return LinkRef{}, ErrInvalidLink
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, prefix := range []string{"www.", "m.", "new."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	if !vkHosts[host] {
+		return LinkRef{}, ErrInvalidLink
+	}
+
+	segment := ""
+	for _, part := range strings.Split(u.Path, "/") {
+		if part != "" {
+			segment = part
+			break
+		}
+	}
+	// vk.com/?w=wall-1_2 opens a post on top of the feed
+	if segment == "" {
+		segment = u.Query().Get("w")
+	}
+	segment = strings.TrimPrefix(segment, "@")
+
+	return classifySegment(segment)
+}
+
+// classifySegment turns the first path segment of a link into a LinkRef
+func classifySegment(segment string) (LinkRef, error) {
+	lower := strings.ToLower(segment)
+
+	if m := wallRefPattern.FindStringSubmatch(lower); m != nil {
+		if m[1] == "-" {
+			return LinkRef{ScreenName: "club" + m[2]}, nil
+		}
+		return LinkRef{ScreenName: "id" + m[2], User: true}, nil
+	}
+	if m := groupRefPattern.FindStringSubmatch(lower); m != nil {
+		return LinkRef{ScreenName: m[1] + m[2]}, nil
+	}
+	if m := userRefPattern.FindStringSubmatch(lower); m != nil {
+		return LinkRef{ScreenName: "id" + m[1], User: true}, nil
+	}
+	if m := numericPattern.FindStringSubmatch(lower); m != nil {
+		return LinkRef{ScreenName: "club" + m[1]}, nil
+	}
+	if segment != "" && IsValidScreenName(segment) {
+		return LinkRef{ScreenName: segment}, nil
+	}
+	return LinkRef{}, ErrInvalidLink
+}
+
+// ResolveLink parses a link and classifies its target with utils.resolveScreenName.
+// Personal pages and applications are rejected with ErrNotCommunity.
+func (s *VKService) ResolveLink(ctx context.Context, link string) (*ResolvedObject, error) {
+	ref, err := ParseVKLink(link)
+	if err != nil {
+		return nil, err
+	}
+	if ref.User {
+		return nil, ErrNotCommunity
+	}
+
+	object, err := s.ResolveScreenName(ctx, ref.ScreenName)
+	if err != nil {
+		return nil, err
+	}
+	if !object.IsCommunity() {
+		return nil, ErrNotCommunity
+	}
+	return object, nil
+}
+
+// ResolveScreenName calls utils.resolveScreenName; unknown names yield ErrGroupNotFound
+func (s *VKService) ResolveScreenName(ctx context.Context, screenName string) (*ResolvedObject, error) {
+	if s.accessToken == "" {
+		return nil, fmt.Errorf("VK access token not configured")
+	}
+
+	resp, err := s.get(ctx, s.methodURL("utils.resolveScreenName", url.Values{
+		"screen_name": {screenName},
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve screen name: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var vkResp VKResolveResponse
+	if err := json.NewDecoder(resp.Body).Decode(&vkResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if vkResp.Error.ErrorCode != 0 {
+		return nil, &VKAPIError{Code: vkResp.Error.ErrorCode, Message: vkResp.Error.ErrorMsg}
+	}
+
+	object, ok := resolvedObject(vkResp.Response, screenName)
+	if !ok {
+		return nil, ErrGroupNotFound
+	}
+	return object, nil
+}
+
+// ResolveScreenNames classifies many screen names with utils.resolveScreenName,
+// ResolveBatchSize per execute call. The result is keyed by the lower-cased
+// screen name as passed in; names VK doesn't know are missing from the map.
+func (s *VKService) ResolveScreenNames(ctx context.Context, screenNames []string) (map[string]*ResolvedObject, error) {
+	if s.accessToken == "" {
+		return nil, fmt.Errorf("VK access token not configured")
+	}
+
+	found := make(map[string]*ResolvedObject, len(screenNames))
+	for start := 0; start < len(screenNames); start += ResolveBatchSize {
+		end := start + ResolveBatchSize
+		if end > len(screenNames) {
+			end = len(screenNames)
+		}
+		batch := screenNames[start:end]
+
+		// Screen names only hold characters that are safe in a JSON string
+		names, err := json.Marshal(batch)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.get(ctx, s.methodURL("execute", url.Values{
+			"code": {fmt.Sprintf(resolveScript, names)},
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve screen names: %w", err)
+		}
+
+		var vkResp VKExecuteResponse
+		err = json.NewDecoder(resp.Body).Decode(&vkResp)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if vkResp.Error.ErrorCode != 0 {
+			return nil, &VKAPIError{Code: vkResp.Error.ErrorCode, Message: vkResp.Error.ErrorMsg}
+		}
+
+		// A call that failed inside execute comes back as false and is left unresolved
+		for i, raw := range vkResp.Response {
+			if i >= len(batch) {
+				break
+			}
+			if object, ok := resolvedObject(raw, batch[i]); ok {
+				found[strings.ToLower(batch[i])] = object
+			}
+		}
+	}
+	return found, nil
+}
+
+// resolvedObject decodes one utils.resolveScreenName response. Unknown names
+// come back as an empty array instead of an object.
+func resolvedObject(raw json.RawMessage, screenName string) (*ResolvedObject, bool) {
+	var result struct {
+		Type     string `json:"type"`
+		ObjectID int    `json:"object_id"`
+	}
+	if len(raw) == 0 || raw[0] != '{' || json.Unmarshal(raw, &result) != nil || result.ObjectID == 0 {
+		return nil, false
+	}
+
+	object := &ResolvedObject{
+		Type:       result.Type,
+		ObjectID:   result.ObjectID,
+		ScreenName: screenName,
+		OwnerID:    result.ObjectID,
+	}
+	if object.IsCommunity() {
+		object.OwnerID = -result.ObjectID
+	}
+	return object, true
+}
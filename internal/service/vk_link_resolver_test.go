@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestParseVKLink tests normalisation of the supported link spellings
+func TestParseVKLink(t *testing.T) {
+	tests := []struct {
+		name       string
+		link       string
+		screenName string
+		user       bool
+	}{
+		{"Full URL", "https://vk.com/mybrand", "mybrand", false},
+		{"No scheme", "vk.com/mybrand", "mybrand", false},
+		{"WWW", "http://www.vk.com/mybrand/", "mybrand", false},
+		{"Mobile", "https://m.vk.com/mybrand", "mybrand", false},
+		{"vk.ru", "https://vk.ru/mybrand", "mybrand", false},
+		{"Club ID with query", "https://vk.com/club123?w=wall-123_456", "club123", false},
+		{"Public ID", "vk.com/public42", "public42", false},
+		{"Event ID", "vk.com/event7", "event7", false},
+		{"Group wall post", "https://vk.com/wall-123_456", "club123", false},
+		{"Post overlay", "https://vk.com/?w=wall-123_456", "club123", false},
+		{"Mention", "@mybrand", "mybrand", false},
+		{"Mention markup", "[club123|My Brand]", "club123", false},
+		{"Bare screen name", "mybrand", "mybrand", false},
+		{"Dotted screen name", "https://vk.com/ivan.shop", "ivan.shop", false},
+		{"Bare numeric ID", "123", "club123", false},
+		{"Negative numeric ID", "-123", "club123", false},
+		{"Personal page", "https://vk.com/id1", "id1", true},
+		{"Personal wall post", "vk.com/wall1_2", "id1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseVKLink(tt.link)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if ref.ScreenName != tt.screenName {
+				t.Errorf("Expected screen name '%s', got '%s'", tt.screenName, ref.ScreenName)
+			}
+			if ref.User != tt.user {
+				t.Errorf("Expected user %v, got %v", tt.user, ref.User)
+			}
+		})
+	}
+}
+
+// TestParseVKLinkInvalid tests rejection of links that are not VK objects
+func TestParseVKLinkInvalid(t *testing.T) {
+	links := []string{"", "   ", "https://vk.com/", "https://example.com/mybrand", "vk.com/bad name", "@"}
+
+	for _, link := range links {
+		if _, err := ParseVKLink(link); !errors.Is(err, ErrInvalidLink) {
+			t.Errorf("%q: expected ErrInvalidLink, got %v", link, err)
+		}
+	}
+}
+
+// TestResolveLink tests classification via utils.resolveScreenName
+func TestResolveLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("screen_name") {
+		case "mybrand", "club123":
+			w.Write([]byte(`{"response":{"type":"group","object_id":123}}`))
+		case "durov":
+			w.Write([]byte(`{"response":{"type":"user","object_id":1}}`))
+		default:
+			w.Write([]byte(`{"response":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	svc := &VKService{accessToken: "token", apiVersion: "5.131", apiURL: server.URL, httpClient: server.Client()}
+
+	for _, link := range []string{"https://vk.com/mybrand", "vk.com/wall-123_1"} {
+		object, err := svc.ResolveLink(context.Background(), link)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", link, err)
+		}
+		if object.OwnerID != -123 {
+			t.Errorf("%s: expected owner ID -123, got %d", link, object.OwnerID)
+		}
+	}
+
+	if _, err := svc.ResolveLink(context.Background(), "vk.com/durov"); !errors.Is(err, ErrNotCommunity) {
+		t.Errorf("Expected ErrNotCommunity for a user, got %v", err)
+	}
+	if _, err := svc.ResolveLink(context.Background(), "vk.com/id1"); !errors.Is(err, ErrNotCommunity) {
+		t.Errorf("Expected ErrNotCommunity for an id link, got %v", err)
+	}
+	if _, err := svc.ResolveLink(context.Background(), "vk.com/nosuchgroup"); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("Expected ErrGroupNotFound, got %v", err)
+	}
+}
@@ -1,27 +1,109 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"social-media-analyzer/internal/config"
 	"social-media-analyzer/internal/models"
 )
 
+// vkAPIURL is the base URL of VK API methods
+const vkAPIURL = "https://api.vk.com/method"
+
 type VKService struct {
 	accessToken string
 	apiVersion  string
+	apiURL      string
 	httpClient  *http.Client
 }
 
 type VKGroupInfo struct {
-	ID      int    `json:"id"`
-	Name    string `json:"name"`
-	Domain  string `json:"screen_name"`
-	Members int    `json:"members_count"`
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Domain   string `json:"screen_name"`
+	Members  int    `json:"members_count"`
+	Photo    string `json:"photo_200"`
+	Type     string `json:"type"`
+	Verified int    `json:"verified"`
+	IsClosed int    `json:"is_closed"`
+	// Deactivated is "deleted" or "banned" for communities that are gone
+	Deactivated string `json:"deactivated"`
+}
+
+// VKAPIError is an error returned by a VK API method
+type VKAPIError struct {
+	Code    int
+	Message string
+}
+
+func (e *VKAPIError) Error() string {
+	return fmt.Sprintf("VK API error: %s", e.Message)
+}
+
+// VK API error codes that describe the state of a community's wall
+const (
+	vkErrAccessDenied   = 15
+	vkErrPageDeleted    = 18
+	vkErrPrivateProfile = 30
+	vkErrGroupAccess    = 203
+)
+
+// groupInfoFields are the extra fields requested from groups.getById
+const groupInfoFields = "members_count,verified"
+
+// toGroup converts VK group info into a group model
+func (info VKGroupInfo) toGroup() *models.Group {
+	vkID := info.ID
+	return &models.Group{
+		VKID:        &vkID,
+		Domain:      info.Domain,
+		Name:        info.Name,
+		PhotoURL:    info.Photo,
+		Type:        info.Type,
+		Verified:    info.Verified == 1,
+		IsClosed:    info.IsClosed,
+		Status:      info.status(),
+		Subscribers: info.Members,
+	}
+}
+
+// status derives the group status visible from groups.getById
+func (info VKGroupInfo) status() string {
+	switch {
+	case info.Deactivated == "banned":
+		return models.GroupStatusBanned
+	case info.Deactivated != "":
+		return models.GroupStatusDeleted
+	case info.IsClosed != 0:
+		return models.GroupStatusClosed
+	default:
+		return models.GroupStatusActive
+	}
+}
+
+// WallErrorStatus maps a wall.get error to the group status it reveals
+func WallErrorStatus(err error) (string, bool) {
+	var apiErr *VKAPIError
+	if !errors.As(err, &apiErr) {
+		return "", false
+	}
+	switch apiErr.Code {
+	case vkErrAccessDenied:
+		return models.GroupStatusWallDisabled, true
+	case vkErrPageDeleted:
+		return models.GroupStatusDeleted, true
+	case vkErrPrivateProfile, vkErrGroupAccess:
+		return models.GroupStatusClosed, true
+	}
+	return "", false
 }
 
 type VKGroupResponse struct {
@@ -37,6 +119,7 @@ type VKWallPost struct {
 	OwnerID  int    `json:"owner_id"`
 	Date     int    `json:"date"`
 	Text     string `json:"text"`
+	IsPinned int    `json:"is_pinned"` // wall.get returns the pinned post first, whatever its date
 	Likes    struct {
 		Count int `json:"count"`
 	} `json:"likes"`
@@ -62,58 +145,61 @@ type VKWallResponse struct {
 	} `json:"error"`
 }
 
+// GroupsBatchSize is the number of groups requested per groups.getById call
+const GroupsBatchSize = 100
+
+// screenNamePattern matches screen names (alphanumeric, dash, underscore, dot, or club/public/event IDs)
+var screenNamePattern = regexp.MustCompile(`^([a-zA-Z0-9_.-]+|club\d+|public\d+|event\d+)$`)
+
 func NewVKService(cfg *config.VKConfig) *VKService {
 	return &VKService{
 		accessToken: cfg.AccessToken,
 		apiVersion:  cfg.APIVersion,
+		apiURL:      vkAPIURL,
 		httpClient:  &http.Client{},
 	}
 }
 
-// ExtractGroupScreenName extracts group screen_name from various VK link formats
-// Supports formats: https://vk.com/groupname, vk.com/groupname, https://vk.com/club123456
-func (s *VKService) ExtractGroupScreenName(link string) (string, error) {
-	// Remove protocol and www if present
-	link = strings.TrimPrefix(link, "https://")
-	link = strings.TrimPrefix(link, "http://")
-	link = strings.TrimPrefix(link, "www.")
-
-	// Extract path part (after vk.com/)
-	parts := strings.Split(link, "/")
-	var screenName string
+// methodURL builds the request URL of a VK API method
+func (s *VKService) methodURL(method string, params url.Values) string {
+	params.Set("v", s.apiVersion)
+	params.Set("access_token", s.accessToken)
+	return s.apiURL + "/" + method + "?" + params.Encode()
+}
 
-	for _, part := range parts {
-		if strings.Contains(part, "vk.com") {
-			continue
-		}
-		if part != "" {
-			screenName = part
-			break
-		}
+// get performs a GET request that is aborted when ctx is cancelled
+func (s *VKService) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
 	}
+	return s.httpClient.Do(req)
+}
 
-	if screenName == "" {
-		return "", fmt.Errorf("could not extract group name from link")
+// ExtractGroupScreenName extracts a resolvable group reference from various VK link formats
+// (see ParseVKLink). Links to personal pages are rejected with ErrNotCommunity.
+func (s *VKService) ExtractGroupScreenName(link string) (string, error) {
+	ref, err := ParseVKLink(link)
+	if err != nil {
+		return "", err
 	}
-
-	// If it's a club ID (starts with "club" or "-"), keep it as is
-	// Otherwise, it's a screen name
-	return screenName, nil
+	if ref.User {
+		return "", ErrNotCommunity
+	}
+	return ref.ScreenName, nil
 }
 
 // GetGroupInfo fetches group information from VK API
-func (s *VKService) GetGroupInfo(screenName string) (*models.Group, error) {
+func (s *VKService) GetGroupInfo(ctx context.Context, screenName string) (*models.Group, error) {
 	if s.accessToken == "" {
 		return nil, fmt.Errorf("VK access token not configured")
 	}
 
 	// Build API request URL with members_count field
-	url := fmt.Sprintf(
-		"https://api.vk.com/method/groups.getById?group_ids=%s&fields=members_count&v=%s&access_token=%s",
-		screenName, s.apiVersion, s.accessToken,
-	)
-
-	resp, err := s.httpClient.Get(url)
+	resp, err := s.get(ctx, s.methodURL("groups.getById", url.Values{
+		"group_ids": {screenName},
+		"fields":    {groupInfoFields},
+	}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch group info: %w", err)
 	}
@@ -125,74 +211,138 @@ func (s *VKService) GetGroupInfo(screenName string) (*models.Group, error) {
 	}
 
 	if vkResp.Error.ErrorCode != 0 {
-		return nil, fmt.Errorf("VK API error: %s", vkResp.Error.ErrorMsg)
+		return nil, &VKAPIError{Code: vkResp.Error.ErrorCode, Message: vkResp.Error.ErrorMsg}
 	}
 
 	if len(vkResp.Response) == 0 {
-		return nil, fmt.Errorf("group not found")
+		return nil, ErrGroupNotFound
 	}
 
-	groupInfo := vkResp.Response[0]
+	return vkResp.Response[0].toGroup(), nil
+}
 
-	group := &models.Group{
-		Domain:      groupInfo.Domain,
-		Subscribers: groupInfo.Members,
+// GetGroupsInfo fetches information about several groups, GroupsBatchSize per request.
+// The result is keyed by the lower-cased screen name as passed in; groups VK
+// doesn't know are missing from the map.
+func (s *VKService) GetGroupsInfo(ctx context.Context, screenNames []string) (map[string]*models.Group, error) {
+	if s.accessToken == "" {
+		return nil, fmt.Errorf("VK access token not configured")
 	}
 
-	return group, nil
+	found := make(map[string]*models.Group, len(screenNames))
+	for start := 0; start < len(screenNames); start += GroupsBatchSize {
+		end := start + GroupsBatchSize
+		if end > len(screenNames) {
+			end = len(screenNames)
+		}
+		batch := screenNames[start:end]
+
+		resp, err := s.get(ctx, s.methodURL("groups.getById", url.Values{
+			"group_ids": {strings.Join(batch, ",")},
+			"fields":    {groupInfoFields},
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch groups info: %w", err)
+		}
+
+		var vkResp VKGroupResponse
+		err = json.NewDecoder(resp.Body).Decode(&vkResp)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		// Error 100 means none of the requested groups exist
+		if vkResp.Error.ErrorCode == 100 {
+			continue
+		}
+		if vkResp.Error.ErrorCode != 0 {
+			return nil, &VKAPIError{Code: vkResp.Error.ErrorCode, Message: vkResp.Error.ErrorMsg}
+		}
+
+		matchGroups(batch, vkResp.Response, found)
+	}
+
+	return found, nil
 }
 
-// GetWallPosts fetches posts from group wall
-func (s *VKService) GetWallPosts(screenName string, count int) ([]VKWallPost, error) {
+// matchGroups maps requested screen names to the groups VK returned for them
+func matchGroups(requested []string, infos []VKGroupInfo, found map[string]*models.Group) {
+	byKey := make(map[string]VKGroupInfo, len(infos)*4)
+	for _, info := range infos {
+		byKey[strings.ToLower(info.Domain)] = info
+		for _, alias := range []string{"club%d", "public%d", "event%d", "%d", "-%d"} {
+			byKey[fmt.Sprintf(alias, info.ID)] = info
+		}
+	}
+
+	for _, name := range requested {
+		key := strings.ToLower(name)
+		if info, ok := byKey[key]; ok {
+			found[key] = info.toGroup()
+		}
+	}
+}
+
+// IsValidScreenName reports whether s looks like a VK screen name or numeric group ID
+func IsValidScreenName(s string) bool {
+	return screenNamePattern.MatchString(s)
+}
+
+// GetWallPosts fetches the latest posts from group wall together with the
+// total number of posts on it. Groups with a known VK ID are addressed by
+// owner_id so that renames don't break parsing.
+func (s *VKService) GetWallPosts(ctx context.Context, group *models.Group, count int) ([]VKWallPost, int, error) {
 	if s.accessToken == "" {
-		return nil, fmt.Errorf("VK access token not configured")
+		return nil, 0, fmt.Errorf("VK access token not configured")
 	}
 
 	if count <= 0 || count > 100 {
 		count = 100
 	}
 
-	// Build API request URL
 	// wall.get method with owner_id (negative for groups)
-	url := fmt.Sprintf(
-		"https://api.vk.com/method/wall.get?domain=%s&count=%d&v=%s&access_token=%s",
-		screenName, count, s.apiVersion, s.accessToken,
-	)
+	params := url.Values{"count": {strconv.Itoa(count)}}
+	if group.VKID != nil {
+		params.Set("owner_id", strconv.Itoa(-*group.VKID))
+	} else {
+		params.Set("domain", group.Domain)
+	}
 
-	resp, err := s.httpClient.Get(url)
+	resp, err := s.get(ctx, s.methodURL("wall.get", params))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch wall posts: %w", err)
+		return nil, 0, fmt.Errorf("failed to fetch wall posts: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var vkResp VKWallResponse
 	if err := json.NewDecoder(resp.Body).Decode(&vkResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, 0, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if vkResp.Error.ErrorCode != 0 {
-		return nil, fmt.Errorf("VK API error: %s", vkResp.Error.ErrorMsg)
+		return nil, 0, &VKAPIError{Code: vkResp.Error.ErrorCode, Message: vkResp.Error.ErrorMsg}
 	}
 
-	return vkResp.Response.Items, nil
+	return vkResp.Response.Items, vkResp.Response.Count, nil
 }
 
-// ParseGroupFromLink parses group link and fetches info from VK API
-func (s *VKService) ParseGroupFromLink(link string) (*models.Group, error) {
-	screenName, err := s.ExtractGroupScreenName(link)
-	if err != nil {
-		return nil, fmt.Errorf("invalid group link: %w", err)
+// RefreshGroupInfo fetches current metadata of a stored group, by VK ID when known
+func (s *VKService) RefreshGroupInfo(ctx context.Context, group *models.Group) (*models.Group, error) {
+	if group.VKID != nil {
+		return s.GetGroupInfo(ctx, strconv.Itoa(*group.VKID))
 	}
+	return s.GetGroupInfo(ctx, group.Domain)
+}
 
-	fmt.Println(screenName)
-
-	// Validate screen name (alphanumeric, dash, underscore, or starts with club/public)
-	validPattern := regexp.MustCompile(`^([a-zA-Z0-9_-]+|club\d+|public\d+|-\d+)$`)
-	if !validPattern.MatchString(screenName) {
-		return nil, fmt.Errorf("invalid screen name format")
+// ParseGroupFromLink resolves a group link to its community and fetches info from VK API
+func (s *VKService) ParseGroupFromLink(ctx context.Context, link string) (*models.Group, error) {
+	object, err := s.ResolveLink(ctx, link)
+	if err != nil {
+		return nil, err
 	}
 
-	group, err := s.GetGroupInfo(screenName)
+	group, err := s.GetGroupInfo(ctx, strconv.Itoa(object.ObjectID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get group info: %w", err)
 	}
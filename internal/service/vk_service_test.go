@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"social-media-analyzer/internal/models"
+)
+
+// TestGroupInfoStatus tests deriving a group status from groups.getById fields
+func TestGroupInfoStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     VKGroupInfo
+		expected string
+	}{
+		{"Open", VKGroupInfo{}, models.GroupStatusActive},
+		{"Closed", VKGroupInfo{IsClosed: 1}, models.GroupStatusClosed},
+		{"Private", VKGroupInfo{IsClosed: 2}, models.GroupStatusClosed},
+		{"Banned", VKGroupInfo{Deactivated: "banned", IsClosed: 1}, models.GroupStatusBanned},
+		{"Deleted", VKGroupInfo{Deactivated: "deleted"}, models.GroupStatusDeleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := tt.info.toGroup().Status; status != tt.expected {
+				t.Errorf("Expected status '%s', got '%s'", tt.expected, status)
+			}
+		})
+	}
+}
+
+// TestWallErrorStatus tests mapping wall.get errors to group statuses
+func TestWallErrorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+		ok       bool
+	}{
+		{"Wall disabled", &VKAPIError{Code: 15, Message: "Access denied"}, models.GroupStatusWallDisabled, true},
+		{"Deleted", &VKAPIError{Code: 18, Message: "Page deleted"}, models.GroupStatusDeleted, true},
+		{"Private", &VKAPIError{Code: 30, Message: "Private"}, models.GroupStatusClosed, true},
+		{"Wrapped", fmt.Errorf("sync: %w", &VKAPIError{Code: 203}), models.GroupStatusClosed, true},
+		{"Rate limit", &VKAPIError{Code: 6, Message: "Too many requests"}, "", false},
+		{"Network", fmt.Errorf("failed to fetch wall posts"), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, ok := WallErrorStatus(tt.err)
+			if status != tt.expected || ok != tt.ok {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.expected, tt.ok, status, ok)
+			}
+		})
+	}
+}
+
+// TestGroupInfoAPIError tests that groups.getById errors keep their VK error code
+func TestGroupInfoAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"error":{"error_code":6,"error_msg":"Too many requests per second"}}`)
+	}))
+	defer server.Close()
+	svc := &VKService{accessToken: "token", apiVersion: "5.131", apiURL: server.URL, httpClient: server.Client()}
+
+	var apiErr *VKAPIError
+	if _, err := svc.GetGroupInfo(context.Background(), "mybrand"); !errors.As(err, &apiErr) || apiErr.Code != 6 {
+		t.Errorf("Expected a VK API error with code 6 from GetGroupInfo, got %v", err)
+	}
+	if _, err := svc.GetGroupsInfo(context.Background(), []string{"mybrand"}); !errors.As(err, &apiErr) || apiErr.Code != 6 {
+		t.Errorf("Expected a VK API error with code 6 from GetGroupsInfo, got %v", err)
+	}
+}
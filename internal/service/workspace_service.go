@@ -0,0 +1,292 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"social-media-analyzer/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrWorkspaceInvalid  = errors.New("invalid workspace")
+	ErrNoWorkspace       = errors.New("user is not a member of any workspace")
+	ErrMemberNotFound    = errors.New("workspace member not found")
+	ErrMemberExists      = errors.New("user is already a member of this workspace")
+	ErrLastOwner         = errors.New("workspace must keep at least one owner")
+)
+
+// WorkspaceService manages workspaces, their members and the groups they track
+type WorkspaceService struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceService(db *gorm.DB) *WorkspaceService {
+	return &WorkspaceService{db: db}
+}
+
+// Create adds a workspace owned by the given user
+func (ws *WorkspaceService) Create(userID uint, name string) (*models.Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return nil, fmt.Errorf("%w: name must be 1-100 characters", ErrWorkspaceInvalid)
+	}
+
+	workspace := models.Workspace{Name: name}
+	err := ws.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&workspace).Error; err != nil {
+			return err
+		}
+		member := models.WorkspaceMember{WorkspaceID: workspace.ID, UserID: userID, Role: models.WorkspaceRoleOwner}
+		return tx.Omit(clause.Associations).Create(&member).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// ListForUser returns the memberships of a user with their workspaces, ordered by name
+func (ws *WorkspaceService) ListForUser(userID uint) ([]models.WorkspaceMember, error) {
+	var memberships []models.WorkspaceMember
+	err := ws.db.Preload("Workspace").
+		Joins("JOIN workspaces ON workspaces.id = workspace_members.workspace_id").
+		Where("workspace_members.user_id = ?", userID).
+		Order("workspaces.name, workspaces.id").
+		Find(&memberships).Error
+	return memberships, err
+}
+
+// Membership returns a user's membership in a workspace; workspaces the user
+// does not belong to are reported as not found
+func (ws *WorkspaceService) Membership(workspaceID, userID uint) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	err := ws.db.Preload("Workspace").
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// Current returns the workspace a session works in: the one it switched to
+// last or, failing that, the user's first workspace
+func (ws *WorkspaceService) Current(session *models.Session) (*models.WorkspaceMember, error) {
+	if session.WorkspaceID != nil {
+		member, err := ws.Membership(*session.WorkspaceID, session.UserID)
+		if !errors.Is(err, ErrWorkspaceNotFound) {
+			return member, err
+		}
+	}
+
+	memberships, err := ws.ListForUser(session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(memberships) == 0 {
+		return nil, ErrNoWorkspace
+	}
+	return &memberships[0], nil
+}
+
+// Select switches a session to another workspace of its user
+func (ws *WorkspaceService) Select(session *models.Session, workspaceID uint) error {
+	if _, err := ws.Membership(workspaceID, session.UserID); err != nil {
+		return err
+	}
+	session.WorkspaceID = &workspaceID
+	return ws.db.Model(&models.Session{}).Where("token_hash = ?", session.TokenHash).Update("workspace_id", workspaceID).Error
+}
+
+// Members returns the members of a workspace with their users, ordered by username
+func (ws *WorkspaceService) Members(workspaceID uint) ([]models.WorkspaceMember, error) {
+	var members []models.WorkspaceMember
+	err := ws.db.Preload("User").
+		Joins("JOIN users ON users.id = workspace_members.user_id").
+		Where("workspace_members.workspace_id = ?", workspaceID).
+		Order("users.username").
+		Find(&members).Error
+	return members, err
+}
+
+// AddMember gives an existing user access to a workspace
+func (ws *WorkspaceService) AddMember(workspaceID uint, username, role string) (*models.WorkspaceMember, error) {
+	if !models.ValidWorkspaceRole(role) {
+		return nil, invalidRoleError()
+	}
+	var user models.User
+	if err := ws.db.Where("username = ?", NormalizeUsername(username)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	member := models.WorkspaceMember{WorkspaceID: workspaceID, UserID: user.ID, User: user, Role: role}
+	result := ws.db.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&member)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrMemberExists
+	}
+	return &member, nil
+}
+
+// SetRole changes a member's role; the last owner cannot be demoted
+func (ws *WorkspaceService) SetRole(workspaceID, userID uint, role string) error {
+	if !models.ValidWorkspaceRole(role) {
+		return invalidRoleError()
+	}
+	return ws.db.Transaction(func(tx *gorm.DB) error {
+		member, err := findMember(tx, workspaceID, userID)
+		if err != nil {
+			return err
+		}
+		if member.Role == models.WorkspaceRoleOwner && role != models.WorkspaceRoleOwner {
+			if err := ensureOtherOwner(tx, workspaceID, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.WorkspaceMember{}).
+			Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+			Update("role", role).Error
+	})
+}
+
+// RemoveMember takes a user's access to a workspace away, together with the
+// user's API keys for it; the last owner cannot be removed
+func (ws *WorkspaceService) RemoveMember(workspaceID, userID uint) error {
+	return ws.db.Transaction(func(tx *gorm.DB) error {
+		member, err := findMember(tx, workspaceID, userID)
+		if err != nil {
+			return err
+		}
+		if member.Role == models.WorkspaceRoleOwner {
+			if err := ensureOtherOwner(tx, workspaceID, userID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&models.APIKey{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&models.WorkspaceMember{}).Error
+	})
+}
+
+// Track adds a group to a workspace's list; added is false if it was already there
+func (ws *WorkspaceService) Track(workspaceID, groupID uint) (added bool, err error) {
+	link := models.WorkspaceGroup{WorkspaceID: workspaceID, GroupID: groupID}
+	result := ws.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&link)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Untrack removes a group from a workspace's list together with the
+// workspace's notes and tags on it and its place in the workspace's sets;
+// removed is false if it was not there. The group itself is shared and kept.
+func (ws *WorkspaceService) Untrack(workspaceID, groupID uint) (removed bool, err error) {
+	err = ws.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("workspace_id = ? AND group_id = ?", workspaceID, groupID).Delete(&models.WorkspaceGroup{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		removed = true
+
+		tags := tx.Model(&models.Tag{}).Select("id").Where("workspace_id = ?", workspaceID)
+		if err := tx.Exec("DELETE FROM group_tags WHERE group_id = ? AND tag_id IN (?)", groupID, tags).Error; err != nil {
+			return err
+		}
+		sets := tx.Model(&models.GroupSet{}).Select("id").Where("workspace_id = ?", workspaceID)
+		return tx.Exec("DELETE FROM group_set_members WHERE group_id = ? AND group_set_id IN (?)", groupID, sets).Error
+	})
+	return removed, err
+}
+
+// IsTracking reports whether a group is on a workspace's list
+func (ws *WorkspaceService) IsTracking(workspaceID, groupID uint) (bool, error) {
+	var count int64
+	err := ws.db.Model(&models.WorkspaceGroup{}).
+		Where("workspace_id = ? AND group_id = ?", workspaceID, groupID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// TrackedGroupIDs returns the IDs of all groups on a workspace's list
+func (ws *WorkspaceService) TrackedGroupIDs(workspaceID uint) ([]uint, error) {
+	var ids []uint
+	err := ws.db.Model(&models.WorkspaceGroup{}).Where("workspace_id = ?", workspaceID).Pluck("group_id", &ids).Error
+	return ids, err
+}
+
+// trackedGroupIDs is a subquery selecting the groups tracked by a workspace
+func trackedGroupIDs(tx *gorm.DB, workspaceID uint) *gorm.DB {
+	return tx.Session(&gorm.Session{NewDB: true}).
+		Model(&models.WorkspaceGroup{}).
+		Select("group_id").
+		Where("workspace_id = ?", workspaceID)
+}
+
+// deleteWorkspace removes a workspace with its tags, sets, keys, members and
+// group list; the groups themselves are shared and kept
+func deleteWorkspace(tx *gorm.DB, workspaceID uint) error {
+	tags := tx.Model(&models.Tag{}).Select("id").Where("workspace_id = ?", workspaceID)
+	if err := tx.Exec("DELETE FROM group_tags WHERE tag_id IN (?)", tags).Error; err != nil {
+		return err
+	}
+	sets := tx.Model(&models.GroupSet{}).Select("id").Where("workspace_id = ?", workspaceID)
+	if err := tx.Exec("DELETE FROM group_set_members WHERE group_set_id IN (?)", sets).Error; err != nil {
+		return err
+	}
+	for _, model := range []interface{}{
+		&models.Tag{}, &models.GroupSet{}, &models.APIKey{}, &models.WorkspaceMember{}, &models.WorkspaceGroup{},
+	} {
+		if err := tx.Where("workspace_id = ?", workspaceID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&models.Session{}).Where("workspace_id = ?", workspaceID).Update("workspace_id", nil).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&models.Workspace{}, workspaceID).Error
+}
+
+func findMember(tx *gorm.DB, workspaceID, userID uint) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	err := tx.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ensureOtherOwner fails unless the workspace has an owner besides the given user
+func ensureOtherOwner(tx *gorm.DB, workspaceID, userID uint) error {
+	var owners int64
+	err := tx.Model(&models.WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id <> ? AND role = ?", workspaceID, userID, models.WorkspaceRoleOwner).
+		Count(&owners).Error
+	if err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func invalidRoleError() error {
+	return fmt.Errorf("%w: role must be %s, %s or %s", ErrWorkspaceInvalid,
+		models.WorkspaceRoleViewer, models.WorkspaceRoleEditor, models.WorkspaceRoleOwner)
+}
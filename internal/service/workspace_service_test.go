@@ -0,0 +1,267 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"social-media-analyzer/internal/config"
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// newTestWorkspace stores a workspace tracking the given groups
+func newTestWorkspace(t *testing.T, db *gorm.DB, groupIDs ...uint) uint {
+	t.Helper()
+	workspace := models.Workspace{Name: "test"}
+	if err := db.Create(&workspace).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ws := NewWorkspaceService(db)
+	for _, id := range groupIDs {
+		if _, err := ws.Track(workspace.ID, id); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	return workspace.ID
+}
+
+func newTestUsers(t *testing.T, db *gorm.DB, usernames ...string) []*models.User {
+	t.Helper()
+	as := NewAuthService(db, config.AuthConfig{SessionTTL: time.Hour})
+	users := make([]*models.User, len(usernames))
+	for i, username := range usernames {
+		user, err := as.CreateUser(username, "correct horse")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		users[i] = user
+	}
+	return users
+}
+
+// TestWorkspaceMembers tests adding members, changing roles and keeping an owner
+func TestWorkspaceMembers(t *testing.T) {
+	db := dbtest.New(t)
+	users := newTestUsers(t, db, "anna", "boris")
+	anna, boris := users[0], users[1]
+	ws := NewWorkspaceService(db)
+
+	workspace, err := ws.Create(anna.ID, " Acme ")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if workspace.Name != "Acme" {
+		t.Errorf("Expected a trimmed name, got %q", workspace.Name)
+	}
+	if _, err := ws.Create(anna.ID, " "); !errors.Is(err, ErrWorkspaceInvalid) {
+		t.Errorf("Expected ErrWorkspaceInvalid for an empty name, got %v", err)
+	}
+
+	if _, err := ws.AddMember(workspace.ID, "Boris", models.WorkspaceRoleViewer); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := ws.AddMember(workspace.ID, "boris", models.WorkspaceRoleEditor); !errors.Is(err, ErrMemberExists) {
+		t.Errorf("Expected ErrMemberExists, got %v", err)
+	}
+	if _, err := ws.AddMember(workspace.ID, "nobody", models.WorkspaceRoleViewer); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := ws.AddMember(workspace.ID, "anna", "admin"); !errors.Is(err, ErrWorkspaceInvalid) {
+		t.Errorf("Expected ErrWorkspaceInvalid for an unknown role, got %v", err)
+	}
+
+	member, err := ws.Membership(workspace.ID, boris.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !member.Can(models.WorkspaceRoleViewer) || member.Can(models.WorkspaceRoleEditor) {
+		t.Errorf("Expected a viewer to read but not edit, got role %q", member.Role)
+	}
+
+	if err := ws.SetRole(workspace.ID, anna.ID, models.WorkspaceRoleEditor); !errors.Is(err, ErrLastOwner) {
+		t.Errorf("Expected ErrLastOwner when demoting the only owner, got %v", err)
+	}
+	if err := ws.RemoveMember(workspace.ID, anna.ID); !errors.Is(err, ErrLastOwner) {
+		t.Errorf("Expected ErrLastOwner when removing the only owner, got %v", err)
+	}
+	if err := ws.SetRole(workspace.ID, boris.ID, models.WorkspaceRoleOwner); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := ws.RemoveMember(workspace.ID, anna.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := ws.Membership(workspace.ID, anna.ID); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Errorf("Expected a removed member to lose access, got %v", err)
+	}
+}
+
+// TestWorkspaceCurrentAndSelect tests which workspace a session works in
+func TestWorkspaceCurrentAndSelect(t *testing.T) {
+	db := dbtest.New(t)
+	users := newTestUsers(t, db, "anna", "boris")
+	anna, boris := users[0], users[1]
+	ws := NewWorkspaceService(db)
+
+	session := &models.Session{TokenHash: "hash", UserID: anna.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := db.Omit("User").Create(session).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := ws.Current(session); !errors.Is(err, ErrNoWorkspace) {
+		t.Errorf("Expected ErrNoWorkspace without memberships, got %v", err)
+	}
+
+	beta, _ := ws.Create(anna.ID, "Beta")
+	alpha, _ := ws.Create(anna.ID, "Alpha")
+	foreign, _ := ws.Create(boris.ID, "Foreign")
+
+	current, err := ws.Current(session)
+	if err != nil || current.WorkspaceID != alpha.ID {
+		t.Fatalf("Expected the first workspace by name, got %+v, %v", current, err)
+	}
+	if err := ws.Select(session, foreign.ID); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Errorf("Expected ErrWorkspaceNotFound for a foreign workspace, got %v", err)
+	}
+	if err := ws.Select(session, beta.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var stored models.Session
+	db.Where("token_hash = ?", "hash").First(&stored)
+	if current, err := ws.Current(&stored); err != nil || current.Workspace.Name != "Beta" {
+		t.Errorf("Expected the selected workspace to stick, got %+v, %v", current, err)
+	}
+}
+
+// TestWorkspacesKeepTagsApart tests that two workspaces tracking the same group
+// see only their own tags, notes and groups
+func TestWorkspacesKeepTagsApart(t *testing.T) {
+	db := dbtest.New(t)
+	groups := []models.Group{{Domain: "shared"}, {Domain: "private"}}
+	if err := db.Create(&groups).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	first := newTestWorkspace(t, db, groups[0].ID, groups[1].ID)
+	second := newTestWorkspace(t, db, groups[0].ID)
+	ts := NewTagService(db)
+
+	if _, err := ts.SetGroupTags(first, groups[0].ID, []string{"client"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := ts.SetGroupTags(second, groups[0].ID, []string{"competitor"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := ts.SetGroupNotes(second, groups[0].ID, "watch closely"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := ts.SetGroupTags(second, groups[1].ID, []string{"x"}); !errors.Is(err, ErrGroupNotFound) {
+		t.Errorf("Expected ErrGroupNotFound for an untracked group, got %v", err)
+	}
+
+	var list []models.Group
+	if err := (GroupFilter{WorkspaceID: first}).Apply(db).Find(&list).Error; err != nil || len(list) != 2 {
+		t.Fatalf("Expected 2 groups in the first workspace, got %d, %v", len(list), err)
+	}
+
+	var group models.Group
+	if err := db.Preload("Tags", "workspace_id = ?", first).First(&group, groups[0].ID).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if names := tagNames(group.Tags); len(names) != 1 || names[0] != "client" {
+		t.Errorf("Expected only the first workspace's tag, got %v", names)
+	}
+
+	usage, err := ts.ListTags(second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(usage) != 1 || usage[0].Name != "competitor" {
+		t.Errorf("Expected only the second workspace's tag, got %+v", usage)
+	}
+
+	var notes string
+	db.Model(&models.WorkspaceGroup{}).Where("workspace_id = ? AND group_id = ?", first, groups[0].ID).Pluck("notes", &notes)
+	if notes != "" {
+		t.Errorf("Expected notes of the second workspace to stay there, got %q", notes)
+	}
+}
+
+// TestUntrack tests that untracking a group drops the workspace's tags and set
+// memberships of it and leaves other workspaces alone
+func TestUntrack(t *testing.T) {
+	db := dbtest.New(t)
+	group := models.Group{Domain: "shared"}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	first := newTestWorkspace(t, db, group.ID)
+	second := newTestWorkspace(t, db, group.ID)
+	ts := NewTagService(db)
+	for _, workspaceID := range []uint{first, second} {
+		if _, err := ts.SetGroupTags(workspaceID, group.ID, []string{"client"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := NewGroupSetService(db, nil).Create(workspaceID, GroupSetInput{Name: "set", GroupIDs: []uint{group.ID}}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	ws := NewWorkspaceService(db)
+	if removed, err := ws.Untrack(first, group.ID); err != nil || !removed {
+		t.Fatalf("Expected the group to be removed, got %v, %v", removed, err)
+	}
+	if removed, err := ws.Untrack(first, group.ID); err != nil || removed {
+		t.Errorf("Expected nothing to remove the second time, got %v, %v", removed, err)
+	}
+
+	for workspaceID, want := range map[uint]int64{first: 0, second: 1} {
+		tracking, _ := ws.IsTracking(workspaceID, group.ID)
+		var tags, members int64
+		db.Table("group_tags").Joins("JOIN tags ON tags.id = group_tags.tag_id").Where("tags.workspace_id = ?", workspaceID).Count(&tags)
+		db.Table("group_set_members").Joins("JOIN group_sets ON group_sets.id = group_set_members.group_set_id").Where("group_sets.workspace_id = ?", workspaceID).Count(&members)
+		if tracking != (want == 1) || tags != want || members != want {
+			t.Errorf("Workspace %d: expected tracking %v with %d tags and set members, got %v, %d, %d", workspaceID, want == 1, want, tracking, tags, members)
+		}
+	}
+}
+
+// TestDeleteUserWithWorkspaces tests that deleting a user keeps shared
+// workspaces owned and drops the ones nobody else uses
+func TestDeleteUserWithWorkspaces(t *testing.T) {
+	db := dbtest.New(t)
+	users := newTestUsers(t, db, "anna", "boris")
+	anna, boris := users[0], users[1]
+	as := NewAuthService(db, config.AuthConfig{SessionTTL: time.Hour})
+	ws := NewWorkspaceService(db)
+
+	own, _ := ws.Create(anna.ID, "Own")
+	shared, _ := ws.Create(anna.ID, "Shared")
+	if _, err := ws.AddMember(shared.ID, "boris", models.WorkspaceRoleEditor); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := db.Create(&models.Tag{WorkspaceID: own.ID, Name: "mine"}).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := as.DeleteUser("anna"); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("Expected ErrLastOwner while anna is the only owner of a shared workspace, got %v", err)
+	}
+	if err := ws.SetRole(shared.ID, boris.ID, models.WorkspaceRoleOwner); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := as.DeleteUser("anna"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var workspaces, tags int64
+	db.Model(&models.Workspace{}).Where("id = ?", own.ID).Count(&workspaces)
+	db.Model(&models.Tag{}).Where("workspace_id = ?", own.ID).Count(&tags)
+	if workspaces != 0 || tags != 0 {
+		t.Errorf("Expected the workspace only anna used to be deleted with its tags, got %d and %d", workspaces, tags)
+	}
+	if members, _ := ws.Members(shared.ID); len(members) != 1 || members[0].UserID != boris.ID {
+		t.Errorf("Expected boris to remain the only member, got %+v", members)
+	}
+}
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/service"
+	"social-media-analyzer/internal/transport/http/router"
+)
+
+type APIKeyController struct {
+	apiKeyService *service.APIKeyService
+	auditor       *Auditor
+	urls          URLFunc
+}
+
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scope      string     `json:"scope"`
+	Workspace  string     `json:"workspace"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// CreatedAPIKeyResponse is returned once on creation; the key cannot be shown again
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type KeysPageData struct {
+	Keys   []APIKeyResponse
+	Scopes []string
+	// Workspace is where new keys are created
+	Workspace string
+	User      string
+	CSRFToken string
+	Error     string
+}
+
+func NewAPIKeyController(apiKeyService *service.APIKeyService, auditor *Auditor, urls URLFunc) *APIKeyController {
+	return &APIKeyController{apiKeyService: apiKeyService, auditor: auditor, urls: urls}
+}
+
+// ListKeys handles GET /api/keys requests for the signed-in user's keys
+func (kc *APIKeyController) ListKeys(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	keys, err := kc.apiKeyService.List(UserFromContext(r.Context()).ID, keyWorkspace(r))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to load API keys"})
+		return
+	}
+	json.NewEncoder(w).Encode(newAPIKeyResponses(keys))
+}
+
+// CreateKey handles POST /api/keys requests ({"name", "scope", "expires_in_days"})
+func (kc *APIKeyController) CreateKey(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var input service.APIKeyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid request body"})
+		return
+	}
+
+	created, err := kc.apiKeyService.Create(UserFromContext(r.Context()).ID, WorkspaceFromContext(r.Context()).WorkspaceID, input)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+	kc.auditor.Record(r, models.AuditAPIKeyCreate, models.AuditTargetAPIKey, created.APIKey.ID, map[string]interface{}{
+		"name":       created.APIKey.Name,
+		"prefix":     created.APIKey.Prefix,
+		"scope":      created.APIKey.Scope,
+		"expires_at": created.APIKey.ExpiresAt,
+	})
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CreatedAPIKeyResponse{
+		APIKeyResponse: newAPIKeyResponse(created.APIKey),
+		Key:            created.Key,
+	})
+}
+
+// RevokeKey handles DELETE /api/keys/:id requests
+func (kc *APIKeyController) RevokeKey(w http.ResponseWriter, r *http.Request, params router.Params) {
+	id, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid key id"})
+		return
+	}
+
+	// A key is revoked in the workspace it was bound to, whichever one the
+	// session works in
+	apiKey, err := kc.apiKeyService.Revoke(UserFromContext(r.Context()).ID, keyWorkspace(r), uint(id))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		writeAPIKeyError(w, err)
+		return
+	}
+	kc.auditor.RecordIn(r, apiKey.WorkspaceID, models.AuditAPIKeyRevoke, models.AuditTargetAPIKey, apiKey.ID, map[string]string{
+		"name":   apiKey.Name,
+		"prefix": apiKey.Prefix,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetKeysPage handles GET /keys requests
+func (kc *APIKeyController) GetKeysPage(w http.ResponseWriter, r *http.Request, params router.Params) {
+	session := SessionFromContext(r.Context())
+	pageData := KeysPageData{
+		Scopes:    []string{models.APIKeyScopeRead, models.APIKeyScopeWrite, models.APIKeyScopeAdmin},
+		Workspace: WorkspaceFromContext(r.Context()).Workspace.Name,
+		User:      session.User.Username,
+		CSRFToken: session.CSRFToken,
+	}
+	keys, err := kc.apiKeyService.List(session.UserID, 0)
+	if err != nil {
+		log.Printf("Failed to load API keys: %v\n", err)
+		pageData.Error = "Не удалось загрузить ключи"
+	} else {
+		pageData.Keys = newAPIKeyResponses(keys)
+	}
+
+	date := func(t time.Time) string {
+		return t.Local().Format("02.01.2006 15:04")
+	}
+	funcMap := template.FuncMap{
+		"date": date,
+		"optionalDate": func(t *time.Time) string {
+			if t == nil {
+				return "—"
+			}
+			return date(*t)
+		},
+		"url": kc.urls,
+	}
+
+	tpl := template.Must(template.New("keys.html").Funcs(funcMap).ParseFiles("web/templates/keys.html"))
+	tpl.Execute(w, pageData)
+}
+
+// keyWorkspace returns the workspace a request signed with an API key is
+// bound to; such requests only manage keys of that workspace. Browser
+// sessions manage the user's keys in every workspace (zero).
+func keyWorkspace(r *http.Request) uint {
+	if apiKey := APIKeyFromContext(r.Context()); apiKey != nil {
+		return apiKey.WorkspaceID
+	}
+	return 0
+}
+
+func newAPIKeyResponse(key *models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scope:      key.Scope,
+		Workspace:  key.Workspace.Name,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+	}
+}
+
+func newAPIKeyResponses(keys []models.APIKey) []APIKeyResponse {
+	resp := make([]APIKeyResponse, len(keys))
+	for i := range keys {
+		resp[i] = newAPIKeyResponse(&keys[i])
+	}
+	return resp
+}
+
+// writeAPIKeyError maps API key service errors to HTTP responses
+func writeAPIKeyError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	message := "Failed to process API key"
+	switch {
+	case errors.Is(err, service.ErrAPIKeyNotFound):
+		status = http.StatusNotFound
+		message = err.Error()
+	case errors.Is(err, service.ErrAPIKeyInvalid):
+		status = http.StatusBadRequest
+		message = err.Error()
+	default:
+		log.Printf("API key request failed: %v\n", err)
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Message: message})
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/service"
+)
+
+// newKey creates an API key; the secret to sign requests with is in Key
+func (app *testApp) newKey(t *testing.T, userID, workspaceID uint, scope string) *service.CreatedAPIKey {
+	t.Helper()
+	created, err := app.services.APIKeyService.Create(userID, workspaceID, service.APIKeyInput{Name: scope, Scope: scope})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return created
+}
+
+// serveKey sends a request signed with an API key
+func (app *testApp) serveKey(method, target, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(`{}`))
+	req.Header.Set("Authorization", "Bearer "+key)
+	return app.serve(req, nil)
+}
+
+// TestAPIKeyScopes tests that a key's scope limits the methods and routes it can use
+func TestAPIKeyScopes(t *testing.T) {
+	app := newTestApp(t)
+	login, workspaceID := app.newUser(t, "anna", "Acme")
+	userID := login.Session.UserID
+	read := app.newKey(t, userID, workspaceID, models.APIKeyScopeRead).Key
+	write := app.newKey(t, userID, workspaceID, models.APIKeyScopeWrite).Key
+	admin := app.newKey(t, userID, workspaceID, models.APIKeyScopeAdmin).Key
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		key    string
+		status int
+		scope  string
+	}{
+		{"read key reads", http.MethodGet, "/api/groups", read, http.StatusOK, ""},
+		{"read key writes", http.MethodPost, "/api/groups", read, http.StatusForbidden, models.APIKeyScopeWrite},
+		{"write key writes", http.MethodPost, "/api/groups", write, http.StatusBadRequest, ""},
+		{"write key lists keys", http.MethodGet, "/api/keys", write, http.StatusForbidden, models.APIKeyScopeAdmin},
+		{"write key creates a key", http.MethodPost, "/api/keys", write, http.StatusForbidden, models.APIKeyScopeAdmin},
+		{"admin key lists keys", http.MethodGet, "/api/keys", admin, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		rec := app.serveKey(tt.method, tt.target, tt.key)
+		if rec.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.status, rec.Code, rec.Body)
+			continue
+		}
+		challenge := rec.Header().Get("WWW-Authenticate")
+		if tt.scope != "" && (!strings.Contains(challenge, `error="insufficient_scope"`) || !strings.Contains(challenge, `scope="`+tt.scope+`"`)) {
+			t.Errorf("%s: expected an insufficient_scope challenge for %s, got %q", tt.name, tt.scope, challenge)
+		}
+	}
+}
+
+// TestAPIKeyRejected tests that expired keys, unknown keys and other
+// authorization schemes get 401
+func TestAPIKeyRejected(t *testing.T) {
+	app := newTestApp(t)
+	login, workspaceID := app.newUser(t, "anna", "Acme")
+	expired := app.newKey(t, login.Session.UserID, workspaceID, models.APIKeyScopeAdmin)
+	if err := app.db.Model(&models.APIKey{}).Where("id = ?", expired.APIKey.ID).Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for name, authorization := range map[string]string{
+		"expired key": "Bearer " + expired.Key,
+		"unknown key": "Bearer sma_unknown_key",
+		"basic auth":  "Basic YW5uYTpjb3JyZWN0IGhvcnNl",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/groups", nil)
+		req.Header.Set("Authorization", authorization)
+		rec := app.serve(req, nil)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401, got %d", name, rec.Code)
+		}
+		invalid := strings.Contains(rec.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+		if invalid != strings.HasPrefix(authorization, "Bearer") {
+			t.Errorf("%s: unexpected challenge %q", name, rec.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+// TestAPIKeysOfKeyWorkspace tests that a key only lists and revokes keys of
+// its own workspace, while a session manages all of the user's keys
+func TestAPIKeysOfKeyWorkspace(t *testing.T) {
+	app := newTestApp(t)
+	login, workspaceID := app.newUser(t, "anna", "Acme")
+	userID := login.Session.UserID
+	other, err := app.services.WorkspaceService.Create(userID, "Other")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	admin := app.newKey(t, userID, workspaceID, models.APIKeyScopeAdmin)
+	foreign := app.newKey(t, userID, other.ID, models.APIKeyScopeRead)
+
+	rec := app.serveKey(http.MethodGet, "/api/keys", admin.Key)
+	var keys []APIKeyResponse
+	if err := json.NewDecoder(rec.Body).Decode(&keys); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].ID != admin.APIKey.ID {
+		t.Errorf("Expected only the key's own workspace, got %+v", keys)
+	}
+
+	target := fmt.Sprintf("/api/keys/%d", foreign.APIKey.ID)
+	if rec := app.serveKey(http.MethodDelete, target, admin.Key); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 revoking a key of another workspace, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, target, nil)
+	req.Header.Set(CSRFHeader, login.Session.CSRFToken)
+	if rec := app.serve(req, login); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected the session to revoke the key, got %d: %s", rec.Code, rec.Body)
+	}
+}
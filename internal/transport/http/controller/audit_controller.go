@@ -0,0 +1,273 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/service"
+	"social-media-analyzer/internal/transport/http/router"
+)
+
+// Auditor records the actions of a request's user in the audit log. A failed
+// write is logged and does not fail the action it describes.
+type Auditor struct {
+	auditService *service.AuditService
+}
+
+type AuditController struct {
+	auditService *service.AuditService
+	urls         URLFunc
+}
+
+type AuditEventResponse struct {
+	ID          uint            `json:"id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UserID      *uint           `json:"user_id"`
+	Username    string          `json:"username"`
+	APIKeyID    *uint           `json:"api_key_id"`
+	WorkspaceID *uint           `json:"workspace_id"`
+	Action      string          `json:"action"`
+	TargetType  string          `json:"target_type"`
+	TargetID    string          `json:"target_id"`
+	Details     json.RawMessage `json:"details"`
+	RequestID   string          `json:"request_id"`
+}
+
+// AuditListResponse is one page of events; NextBeforeID is passed as
+// before_id to get the next one and is empty on the last page
+type AuditListResponse struct {
+	Events       []AuditEventResponse `json:"events"`
+	NextBeforeID *uint                `json:"next_before_id"`
+}
+
+type AuditPageData struct {
+	Events    []AuditEventResponse
+	Filter    service.AuditFilter
+	Actions   []string
+	Targets   []string
+	OlderURL  string
+	Workspace string
+	User      string
+	CSRFToken string
+	Error     string
+}
+
+func NewAuditor(auditService *service.AuditService) *Auditor {
+	return &Auditor{auditService: auditService}
+}
+
+func NewAuditController(auditService *service.AuditService, urls URLFunc) *AuditController {
+	return &AuditController{auditService: auditService, urls: urls}
+}
+
+// Record records an action on one target in the request's workspace
+func (a *Auditor) Record(r *http.Request, action, targetType string, targetID uint, details interface{}) {
+	a.RecordIn(r, WorkspaceFromContext(r.Context()).WorkspaceID, action, targetType, targetID, details)
+}
+
+// RecordEach records one event per target, e.g. for every group of a bulk request
+func (a *Auditor) RecordEach(r *http.Request, action, targetType string, targetIDs []uint, details interface{}) {
+	workspaceID := WorkspaceFromContext(r.Context()).WorkspaceID
+	events := make([]models.AuditEvent, len(targetIDs))
+	for i, id := range targetIDs {
+		events[i] = newAuditEvent(r, workspaceID, action, targetType, id, details)
+	}
+	a.write(events...)
+}
+
+// RecordIn records an action in a given workspace, for requests that do not
+// work in one, such as creating a workspace
+func (a *Auditor) RecordIn(r *http.Request, workspaceID uint, action, targetType string, targetID uint, details interface{}) {
+	a.write(newAuditEvent(r, workspaceID, action, targetType, targetID, details))
+}
+
+func (a *Auditor) write(events ...models.AuditEvent) {
+	if err := a.auditService.Record(events...); err != nil {
+		log.Printf("Failed to record audit events: %v\n", err)
+	}
+}
+
+// newAuditEvent fills in the actor and request ID of an event
+func newAuditEvent(r *http.Request, workspaceID uint, action, targetType string, targetID uint, details interface{}) models.AuditEvent {
+	event := models.AuditEvent{
+		WorkspaceID: &workspaceID,
+		Action:      action,
+		TargetType:  targetType,
+		TargetID:    service.AuditTargetID(targetID),
+		Details:     service.AuditDetails(details),
+		RequestID:   router.RequestIDFromContext(r.Context()),
+	}
+	if user := UserFromContext(r.Context()); user != nil {
+		event.UserID = &user.ID
+		event.Username = user.Username
+	}
+	if apiKey := APIKeyFromContext(r.Context()); apiKey != nil {
+		event.APIKeyID = &apiKey.ID
+	}
+	return event
+}
+
+// ListEvents handles GET /api/audit requests for the workspace's audit log.
+// Query parameters: action, actor, target_type, target_id, from, to
+// (YYYY-MM-DD), before_id and limit.
+func (ac *AuditController) ListEvents(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	filter, err := auditFilterFromQuery(r)
+	if err != nil {
+		writeAuditError(w, err)
+		return
+	}
+	events, err := ac.auditService.List(filter)
+	if err != nil {
+		writeAuditError(w, err)
+		return
+	}
+
+	resp := AuditListResponse{Events: newAuditEventResponses(events)}
+	if fullAuditPage(filter, events) {
+		resp.NextBeforeID = &events[len(events)-1].ID
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetAuditPage handles GET /audit requests; only workspace owners see the log
+func (ac *AuditController) GetAuditPage(w http.ResponseWriter, r *http.Request, params router.Params) {
+	session := SessionFromContext(r.Context())
+	workspace := WorkspaceFromContext(r.Context())
+	if !workspace.Can(models.WorkspaceRoleOwner) {
+		http.Error(w, "Only workspace owners can view the audit log", http.StatusForbidden)
+		return
+	}
+
+	pageData := AuditPageData{
+		Actions: []string{
+			models.AuditGroupAdd, models.AuditGroupRefresh, models.AuditGroupRemove, models.AuditGroupTags, models.AuditGroupNotes,
+			models.AuditGroupSyncCancel, models.AuditGroupPurge,
+			models.AuditSetCreate, models.AuditSetUpdate, models.AuditSetDelete,
+			models.AuditWorkspaceCreate, models.AuditMemberAdd, models.AuditMemberRole, models.AuditMemberRemove,
+			models.AuditAPIKeyCreate, models.AuditAPIKeyRevoke,
+		},
+		Targets: []string{
+			models.AuditTargetGroup, models.AuditTargetSet, models.AuditTargetWorkspace,
+			models.AuditTargetUser, models.AuditTargetAPIKey,
+		},
+		Workspace: workspace.Workspace.Name,
+		User:      session.User.Username,
+		CSRFToken: session.CSRFToken,
+	}
+
+	filter, err := auditFilterFromQuery(r)
+	pageData.Filter = filter
+	if err == nil {
+		var events []models.AuditEvent
+		events, err = ac.auditService.List(filter)
+		pageData.Events = newAuditEventResponses(events)
+		if err == nil && fullAuditPage(filter, events) {
+			pageData.OlderURL = olderAuditURL(r, events[len(events)-1].ID)
+		}
+	}
+	if errors.Is(err, service.ErrAuditFilterInvalid) {
+		pageData.Error = "Некорректный фильтр: " + err.Error()
+	} else if err != nil {
+		log.Printf("Failed to load audit events: %v\n", err)
+		pageData.Error = "Не удалось загрузить журнал"
+	}
+
+	funcMap := template.FuncMap{
+		"date": func(t time.Time) string {
+			return t.Local().Format("02.01.2006 15:04:05")
+		},
+		"url": ac.urls,
+	}
+
+	tpl := template.Must(template.New("audit.html").Funcs(funcMap).ParseFiles("web/templates/audit.html"))
+	tpl.Execute(w, pageData)
+}
+
+// auditFilterFromQuery reads an audit filter of the request's workspace from query parameters
+func auditFilterFromQuery(r *http.Request) (service.AuditFilter, error) {
+	q := r.URL.Query()
+	filter := service.AuditFilter{
+		WorkspaceID: WorkspaceFromContext(r.Context()).WorkspaceID,
+		Action:      q.Get("action"),
+		Actor:       q.Get("actor"),
+		TargetType:  q.Get("target_type"),
+		TargetID:    q.Get("target_id"),
+		From:        q.Get("from"),
+		To:          q.Get("to"),
+	}
+	if v := q.Get("before_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("%w: before_id must be a positive integer", service.ErrAuditFilterInvalid)
+		}
+		filter.BeforeID = uint(id)
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, fmt.Errorf("%w: limit must be an integer", service.ErrAuditFilterInvalid)
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+// fullAuditPage reports whether a listing filled its page, so older events may follow
+func fullAuditPage(filter service.AuditFilter, events []models.AuditEvent) bool {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = service.DefaultAuditLimit
+	}
+	return len(events) > 0 && len(events) == limit
+}
+
+// olderAuditURL links to the page of events below beforeID with the same filter
+func olderAuditURL(r *http.Request, beforeID uint) string {
+	q := r.URL.Query()
+	q.Set("before_id", strconv.FormatUint(uint64(beforeID), 10))
+	return (&url.URL{Path: r.URL.Path, RawQuery: q.Encode()}).String()
+}
+
+func newAuditEventResponses(events []models.AuditEvent) []AuditEventResponse {
+	resp := make([]AuditEventResponse, len(events))
+	for i, event := range events {
+		resp[i] = AuditEventResponse{
+			ID:          event.ID,
+			CreatedAt:   event.CreatedAt,
+			UserID:      event.UserID,
+			Username:    event.Username,
+			APIKeyID:    event.APIKeyID,
+			WorkspaceID: event.WorkspaceID,
+			Action:      event.Action,
+			TargetType:  event.TargetType,
+			TargetID:    event.TargetID,
+			Details:     json.RawMessage(event.Details),
+			RequestID:   event.RequestID,
+		}
+	}
+	return resp
+}
+
+// writeAuditError maps audit service errors to HTTP responses
+func writeAuditError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	message := "Failed to load audit events"
+	if errors.Is(err, service.ErrAuditFilterInvalid) {
+		status = http.StatusBadRequest
+		message = err.Error()
+	} else {
+		log.Printf("Audit request failed: %v\n", err)
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Message: message})
+}
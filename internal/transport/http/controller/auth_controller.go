@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"social-media-analyzer/internal/config"
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/service"
+	"social-media-analyzer/internal/transport/http/router"
+)
+
+const (
+	// SessionCookie holds the session token of a signed-in browser
+	SessionCookie = "session"
+	// CSRFHeader carries the session's CSRF token on requests sent by scripts;
+	// HTML forms send it in the csrf_token field instead
+	CSRFHeader = "X-CSRF-Token"
+	csrfField  = "csrf_token"
+)
+
+type sessionContextKey struct{}
+
+type apiKeyContextKey struct{}
+
+type AuthController struct {
+	authService   *service.AuthService
+	apiKeyService *service.APIKeyService
+	cfg           config.AuthConfig
+	urls          URLFunc
+}
+
+type LoginPageData struct {
+	Username string
+	Next     string
+	Error    string
+}
+
+func NewAuthController(authService *service.AuthService, apiKeyService *service.APIKeyService, cfg config.AuthConfig, urls URLFunc) *AuthController {
+	return &AuthController{authService: authService, apiKeyService: apiKeyService, cfg: cfg, urls: urls}
+}
+
+// SessionFromContext returns the session set by the login middlewares, nil on
+// public routes and for requests signed with an API key
+func SessionFromContext(ctx context.Context) *models.Session {
+	session, _ := ctx.Value(sessionContextKey{}).(*models.Session)
+	return session
+}
+
+// APIKeyFromContext returns the API key a request was signed with, nil for browser sessions
+func APIKeyFromContext(ctx context.Context) *models.APIKey {
+	apiKey, _ := ctx.Value(apiKeyContextKey{}).(*models.APIKey)
+	return apiKey
+}
+
+// UserFromContext returns the signed-in user, whether by session or API key
+func UserFromContext(ctx context.Context) *models.User {
+	if session := SessionFromContext(ctx); session != nil {
+		return &session.User
+	}
+	if apiKey := APIKeyFromContext(ctx); apiKey != nil {
+		return &apiKey.User
+	}
+	return nil
+}
+
+// GetLoginPage handles GET /login requests
+func (ac *AuthController) GetLoginPage(w http.ResponseWriter, r *http.Request, params router.Params) {
+	next := localPath(r.URL.Query().Get("next"))
+	if cookie, err := r.Cookie(SessionCookie); err == nil {
+		if _, _, err := ac.authService.Authenticate(cookie.Value); err == nil {
+			ac.redirectAfterLogin(w, r, next)
+			return
+		}
+	}
+	ac.renderLogin(w, http.StatusOK, LoginPageData{Next: next})
+}
+
+// Login handles POST /login form submissions
+func (ac *AuthController) Login(w http.ResponseWriter, r *http.Request, params router.Params) {
+	// The form is not tied to a session yet, so a foreign origin is the only sign of forgery
+	if !sameOrigin(r) {
+		http.Error(w, "Cross-origin login is not allowed", http.StatusForbidden)
+		return
+	}
+
+	username := r.PostFormValue("username")
+	next := localPath(r.PostFormValue("next"))
+	login, err := ac.authService.Login(username, r.PostFormValue("password"))
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		ac.renderLogin(w, http.StatusUnauthorized, LoginPageData{Username: username, Next: next, Error: "Неверное имя пользователя или пароль"})
+		return
+	}
+	if err != nil {
+		log.Printf("Login failed: %v\n", err)
+		http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+
+	ac.setSessionCookie(w, login.Token, login.Session.ExpiresAt)
+	ac.redirectAfterLogin(w, r, next)
+}
+
+// Logout handles POST /logout requests
+func (ac *AuthController) Logout(w http.ResponseWriter, r *http.Request, params router.Params) {
+	if cookie, err := r.Cookie(SessionCookie); err == nil {
+		if err := ac.authService.Logout(cookie.Value); err != nil {
+			log.Printf("Logout failed: %v\n", err)
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   ac.cfg.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	loginURL, _ := ac.urls("login")
+	http.Redirect(w, r, loginURL, http.StatusSeeOther)
+}
+
+// RequirePageLogin is the router middleware for pages: anonymous visitors are
+// redirected to the login page and come back after signing in
+func (ac *AuthController) RequirePageLogin() router.MiddlewareFunc {
+	return ac.requireLogin(func(w http.ResponseWriter, r *http.Request) {
+		loginURL, _ := ac.urls("login", "next", r.URL.RequestURI())
+		http.Redirect(w, r, loginURL, http.StatusSeeOther)
+	})
+}
+
+// RequireAPILogin is the router middleware for the JSON API. Requests are
+// signed in either by the session cookie or by an API key in
+// "Authorization: Bearer"; anonymous requests get 401. A key's scope must
+// cover the method: read for GET, HEAD and OPTIONS, write for the rest.
+func (ac *AuthController) RequireAPILogin() router.MiddlewareFunc {
+	sessionLogin := ac.requireLogin(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+		writeAuthError(w, http.StatusUnauthorized, "Authentication required")
+	})
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		withSession := sessionLogin(next)
+		return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+			authorization := r.Header.Get("Authorization")
+			if authorization == "" {
+				withSession(w, r, params)
+				return
+			}
+
+			scheme, key, _ := strings.Cut(authorization, " ")
+			if !strings.EqualFold(scheme, "Bearer") {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				writeAuthError(w, http.StatusUnauthorized, "Unsupported authorization scheme")
+				return
+			}
+			apiKey, err := ac.apiKeyService.Authenticate(strings.TrimSpace(key))
+			if errors.Is(err, service.ErrAPIKeyNotFound) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+				writeAuthError(w, http.StatusUnauthorized, "Invalid or expired API key")
+				return
+			}
+			if err != nil {
+				log.Printf("API key lookup failed: %v\n", err)
+				http.Error(w, "Failed to check API key", http.StatusInternalServerError)
+				return
+			}
+
+			scope := models.APIKeyScopeWrite
+			if safeMethod(r.Method) {
+				scope = models.APIKeyScopeRead
+			}
+			if !apiKey.Allows(scope) {
+				writeScopeError(w, scope)
+				return
+			}
+			next(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, apiKey)), params)
+		}
+	}
+}
+
+// RequireScope is a per-route middleware for routes behind RequireAPILogin
+// that need more than the method implies, such as managing API keys.
+// Browser sessions always pass.
+func (ac *AuthController) RequireScope(scope string) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+			if apiKey := APIKeyFromContext(r.Context()); apiKey != nil && !apiKey.Allows(scope) {
+				writeScopeError(w, scope)
+				return
+			}
+			next(w, r, params)
+		}
+	}
+}
+
+// requireLogin resolves the session cookie, checks the CSRF token on
+// state-changing requests and puts the session into the request context
+func (ac *AuthController) requireLogin(unauthorized http.HandlerFunc) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+			cookie, err := r.Cookie(SessionCookie)
+			if err != nil {
+				unauthorized(w, r)
+				return
+			}
+			session, renewed, err := ac.authService.Authenticate(cookie.Value)
+			if errors.Is(err, service.ErrSessionNotFound) {
+				unauthorized(w, r)
+				return
+			}
+			if err != nil {
+				log.Printf("Session lookup failed: %v\n", err)
+				http.Error(w, "Failed to check session", http.StatusInternalServerError)
+				return
+			}
+
+			if !safeMethod(r.Method) && !validCSRFToken(r, session.CSRFToken) {
+				writeAuthError(w, http.StatusForbidden, "Invalid or missing CSRF token")
+				return
+			}
+			if renewed {
+				ac.setSessionCookie(w, cookie.Value, session.ExpiresAt)
+			}
+			next(w, r.WithContext(context.WithValue(r.Context(), sessionContextKey{}, session)), params)
+		}
+	}
+}
+
+func (ac *AuthController) setSessionCookie(w http.ResponseWriter, token string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   ac.cfg.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (ac *AuthController) redirectAfterLogin(w http.ResponseWriter, r *http.Request, next string) {
+	if next == "" {
+		next, _ = ac.urls("main")
+	}
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+func (ac *AuthController) renderLogin(w http.ResponseWriter, status int, data LoginPageData) {
+	funcMap := template.FuncMap{
+		"url": ac.urls,
+	}
+	tpl := template.Must(template.New("login.html").Funcs(funcMap).ParseFiles("web/templates/login.html"))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	tpl.Execute(w, data)
+}
+
+func writeAuthError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Message: message})
+}
+
+func writeScopeError(w http.ResponseWriter, scope string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="insufficient_scope", scope="`+scope+`"`)
+	writeAuthError(w, http.StatusForbidden, "API key scope does not allow this request, "+scope+" is required")
+}
+
+// localPath keeps a post-login redirect target only if it stays on this site
+func localPath(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return ""
+	}
+	return next
+}
+
+// sameOrigin reports whether a form was submitted from this site. Browsers
+// send Origin on cross-site POSTs; requests without it are not from a browser form.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func validCSRFToken(r *http.Request, expected string) bool {
+	token := r.Header.Get(CSRFHeader)
+	if token == "" {
+		token = r.PostFormValue(csrfField)
+	}
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
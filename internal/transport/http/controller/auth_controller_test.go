@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"social-media-analyzer/internal/config"
+	"social-media-analyzer/internal/db/dbtest"
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/service"
+	"social-media-analyzer/internal/transport/http/router"
+
+	"gorm.io/gorm"
+)
+
+const testSessionTTL = time.Hour
+
+// testApp is the application wired like cmd/app on a migrated SQLite
+// database, limited to the routes the request-level tests use
+type testApp struct {
+	db       *gorm.DB
+	services *service.ServiceContainer
+	router   *router.Router
+}
+
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+	db := dbtest.New(t)
+	cfg := &config.Config{
+		Auth: config.AuthConfig{SessionTTL: testSessionTTL},
+		VK:   config.VKConfig{AccessToken: "token", APIVersion: "5.131"},
+	}
+	services := service.NewServiceFactory(cfg, db).CreateServices()
+
+	r := router.New()
+	r.Use(router.RequestID())
+
+	auditor := NewAuditor(services.AuditService)
+	pageCtrl := NewMainController(services.TemplateDataService, services.TagService, r.URL)
+	groupCtrl := NewGroupController(services.VKService, services.GroupIdentityService, services.DailyStatsService, services.AnalyticsService, services.SyncService, services.WorkspaceService, auditor)
+	authCtrl := NewAuthController(services.AuthService, services.APIKeyService, cfg.Auth, r.URL)
+	keyCtrl := NewAPIKeyController(services.APIKeyService, auditor, r.URL)
+	workspaceCtrl := NewWorkspaceController(services.WorkspaceService, auditor, r.URL)
+	auditCtrl := NewAuditController(services.AuditService, r.URL)
+	retentionCtrl := NewRetentionController(services.RetentionService)
+
+	r.GET("/login", authCtrl.GetLoginPage).Name("login")
+	r.POST("/login", authCtrl.Login)
+
+	pages := r.Group("/", authCtrl.RequirePageLogin())
+	pages.POST("/logout", authCtrl.Logout).Name("logout")
+	wsPages := pages.Group("/", workspaceCtrl.RequireWorkspacePage())
+	wsPages.GET("/", pageCtrl.GetMainPage).Name("main")
+
+	authed := r.Group("/api", authCtrl.RequireAPILogin())
+	authed.GET("/workspaces", workspaceCtrl.ListWorkspaces)
+	authed.POST("/workspaces", workspaceCtrl.CreateWorkspace)
+
+	api := authed.Group("/", workspaceCtrl.RequireWorkspace())
+	api.GET("/groups", groupCtrl.ListGroups)
+	api.POST("/groups", groupCtrl.AddGroup)
+	group := api.Group("/groups/:id", workspaceCtrl.RequireTrackedGroup())
+	group.DELETE("", groupCtrl.RemoveGroup)
+	group.GET("/trend", groupCtrl.GetGroupTrend)
+	group.DELETE("/sync", groupCtrl.CancelSync)
+	api.GET("/retention", retentionCtrl.GetRetention)
+	api.GET("/audit", auditCtrl.ListEvents,
+		authCtrl.RequireScope(models.APIKeyScopeAdmin),
+		workspaceCtrl.RequireWorkspaceRole(models.WorkspaceRoleOwner))
+
+	keys := authed.Group("/keys",
+		authCtrl.RequireScope(models.APIKeyScopeAdmin),
+		workspaceCtrl.RequireWorkspaceRole(models.WorkspaceRoleViewer))
+	keys.GET("", keyCtrl.ListKeys)
+	keys.POST("", keyCtrl.CreateKey)
+	keys.DELETE("/:id{uint}", keyCtrl.RevokeKey)
+
+	return &testApp{db: db, services: services, router: r}
+}
+
+// newUser creates a user, a workspace owned by it when workspace is set,
+// and signs the user in
+func (app *testApp) newUser(t *testing.T, username, workspace string) (*service.LoginSession, uint) {
+	t.Helper()
+	user, err := app.services.AuthService.CreateUser(username, "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var workspaceID uint
+	if workspace != "" {
+		created, err := app.services.WorkspaceService.Create(user.ID, workspace)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		workspaceID = created.ID
+	}
+	return app.login(t, username), workspaceID
+}
+
+func (app *testApp) login(t *testing.T, username string) *service.LoginSession {
+	t.Helper()
+	login, err := app.services.AuthService.Login(username, "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return login
+}
+
+// serve sends a request signed in with the session, if any
+func (app *testApp) serve(req *http.Request, login *service.LoginSession) *httptest.ResponseRecorder {
+	if login != nil {
+		req.AddCookie(&http.Cookie{Name: SessionCookie, Value: login.Token})
+	}
+	rec := httptest.NewRecorder()
+	app.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// sessionCookie returns the session cookie set by a response, nil if there is none
+func sessionCookie(rec *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == SessionCookie {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// TestCSRFProtection tests that state-changing requests of a session need its
+// CSRF token in the header or form, while reads do not
+func TestCSRFProtection(t *testing.T) {
+	app := newTestApp(t)
+	login, _ := app.newUser(t, "anna", "Acme")
+
+	for name, token := range map[string]string{"missing": "", "wrong": "not-the-token"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/workspaces", strings.NewReader(`{"name":"Other"}`))
+		if token != "" {
+			req.Header.Set(CSRFHeader, token)
+		}
+		if rec := app.serve(req, login); rec.Code != http.StatusForbidden {
+			t.Errorf("Expected 403 for a %s token, got %d", name, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/workspaces", strings.NewReader(`{"name":"Other"}`))
+	req.Header.Set(CSRFHeader, login.Session.CSRFToken)
+	if rec := app.serve(req, login); rec.Code != http.StatusCreated {
+		t.Errorf("Expected 201 with the token, got %d: %s", rec.Code, rec.Body)
+	}
+
+	if rec := app.serve(httptest.NewRequest(http.MethodGet, "/api/workspaces", nil), login); rec.Code != http.StatusOK {
+		t.Errorf("Expected a GET without a token to pass, got %d", rec.Code)
+	}
+
+	// Forms send the token in a field
+	if rec := app.serve(httptest.NewRequest(http.MethodPost, "/logout", nil), login); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a form without a token, got %d", rec.Code)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/logout", strings.NewReader(url.Values{csrfField: {login.Session.CSRFToken}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if rec := app.serve(req, login); rec.Code != http.StatusSeeOther {
+		t.Errorf("Expected the form with a token to log out, got %d", rec.Code)
+	}
+}
+
+// TestExpiredSession tests that an expired session gets 401 from the API and
+// a redirect to the login page from pages
+func TestExpiredSession(t *testing.T) {
+	app := newTestApp(t)
+	login, _ := app.newUser(t, "anna", "Acme")
+	if err := app.db.Model(&models.Session{}).Where("user_id = ?", login.Session.UserID).Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rec := app.serve(httptest.NewRequest(http.MethodGet, "/api/groups", nil), login)
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected 401 with WWW-Authenticate from the API, got %d %q", rec.Code, rec.Header().Get("WWW-Authenticate"))
+	}
+
+	rec = app.serve(httptest.NewRequest(http.MethodGet, "/?period=week", nil), login)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("Expected a redirect from a page, got %d", rec.Code)
+	}
+	if location := rec.Header().Get("Location"); location != "/login?next="+url.QueryEscape("/?period=week") {
+		t.Errorf("Expected a redirect to the login page coming back to the page, got %q", location)
+	}
+}
+
+// TestLoginOrigin tests that login forms posted from another site are rejected
+func TestLoginOrigin(t *testing.T) {
+	app := newTestApp(t)
+	app.newUser(t, "anna", "Acme")
+	form := url.Values{"username": {"anna"}, "password": {"correct horse"}}.Encode()
+
+	for origin, status := range map[string]int{
+		"https://evil.com":   http.StatusForbidden,
+		"http://example.com": http.StatusSeeOther,
+		"":                   http.StatusSeeOther,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rec := app.serve(req, nil)
+		if rec.Code != status {
+			t.Errorf("Expected %d for origin %q, got %d", status, origin, rec.Code)
+		}
+		if signedIn := sessionCookie(rec) != nil; signedIn != (status == http.StatusSeeOther) {
+			t.Errorf("Unexpected session cookie for origin %q: %v", origin, signedIn)
+		}
+	}
+}
+
+// TestLoginRedirect tests that only local paths are followed after signing in
+func TestLoginRedirect(t *testing.T) {
+	app := newTestApp(t)
+	app.newUser(t, "anna", "Acme")
+
+	for next, location := range map[string]string{
+		"/compare?tag=news": "/compare?tag=news",
+		"//evil.com":        "/",
+		`/\evil.com`:        "/",
+		"https://evil.com":  "/",
+	} {
+		form := url.Values{"username": {"anna"}, "password": {"correct horse"}, "next": {next}}.Encode()
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := app.serve(req, nil)
+		if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != location {
+			t.Errorf("Expected a redirect to %q for next=%q, got %d %q", location, next, rec.Code, rec.Header().Get("Location"))
+		}
+	}
+}
+
+// TestSessionRenewal tests that the cookie is sent again once the session is
+// extended, and not on every request
+func TestSessionRenewal(t *testing.T) {
+	app := newTestApp(t)
+	login, _ := app.newUser(t, "anna", "Acme")
+
+	rec := app.serve(httptest.NewRequest(http.MethodGet, "/api/workspaces", nil), login)
+	if rec.Code != http.StatusOK || sessionCookie(rec) != nil {
+		t.Errorf("Expected no cookie for a fresh session, got %d %v", rec.Code, sessionCookie(rec))
+	}
+
+	expiring := time.Now().Add(testSessionTTL / 4)
+	if err := app.db.Model(&models.Session{}).Where("user_id = ?", login.Session.UserID).Update("expires_at", expiring).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rec = app.serve(httptest.NewRequest(http.MethodGet, "/api/workspaces", nil), login)
+	cookie := sessionCookie(rec)
+	if rec.Code != http.StatusOK || cookie == nil {
+		t.Fatalf("Expected the renewed cookie, got %d %v", rec.Code, cookie)
+	}
+	if cookie.Value != login.Token || !cookie.Expires.After(expiring.Add(testSessionTTL/2)) || !cookie.HttpOnly {
+		t.Errorf("Unexpected renewed cookie %+v", cookie)
+	}
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"social-media-analyzer/internal/service"
+	"social-media-analyzer/internal/transport/http/router"
+)
+
+type ComparisonController struct {
+	comparisonService *service.ComparisonService
+	urls              URLFunc
+}
+
+type ComparePageData struct {
+	Report    service.ComparisonReport
+	ActiveTag string
+	Error     string
+}
+
+func NewComparisonController(comparisonService *service.ComparisonService, urls URLFunc) *ComparisonController {
+	return &ComparisonController{comparisonService: comparisonService, urls: urls}
+}
+
+// Compare handles GET /api/compare requests.
+// Query parameters prev_from, prev_to, cur_from, cur_to default to last month vs this month;
+// an optional tag parameter limits the report to tagged groups.
+func (cc *ComparisonController) Compare(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	previous, current := periodsFromQuery(r)
+	filter := service.GroupFilter{
+		WorkspaceID: WorkspaceFromContext(r.Context()).WorkspaceID,
+		Tag:         r.URL.Query().Get("tag"),
+	}
+	report, err := cc.comparisonService.Compare(previous, current, filter)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetComparePage handles GET /compare requests
+func (cc *ComparisonController) GetComparePage(w http.ResponseWriter, r *http.Request, params router.Params) {
+	previous, current := periodsFromQuery(r)
+	filter := service.GroupFilter{
+		WorkspaceID: WorkspaceFromContext(r.Context()).WorkspaceID,
+		Tag:         r.URL.Query().Get("tag"),
+	}
+
+	pageData := ComparePageData{
+		Report:    service.ComparisonReport{Previous: previous, Current: current},
+		ActiveTag: service.NormalizeTag(filter.Tag),
+	}
+	report, err := cc.comparisonService.Compare(previous, current, filter)
+	if err != nil {
+		pageData.Error = err.Error()
+	} else {
+		pageData.Report = report
+	}
+
+	funcMap := template.FuncMap{
+		"percent": func(p *float64) string {
+			if p == nil {
+				return "—"
+			}
+			return formatSigned(*p) + "%"
+		},
+		"signed":      formatSigned,
+		"statusLabel": statusLabel,
+		"url":         cc.urls,
+	}
+
+	tpl := template.Must(template.New("compare.html").Funcs(funcMap).ParseFiles("web/templates/compare.html"))
+	tpl.Execute(w, pageData)
+}
+
+// periodsFromQuery reads both periods from query parameters, falling back to defaults
+func periodsFromQuery(r *http.Request) (service.Period, service.Period) {
+	previous, current := service.DefaultPeriods(time.Now().UTC())
+	q := r.URL.Query()
+
+	if v := q.Get("prev_from"); v != "" {
+		previous.From = v
+	}
+	if v := q.Get("prev_to"); v != "" {
+		previous.To = v
+	}
+	if v := q.Get("cur_from"); v != "" {
+		current.From = v
+	}
+	if v := q.Get("cur_to"); v != "" {
+		current.To = v
+	}
+
+	return previous, current
+}
+
+// formatSigned formats a change with an explicit sign, e.g. +12.50
+func formatSigned(v float64) string {
+	return fmt.Sprintf("%+.2f", v)
+}
@@ -2,21 +2,25 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"social-media-analyzer/internal/models"
 	"social-media-analyzer/internal/service"
 	"social-media-analyzer/internal/transport/http/router"
-
-	"gorm.io/gorm"
 )
 
 type GroupController struct {
-	db        *gorm.DB
-	vkService *service.VKService
+	vkService         *service.VKService
+	identityService   *service.GroupIdentityService
+	dailyStatsService *service.DailyStatsService
+	analyticsService  *service.AnalyticsService
+	syncService       *service.SyncService
+	workspaceService  *service.WorkspaceService
+	auditor           *Auditor
 }
 
 type AddGroupRequest struct {
@@ -32,8 +36,42 @@ type SuccessResponse struct {
 	GroupID uint   `json:"group_id"`
 }
 
-func NewGroupController(db *gorm.DB, vkService *service.VKService) *GroupController {
-	return &GroupController{db: db, vkService: vkService}
+type TrendResponse struct {
+	GroupID uint                     `json:"group_id"`
+	From    string                   `json:"from"`
+	To      string                   `json:"to"`
+	Days    []models.GroupDailyStats `json:"days"`
+}
+
+func NewGroupController(vkService *service.VKService, identityService *service.GroupIdentityService, dailyStatsService *service.DailyStatsService, analyticsService *service.AnalyticsService, syncService *service.SyncService, workspaceService *service.WorkspaceService, auditor *Auditor) *GroupController {
+	return &GroupController{
+		vkService:         vkService,
+		identityService:   identityService,
+		dailyStatsService: dailyStatsService,
+		analyticsService:  analyticsService,
+		syncService:       syncService,
+		workspaceService:  workspaceService,
+		auditor:           auditor,
+	}
+}
+
+// ListGroups handles GET /api/groups requests; an optional tag parameter filters the list
+func (gc *GroupController) ListGroups(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	stats, err := gc.analyticsService.CalculateGroupStats(service.GroupFilter{
+		WorkspaceID: WorkspaceFromContext(r.Context()).WorkspaceID,
+		Tag:         r.URL.Query().Get("tag"),
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to load groups"})
+		return
+	}
+	if stats == nil {
+		stats = []service.GroupStats{}
+	}
+	json.NewEncoder(w).Encode(stats)
 }
 
 // AddGroup handles POST /api/groups requests
@@ -56,43 +94,38 @@ func (gc *GroupController) AddGroup(w http.ResponseWriter, r *http.Request, para
 	}
 
 	// Parse group from link using VK API
-	parsedGroup, err := gc.vkService.ParseGroupFromLink(req.Link)
+	parsedGroup, err := gc.vkService.ParseGroupFromLink(r.Context(), req.Link)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(ErrorResponse{Message: err.Error()})
 		return
 	}
 
-	// Check if group already exists
-	var existingGroup models.Group
-	groupExists := gc.db.Where("domain = ?", parsedGroup.Domain).First(&existingGroup).Error == nil
+	// Store the group under its VK ID, updating it if it is already tracked
+	group, _, err := gc.identityService.Upsert(parsedGroup)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to add group"})
+		return
+	}
+	groupID := group.ID
 
-	var groupID uint
-	if groupExists {
-		// Update existing group
-		if result := gc.db.Model(&existingGroup).Updates(parsedGroup); result.Error != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to update group"})
-			return
-		}
-		groupID = existingGroup.ID
-		parsedGroup.ID = existingGroup.ID
-	} else {
-		// Create new group
-		if result := gc.db.Create(&parsedGroup); result.Error != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to add group"})
-			return
-		}
-		groupID = parsedGroup.ID
+	workspaceID := WorkspaceFromContext(r.Context()).WorkspaceID
+	added, err := gc.workspaceService.Track(workspaceID, groupID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to add group"})
+		return
+	}
+	// Adding a group the workspace already tracks refreshes it
+	action := models.AuditGroupRefresh
+	if added {
+		action = models.AuditGroupAdd
 	}
+	gc.auditor.Record(r, action, models.AuditTargetGroup, groupID, map[string]string{"domain": group.Domain, "link": req.Link})
 
 	// Fetch wall posts asynchronously
-	go func() {
-		if err := gc.fetchAndSaveWallPosts(parsedGroup); err != nil {
-			log.Printf("Failed to fetch wall posts for group %s: %v\n", parsedGroup.Domain, err)
-		}
-	}()
+	gc.syncService.Enqueue(workspaceID, *group)
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(SuccessResponse{
@@ -101,38 +134,135 @@ func (gc *GroupController) AddGroup(w http.ResponseWriter, r *http.Request, para
 	})
 }
 
-// fetchAndSaveWallPosts fetches wall posts from VK API and saves them to database
-func (gc *GroupController) fetchAndSaveWallPosts(group *models.Group) error {
-	// Delete all existing posts for this group
-	if result := gc.db.Where("group_id = ?", group.ID).Delete(&models.Post{}); result.Error != nil {
-		log.Printf("Failed to delete existing posts for group %s: %v\n", group.Domain, result.Error)
-		return result.Error
+// GetGroupTrend handles GET /api/groups/:id/trend requests.
+// Optional from/to query parameters (YYYY-MM-DD) default to the last 30 days.
+func (gc *GroupController) GetGroupTrend(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	groupID, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid group id"})
+		return
+	}
+
+	now := time.Now().UTC()
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" {
+		from = now.AddDate(0, 0, -29).Format(service.DayLayout)
+	}
+	if to == "" {
+		to = now.Format(service.DayLayout)
+	}
+	if !isValidDay(from) || !isValidDay(to) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Dates must be in YYYY-MM-DD format"})
+		return
+	}
+
+	days, err := gc.dailyStatsService.GetTrend(uint(groupID), from, to)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to load trend"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(TrendResponse{
+		GroupID: uint(groupID),
+		From:    from,
+		To:      to,
+		Days:    days,
+	})
+}
+
+// GetScreenNameHistory handles GET /api/groups/:id/screen-names requests
+func (gc *GroupController) GetScreenNameHistory(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	groupID, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid group id"})
+		return
 	}
 
-	// Fetch wall posts from VK API
-	wallPosts, err := gc.vkService.GetWallPosts(group.Domain, 100)
+	history, err := gc.identityService.ScreenNameHistory(uint(groupID))
 	if err != nil {
-		return err
-	}
-
-	// Convert VK posts to model posts
-	for _, vkPost := range wallPosts {
-		post := models.Post{
-			GroupID:   group.ID,
-			Date:      time.Unix(int64(vkPost.Date), 0).Format("2006-01-02"),
-			Text:      vkPost.Text,
-			Views:     vkPost.Views.Count,
-			Reactions: vkPost.Likes.Count, // Using likes as reactions for now
-			Likes:     vkPost.Likes.Count,
-			Comments:  vkPost.Comments.Count,
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrGroupNotFound) {
+			status = http.StatusNotFound
 		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(history)
+}
 
-		// Save post to database
-		if result := gc.db.Create(&post); result.Error != nil {
-			log.Printf("Failed to save post for group %s: %v\n", group.Domain, result.Error)
-			continue
+// RemoveGroup handles DELETE /api/groups/:id requests, taking a group off the
+// workspace's list; its posts and statistics stay for other workspaces
+func (gc *GroupController) RemoveGroup(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	groupID, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid group id"})
+		return
+	}
+
+	removed, err := gc.workspaceService.Untrack(WorkspaceFromContext(r.Context()).WorkspaceID, uint(groupID))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to remove group"})
+		return
+	}
+	if !removed {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: service.ErrGroupNotFound.Error()})
+		return
+	}
+	gc.auditor.Record(r, models.AuditGroupRemove, models.AuditTargetGroup, uint(groupID), nil)
+
+	json.NewEncoder(w).Encode(SuccessResponse{
+		Message: "Group removed",
+		GroupID: uint(groupID),
+	})
+}
+
+// CancelSync handles DELETE /api/groups/:id/sync requests, aborting a queued or running
+// parse the workspace scheduled
+func (gc *GroupController) CancelSync(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	groupID, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid group id"})
+		return
+	}
+
+	if err := gc.syncService.Cancel(WorkspaceFromContext(r.Context()).WorkspaceID, uint(groupID)); err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, service.ErrSyncOtherWorkspace) {
+			status = http.StatusForbidden
 		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: err.Error()})
+		return
 	}
+	gc.auditor.Record(r, models.AuditGroupSyncCancel, models.AuditTargetGroup, uint(groupID), nil)
+
+	json.NewEncoder(w).Encode(SuccessResponse{
+		Message: "Sync cancelled",
+		GroupID: uint(groupID),
+	})
+}
 
-	return nil
+// isValidDay reports whether s is a date in YYYY-MM-DD format
+func isValidDay(s string) bool {
+	_, err := time.Parse(service.DayLayout, s)
+	return err == nil
 }
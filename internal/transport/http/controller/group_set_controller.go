@@ -0,0 +1,254 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/service"
+	"social-media-analyzer/internal/transport/http/router"
+)
+
+type GroupSetController struct {
+	groupSetService *service.GroupSetService
+	auditor         *Auditor
+}
+
+type GroupSetMember struct {
+	ID     uint   `json:"id"`
+	Domain string `json:"domain"`
+}
+
+type GroupSetResponse struct {
+	ID          uint             `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Groups      []GroupSetMember `json:"groups"`
+}
+
+func NewGroupSetController(groupSetService *service.GroupSetService, auditor *Auditor) *GroupSetController {
+	return &GroupSetController{groupSetService: groupSetService, auditor: auditor}
+}
+
+// ListSets handles GET /api/sets requests
+func (sc *GroupSetController) ListSets(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	sets, err := sc.groupSetService.List(WorkspaceFromContext(r.Context()).WorkspaceID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to load group sets"})
+		return
+	}
+
+	resp := make([]GroupSetResponse, len(sets))
+	for i := range sets {
+		resp[i] = newGroupSetResponse(&sets[i])
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetSet handles GET /api/sets/:id requests
+func (sc *GroupSetController) GetSet(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := setIDParam(w, params)
+	if !ok {
+		return
+	}
+
+	set, err := sc.groupSetService.Get(WorkspaceFromContext(r.Context()).WorkspaceID, id)
+	if err != nil {
+		writeGroupSetError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(newGroupSetResponse(set))
+}
+
+// CreateSet handles POST /api/sets requests
+func (sc *GroupSetController) CreateSet(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var input service.GroupSetInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid request body"})
+		return
+	}
+
+	set, err := sc.groupSetService.Create(WorkspaceFromContext(r.Context()).WorkspaceID, input)
+	if err != nil {
+		writeGroupSetError(w, err)
+		return
+	}
+	sc.auditor.Record(r, models.AuditSetCreate, models.AuditTargetSet, set.ID, map[string]interface{}{
+		"name":      set.Name,
+		"group_ids": setGroupIDs(set),
+	})
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newGroupSetResponse(set))
+}
+
+// UpdateSet handles PUT /api/sets/:id requests
+func (sc *GroupSetController) UpdateSet(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := setIDParam(w, params)
+	if !ok {
+		return
+	}
+
+	var input service.GroupSetInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid request body"})
+		return
+	}
+
+	// The members before the update tell which groups were added and removed
+	before, err := sc.groupSetService.Get(WorkspaceFromContext(r.Context()).WorkspaceID, id)
+	if err != nil {
+		writeGroupSetError(w, err)
+		return
+	}
+
+	set, err := sc.groupSetService.Update(WorkspaceFromContext(r.Context()).WorkspaceID, id, input)
+	if err != nil {
+		writeGroupSetError(w, err)
+		return
+	}
+	sc.auditor.Record(r, models.AuditSetUpdate, models.AuditTargetSet, set.ID, map[string]interface{}{
+		"name":           set.Name,
+		"previous_name":  before.Name,
+		"added_groups":   subtractIDs(setGroupIDs(set), setGroupIDs(before)),
+		"removed_groups": subtractIDs(setGroupIDs(before), setGroupIDs(set)),
+	})
+	json.NewEncoder(w).Encode(newGroupSetResponse(set))
+}
+
+// DeleteSet handles DELETE /api/sets/:id requests
+func (sc *GroupSetController) DeleteSet(w http.ResponseWriter, r *http.Request, params router.Params) {
+	id, ok := setIDParam(w, params)
+	if !ok {
+		return
+	}
+
+	set, err := sc.groupSetService.Get(WorkspaceFromContext(r.Context()).WorkspaceID, id)
+	if err == nil {
+		err = sc.groupSetService.Delete(WorkspaceFromContext(r.Context()).WorkspaceID, id)
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		writeGroupSetError(w, err)
+		return
+	}
+	sc.auditor.Record(r, models.AuditSetDelete, models.AuditTargetSet, set.ID, map[string]interface{}{
+		"name":      set.Name,
+		"group_ids": setGroupIDs(set),
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetBenchmark handles GET /api/sets/:id/benchmark requests.
+// Optional from/to query parameters (YYYY-MM-DD) default to the last 30 days.
+func (sc *GroupSetController) GetBenchmark(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, ok := setIDParam(w, params)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	period := service.Period{
+		From: r.URL.Query().Get("from"),
+		To:   r.URL.Query().Get("to"),
+	}
+	if period.From == "" {
+		period.From = now.AddDate(0, 0, -29).Format(service.DayLayout)
+	}
+	if period.To == "" {
+		period.To = now.Format(service.DayLayout)
+	}
+
+	benchmark, err := sc.groupSetService.Benchmark(WorkspaceFromContext(r.Context()).WorkspaceID, id, period)
+	if err != nil {
+		writeGroupSetError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(benchmark)
+}
+
+func newGroupSetResponse(set *models.GroupSet) GroupSetResponse {
+	resp := GroupSetResponse{
+		ID:          set.ID,
+		Name:        set.Name,
+		Description: set.Description,
+		CreatedAt:   set.CreatedAt,
+		Groups:      make([]GroupSetMember, len(set.Groups)),
+	}
+	for i, group := range set.Groups {
+		resp.Groups[i] = GroupSetMember{ID: group.ID, Domain: group.Domain}
+	}
+	return resp
+}
+
+// setIDParam parses the :id path parameter, writing a 400 response on failure
+func setIDParam(w http.ResponseWriter, params router.Params) (uint, bool) {
+	id, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid set id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeGroupSetError maps group set service errors to HTTP responses
+func writeGroupSetError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	message := "Failed to process group set"
+	switch {
+	case errors.Is(err, service.ErrGroupSetNotFound):
+		status = http.StatusNotFound
+		message = err.Error()
+	case errors.Is(err, service.ErrGroupSetExists):
+		status = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, service.ErrGroupSetInvalid), errors.Is(err, service.ErrInvalidPeriod):
+		status = http.StatusBadRequest
+		message = err.Error()
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Message: message})
+}
+
+// setGroupIDs returns the IDs of a set's members
+func setGroupIDs(set *models.GroupSet) []uint {
+	ids := make([]uint, len(set.Groups))
+	for i, group := range set.Groups {
+		ids[i] = group.ID
+	}
+	return ids
+}
+
+// subtractIDs returns the IDs of a that are not in b
+func subtractIDs(a, b []uint) []uint {
+	exclude := make(map[uint]bool, len(b))
+	for _, id := range b {
+		exclude[id] = true
+	}
+	diff := []uint{}
+	for _, id := range a {
+		if !exclude[id] {
+			diff = append(diff, id)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/service"
+	"social-media-analyzer/internal/transport/http/router"
+)
+
+// maxImportBodySize limits the size of a bulk import request body
+const maxImportBodySize = 1 << 20
+
+type ImportController struct {
+	importService *service.GroupImportService
+	auditor       *Auditor
+}
+
+type BulkAddRequest struct {
+	Links []string `json:"links"`
+}
+
+func NewImportController(importService *service.GroupImportService, auditor *Auditor) *ImportController {
+	return &ImportController{importService: importService, auditor: auditor}
+}
+
+// BulkAddGroups handles POST /api/groups/bulk requests.
+// Accepts a JSON array of links, a {"links": [...]} object, or a multipart
+// upload of a CSV/TXT file in the "file" field.
+func (ic *ImportController) BulkAddGroups(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBodySize)
+
+	links, err := readImportLinks(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: err.Error()})
+		return
+	}
+	if len(links) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "No links provided"})
+		return
+	}
+
+	report, err := ic.importService.Import(r.Context(), WorkspaceFromContext(r.Context()).WorkspaceID, links)
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, service.ErrTooManyLinks) {
+			status = http.StatusBadRequest
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	var added []uint
+	for _, line := range report.Lines {
+		if line.Status == service.ImportAdded {
+			added = append(added, line.GroupID)
+		}
+	}
+	ic.auditor.RecordEach(r, models.AuditGroupAdd, models.AuditTargetGroup, added, map[string]bool{"import": true})
+
+	json.NewEncoder(w).Encode(report)
+}
+
+// readImportLinks extracts numbered links from a JSON or multipart request
+func readImportLinks(r *http.Request) ([]service.ImportLink, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, errors.New("file field is required")
+		}
+		defer file.Close()
+
+		if strings.EqualFold(filepath.Ext(header.Filename), ".csv") {
+			return service.LinksFromCSV(file)
+		}
+		return service.LinksFromText(file)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		return nil, errors.New("Invalid request body")
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		var req BulkAddRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, errors.New("Expected a JSON array of links or {\"links\": [...]}")
+		}
+		list = req.Links
+	}
+	return service.LinksFromList(list), nil
+}
@@ -4,43 +4,73 @@ import (
 	"encoding/json"
 	"html/template"
 	"net/http"
+	"strings"
 
+	"social-media-analyzer/internal/models"
 	"social-media-analyzer/internal/service"
 	"social-media-analyzer/internal/transport/http/router"
 )
 
 type MainController struct {
 	templateDataService *service.TemplateDataService
+	tagService          *service.TagService
+	urls                URLFunc
 }
 
+// URLFunc builds the path of a named route, see router.Router.URL
+type URLFunc func(name string, params ...interface{}) (string, error)
+
 type PageData struct {
-	Groups     []service.TemplateGroupData
-	ChartData  service.ChartDataForTemplate
+	Groups    []service.TemplateGroupData
+	ChartData service.ChartDataForTemplate
+	Tags      []service.TagUsage
+	ActiveTag string
+	// Workspace is the name of the workspace the page shows
+	Workspace string
+	// User and CSRFToken come from the session of the signed-in user
+	User      string
+	CSRFToken string
 }
 
-func NewMainController(templateDataService *service.TemplateDataService) *MainController {
-	return &MainController{templateDataService: templateDataService}
+func NewMainController(templateDataService *service.TemplateDataService, tagService *service.TagService, urls URLFunc) *MainController {
+	return &MainController{templateDataService: templateDataService, tagService: tagService, urls: urls}
 }
 
 // GetMainPage handles GET / requests
 func (mc *MainController) GetMainPage(w http.ResponseWriter, r *http.Request, params router.Params) {
+	workspace := WorkspaceFromContext(r.Context())
+	filter := service.GroupFilter{WorkspaceID: workspace.WorkspaceID, Tag: r.URL.Query().Get("tag")}
+
 	// Prepare group data for template rendering
-	groupData, err := mc.templateDataService.PrepareGroupsForTemplate()
+	groupData, err := mc.templateDataService.PrepareGroupsForTemplate(filter)
 	if err != nil {
 		http.Error(w, "Failed to prepare template data", http.StatusInternalServerError)
 		return
 	}
 
 	// Prepare chart data for template rendering
-	chartData, err := mc.templateDataService.PrepareChartDataForTemplate()
+	chartData, err := mc.templateDataService.PrepareChartDataForTemplate(filter)
 	if err != nil {
 		http.Error(w, "Failed to prepare chart data", http.StatusInternalServerError)
 		return
 	}
 
+	tags, err := mc.tagService.ListTags(workspace.WorkspaceID)
+	if err != nil {
+		http.Error(w, "Failed to load tags", http.StatusInternalServerError)
+		return
+	}
+
 	pageData := PageData{
 		Groups:    groupData,
 		ChartData: chartData,
+		Tags:      tags,
+		ActiveTag: service.NormalizeTag(filter.Tag),
+		Workspace: workspace.Workspace.Name,
+	}
+	if session := SessionFromContext(r.Context()); session != nil {
+		pageData.User = session.User.Username
+		pageData.CSRFToken = session.CSRFToken
 	}
 
 	funcMap := template.FuncMap{
@@ -48,8 +78,24 @@ func (mc *MainController) GetMainPage(w http.ResponseWriter, r *http.Request, pa
 			b, _ := json.Marshal(v)
 			return template.JS(b)
 		},
+		"join":        strings.Join,
+		"statusLabel": statusLabel,
+		"url":         mc.urls,
 	}
 
 	tpl := template.Must(template.New("main.html").Funcs(funcMap).ParseFiles("web/templates/main.html"))
 	tpl.Execute(w, pageData)
 }
+
+// groupStatusLabels are the UI labels of group statuses other than active
+var groupStatusLabels = map[string]string{
+	models.GroupStatusClosed:       "Закрытое",
+	models.GroupStatusBanned:       "Заблокировано",
+	models.GroupStatusDeleted:      "Удалено",
+	models.GroupStatusWallDisabled: "Стена отключена",
+}
+
+// statusLabel returns the badge text of a group status, empty for active groups
+func statusLabel(status string) string {
+	return groupStatusLabels[status]
+}
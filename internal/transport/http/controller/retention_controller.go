@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/service"
+	"social-media-analyzer/internal/transport/http/router"
+)
+
+type RetentionController struct {
+	retentionService *service.RetentionService
+}
+
+// RetentionPolicyResponse lists the retention periods in days; 0 keeps data forever
+type RetentionPolicyResponse struct {
+	PostDays         int    `json:"post_days"`
+	DailyStatsDays   int    `json:"daily_stats_days"`
+	DeletedGroupDays int    `json:"deleted_group_days"`
+	Interval         string `json:"interval"`
+}
+
+// RetentionResponse shows the policy, what a run would remove now and what
+// the last run removed, both limited to the groups the workspace tracks.
+// Preview is only built for editors and owners and is empty for viewers.
+type RetentionResponse struct {
+	Policy  RetentionPolicyResponse  `json:"policy"`
+	Preview *service.RetentionReport `json:"preview"`
+	LastRun *service.RetentionReport `json:"last_run"`
+}
+
+func NewRetentionController(retentionService *service.RetentionService) *RetentionController {
+	return &RetentionController{retentionService: retentionService}
+}
+
+// GetRetention handles GET /api/retention requests with a dry-run report.
+// Deletion only happens in the background job or through the retention command.
+func (rc *RetentionController) GetRetention(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// The dry run counts every post and rollup of the workspace's groups, so
+	// viewers only get the policy and the last run
+	member := WorkspaceFromContext(r.Context())
+	var preview *service.RetentionReport
+	if member.Can(models.WorkspaceRoleEditor) {
+		var err error
+		preview, err = rc.retentionService.Preview(r.Context(), member.WorkspaceID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to build retention report"})
+			return
+		}
+	}
+
+	policy := rc.retentionService.Policy()
+	json.NewEncoder(w).Encode(RetentionResponse{
+		Policy: RetentionPolicyResponse{
+			PostDays:         policy.PostDays,
+			DailyStatsDays:   policy.DailyStatsDays,
+			DeletedGroupDays: policy.DeletedGroupDays,
+			Interval:         policy.Interval.String(),
+		},
+		Preview: preview,
+		LastRun: rc.retentionService.LastRunFor(member.WorkspaceID),
+	})
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/service"
+)
+
+// TestRetentionPreviewRoles tests that the dry run is only built for editors
+// and owners, while viewers still get the policy
+func TestRetentionPreviewRoles(t *testing.T) {
+	app := newTestApp(t)
+	owner, workspaceID := app.newUser(t, "anna", "Acme")
+	editor := app.newMember(t, workspaceID, "boris", models.WorkspaceRoleEditor)
+	viewer := app.newMember(t, workspaceID, "carl", models.WorkspaceRoleViewer)
+
+	for name, tt := range map[string]struct {
+		login   *service.LoginSession
+		preview bool
+	}{
+		"owner":  {owner, true},
+		"editor": {editor, true},
+		"viewer": {viewer, false},
+	} {
+		rec := app.serveSession(http.MethodGet, "/api/retention", tt.login)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", name, rec.Code)
+		}
+		var resp RetentionResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if (resp.Preview != nil) != tt.preview || resp.Policy.Interval == "" {
+			t.Errorf("%s: expected a preview %v, got %+v", name, tt.preview, resp)
+		}
+	}
+}
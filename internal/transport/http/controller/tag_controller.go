@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/service"
+	"social-media-analyzer/internal/transport/http/router"
+)
+
+type TagController struct {
+	tagService *service.TagService
+	auditor    *Auditor
+}
+
+type SetTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+type SetTagsResponse struct {
+	GroupID uint     `json:"group_id"`
+	Tags    []string `json:"tags"`
+}
+
+type SetNotesRequest struct {
+	Notes string `json:"notes"`
+}
+
+func NewTagController(tagService *service.TagService, auditor *Auditor) *TagController {
+	return &TagController{tagService: tagService, auditor: auditor}
+}
+
+// ListTags handles GET /api/tags requests
+func (tc *TagController) ListTags(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tags, err := tc.tagService.ListTags(WorkspaceFromContext(r.Context()).WorkspaceID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to load tags"})
+		return
+	}
+	json.NewEncoder(w).Encode(tags)
+}
+
+// SetGroupTags handles PUT /api/groups/:id/tags requests
+func (tc *TagController) SetGroupTags(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	groupID, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid group id"})
+		return
+	}
+
+	var req SetTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid request body"})
+		return
+	}
+
+	tags, err := tc.tagService.SetGroupTags(WorkspaceFromContext(r.Context()).WorkspaceID, uint(groupID), req.Tags)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+	tc.auditor.Record(r, models.AuditGroupTags, models.AuditTargetGroup, uint(groupID), map[string][]string{"tags": tags})
+	json.NewEncoder(w).Encode(SetTagsResponse{GroupID: uint(groupID), Tags: tags})
+}
+
+// SetGroupNotes handles PUT /api/groups/:id/notes requests
+func (tc *TagController) SetGroupNotes(w http.ResponseWriter, r *http.Request, params router.Params) {
+	groupID, err := strconv.ParseUint(params["id"], 10, 64)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid group id"})
+		return
+	}
+
+	var req SetNotesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid request body"})
+		return
+	}
+
+	if err := tc.tagService.SetGroupNotes(WorkspaceFromContext(r.Context()).WorkspaceID, uint(groupID), req.Notes); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		writeTagError(w, err)
+		return
+	}
+	tc.auditor.Record(r, models.AuditGroupNotes, models.AuditTargetGroup, uint(groupID), map[string]string{"notes": req.Notes})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BulkTag handles POST /api/tags/bulk requests
+func (tc *TagController) BulkTag(w http.ResponseWriter, r *http.Request, params router.Params) {
+	var input service.BulkTagInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid request body"})
+		return
+	}
+
+	if err := tc.tagService.BulkTag(WorkspaceFromContext(r.Context()).WorkspaceID, input); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		writeTagError(w, err)
+		return
+	}
+	tc.auditor.RecordEach(r, models.AuditGroupTags, models.AuditTargetGroup, input.GroupIDs, map[string][]string{"add": input.Add, "remove": input.Remove})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeTagError maps tag service errors to HTTP responses
+func writeTagError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	message := "Failed to update tags"
+	switch {
+	case errors.Is(err, service.ErrGroupNotFound):
+		status = http.StatusNotFound
+		message = err.Error()
+	case errors.Is(err, service.ErrInvalidTag):
+		status = http.StatusBadRequest
+		message = err.Error()
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Message: message})
+}
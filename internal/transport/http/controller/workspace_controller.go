@@ -0,0 +1,419 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/service"
+	"social-media-analyzer/internal/transport/http/router"
+)
+
+type workspaceContextKey struct{}
+
+type WorkspaceController struct {
+	workspaceService *service.WorkspaceService
+	auditor          *Auditor
+	urls             URLFunc
+}
+
+type WorkspaceResponse struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	Role    string `json:"role"`
+	Current bool   `json:"current"`
+}
+
+type MemberResponse struct {
+	UserID   uint      `json:"user_id"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+type AddMemberRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type WorkspacesPageData struct {
+	Workspaces []WorkspaceResponse
+	Current    *WorkspaceResponse
+	Members    []MemberResponse
+	Roles      []string
+	CanManage  bool
+	User       string
+	CSRFToken  string
+	Error      string
+}
+
+func NewWorkspaceController(workspaceService *service.WorkspaceService, auditor *Auditor, urls URLFunc) *WorkspaceController {
+	return &WorkspaceController{workspaceService: workspaceService, auditor: auditor, urls: urls}
+}
+
+// WorkspaceFromContext returns the signed-in user's membership in the
+// workspace the request works in, set by the workspace middlewares
+func WorkspaceFromContext(ctx context.Context) *models.WorkspaceMember {
+	member, _ := ctx.Value(workspaceContextKey{}).(*models.WorkspaceMember)
+	return member
+}
+
+// RequireWorkspace is the router middleware for the data API behind
+// RequireAPILogin. It resolves the workspace of the request: the one an API
+// key is bound to or the one the session works in. Viewers may read, writing
+// needs the editor role.
+func (wc *WorkspaceController) RequireWorkspace() router.MiddlewareFunc {
+	return wc.requireWorkspace(func(r *http.Request) string {
+		if safeMethod(r.Method) {
+			return models.WorkspaceRoleViewer
+		}
+		return models.WorkspaceRoleEditor
+	})
+}
+
+// RequireWorkspaceRole is a per-route middleware for routes that need a
+// particular role regardless of the method, such as managing members
+func (wc *WorkspaceController) RequireWorkspaceRole(role string) router.MiddlewareFunc {
+	return wc.requireWorkspace(func(*http.Request) string { return role })
+}
+
+// RequireWorkspacePage is the middleware for pages behind RequirePageLogin;
+// users without a workspace are sent to the workspaces page to create one
+func (wc *WorkspaceController) RequireWorkspacePage() router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+			member, err := wc.resolve(r)
+			if errors.Is(err, service.ErrNoWorkspace) || errors.Is(err, service.ErrWorkspaceNotFound) {
+				workspacesURL, _ := wc.urls("workspaces")
+				http.Redirect(w, r, workspacesURL, http.StatusSeeOther)
+				return
+			}
+			if err != nil {
+				log.Printf("Workspace lookup failed: %v\n", err)
+				http.Error(w, "Failed to load workspace", http.StatusInternalServerError)
+				return
+			}
+			next(w, r.WithContext(context.WithValue(r.Context(), workspaceContextKey{}, member)), params)
+		}
+	}
+}
+
+// RequireTrackedGroup is a per-route middleware for /groups/:id routes behind
+// RequireWorkspace; groups the workspace does not track are reported as not found
+func (wc *WorkspaceController) RequireTrackedGroup() router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+			groupID, err := strconv.ParseUint(params["id"], 10, 64)
+			if err != nil {
+				writeAuthError(w, http.StatusBadRequest, "Invalid group id")
+				return
+			}
+			tracked, err := wc.workspaceService.IsTracking(WorkspaceFromContext(r.Context()).WorkspaceID, uint(groupID))
+			if err != nil {
+				log.Printf("Workspace group lookup failed: %v\n", err)
+				writeAuthError(w, http.StatusInternalServerError, "Failed to check group")
+				return
+			}
+			if !tracked {
+				writeAuthError(w, http.StatusNotFound, service.ErrGroupNotFound.Error())
+				return
+			}
+			next(w, r, params)
+		}
+	}
+}
+
+// ListWorkspaces handles GET /api/workspaces requests for the signed-in user's workspaces
+func (wc *WorkspaceController) ListWorkspaces(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	workspaces, _, err := wc.listWorkspaces(r)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to load workspaces"})
+		return
+	}
+	json.NewEncoder(w).Encode(workspaces)
+}
+
+// CreateWorkspace handles POST /api/workspaces requests ({"name"}); the
+// signed-in user becomes its owner
+func (wc *WorkspaceController) CreateWorkspace(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req CreateWorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid request body"})
+		return
+	}
+
+	workspace, err := wc.workspaceService.Create(UserFromContext(r.Context()).ID, req.Name)
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+	wc.auditor.RecordIn(r, workspace.ID, models.AuditWorkspaceCreate, models.AuditTargetWorkspace, workspace.ID, map[string]string{"name": workspace.Name})
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(WorkspaceResponse{ID: workspace.ID, Name: workspace.Name, Role: models.WorkspaceRoleOwner})
+}
+
+// SelectWorkspace handles POST /workspaces/select form submissions that
+// switch the session to another workspace
+func (wc *WorkspaceController) SelectWorkspace(w http.ResponseWriter, r *http.Request, params router.Params) {
+	id, err := strconv.ParseUint(r.PostFormValue("workspace_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid workspace id", http.StatusBadRequest)
+		return
+	}
+
+	err = wc.workspaceService.Select(SessionFromContext(r.Context()), uint(id))
+	if errors.Is(err, service.ErrWorkspaceNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to switch workspace: %v\n", err)
+		http.Error(w, "Failed to switch workspace", http.StatusInternalServerError)
+		return
+	}
+
+	mainURL, _ := wc.urls("main")
+	http.Redirect(w, r, mainURL, http.StatusSeeOther)
+}
+
+// ListMembers handles GET /api/workspace/members requests
+func (wc *WorkspaceController) ListMembers(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	members, err := wc.workspaceService.Members(WorkspaceFromContext(r.Context()).WorkspaceID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Failed to load members"})
+		return
+	}
+	json.NewEncoder(w).Encode(newMemberResponses(members))
+}
+
+// AddMember handles POST /api/workspace/members requests ({"username", "role"})
+func (wc *WorkspaceController) AddMember(w http.ResponseWriter, r *http.Request, params router.Params) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req AddMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid request body"})
+		return
+	}
+
+	member, err := wc.workspaceService.AddMember(WorkspaceFromContext(r.Context()).WorkspaceID, req.Username, req.Role)
+	if err != nil {
+		writeWorkspaceError(w, err)
+		return
+	}
+	wc.auditor.Record(r, models.AuditMemberAdd, models.AuditTargetUser, member.UserID, map[string]string{
+		"username": member.User.Username,
+		"role":     member.Role,
+	})
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newMemberResponse(member))
+}
+
+// SetMemberRole handles PUT /api/workspace/members/:user_id requests ({"role"})
+func (wc *WorkspaceController) SetMemberRole(w http.ResponseWriter, r *http.Request, params router.Params) {
+	userID, err := strconv.ParseUint(params["user_id"], 10, 64)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid user id"})
+		return
+	}
+
+	var req SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid request body"})
+		return
+	}
+
+	if err := wc.workspaceService.SetRole(WorkspaceFromContext(r.Context()).WorkspaceID, uint(userID), req.Role); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		writeWorkspaceError(w, err)
+		return
+	}
+	wc.auditor.Record(r, models.AuditMemberRole, models.AuditTargetUser, uint(userID), map[string]string{"role": req.Role})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveMember handles DELETE /api/workspace/members/:user_id requests
+func (wc *WorkspaceController) RemoveMember(w http.ResponseWriter, r *http.Request, params router.Params) {
+	userID, err := strconv.ParseUint(params["user_id"], 10, 64)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Message: "Invalid user id"})
+		return
+	}
+
+	if err := wc.workspaceService.RemoveMember(WorkspaceFromContext(r.Context()).WorkspaceID, uint(userID)); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		writeWorkspaceError(w, err)
+		return
+	}
+	wc.auditor.Record(r, models.AuditMemberRemove, models.AuditTargetUser, uint(userID), nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWorkspacesPage handles GET /workspaces requests
+func (wc *WorkspaceController) GetWorkspacesPage(w http.ResponseWriter, r *http.Request, params router.Params) {
+	session := SessionFromContext(r.Context())
+	pageData := WorkspacesPageData{
+		Roles:     []string{models.WorkspaceRoleViewer, models.WorkspaceRoleEditor, models.WorkspaceRoleOwner},
+		User:      session.User.Username,
+		CSRFToken: session.CSRFToken,
+	}
+
+	workspaces, current, err := wc.listWorkspaces(r)
+	if err == nil && current != nil {
+		pageData.Current = &WorkspaceResponse{ID: current.WorkspaceID, Name: current.Workspace.Name, Role: current.Role, Current: true}
+		pageData.CanManage = current.Can(models.WorkspaceRoleOwner)
+		var members []models.WorkspaceMember
+		members, err = wc.workspaceService.Members(current.WorkspaceID)
+		pageData.Members = newMemberResponses(members)
+	}
+	if err != nil {
+		log.Printf("Failed to load workspaces: %v\n", err)
+		pageData.Error = "Не удалось загрузить рабочие пространства"
+	}
+	pageData.Workspaces = workspaces
+
+	funcMap := template.FuncMap{
+		"url": wc.urls,
+	}
+
+	tpl := template.Must(template.New("workspaces.html").Funcs(funcMap).ParseFiles("web/templates/workspaces.html"))
+	tpl.Execute(w, pageData)
+}
+
+// resolve returns the membership of the request's user in the workspace the
+// request works in
+func (wc *WorkspaceController) resolve(r *http.Request) (*models.WorkspaceMember, error) {
+	if apiKey := APIKeyFromContext(r.Context()); apiKey != nil {
+		return wc.workspaceService.Membership(apiKey.WorkspaceID, apiKey.UserID)
+	}
+	if session := SessionFromContext(r.Context()); session != nil {
+		return wc.workspaceService.Current(session)
+	}
+	return nil, service.ErrNoWorkspace
+}
+
+// requireWorkspace puts the request's membership into the context and checks
+// that its role covers the one the request needs
+func (wc *WorkspaceController) requireWorkspace(required func(*http.Request) string) router.MiddlewareFunc {
+	return func(next router.HandlerFunc) router.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+			member := WorkspaceFromContext(r.Context())
+			if member == nil {
+				var err error
+				member, err = wc.resolve(r)
+				if errors.Is(err, service.ErrNoWorkspace) || errors.Is(err, service.ErrWorkspaceNotFound) {
+					writeAuthError(w, http.StatusForbidden, "No access to a workspace, create one or ask an owner to add you")
+					return
+				}
+				if err != nil {
+					log.Printf("Workspace lookup failed: %v\n", err)
+					writeAuthError(w, http.StatusInternalServerError, "Failed to load workspace")
+					return
+				}
+				r = r.WithContext(context.WithValue(r.Context(), workspaceContextKey{}, member))
+			}
+
+			if role := required(r); !member.Can(role) {
+				writeAuthError(w, http.StatusForbidden, "Your role in this workspace does not allow this request, "+role+" is required")
+				return
+			}
+			next(w, r, params)
+		}
+	}
+}
+
+// listWorkspaces returns the user's workspaces and the membership the request works in, if any
+func (wc *WorkspaceController) listWorkspaces(r *http.Request) ([]WorkspaceResponse, *models.WorkspaceMember, error) {
+	memberships, err := wc.workspaceService.ListForUser(UserFromContext(r.Context()).ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := wc.resolve(r)
+	if errors.Is(err, service.ErrNoWorkspace) || errors.Is(err, service.ErrWorkspaceNotFound) {
+		current, err = nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	workspaces := make([]WorkspaceResponse, len(memberships))
+	for i, member := range memberships {
+		workspaces[i] = WorkspaceResponse{
+			ID:      member.WorkspaceID,
+			Name:    member.Workspace.Name,
+			Role:    member.Role,
+			Current: current != nil && current.WorkspaceID == member.WorkspaceID,
+		}
+	}
+	return workspaces, current, nil
+}
+
+func newMemberResponse(member *models.WorkspaceMember) MemberResponse {
+	return MemberResponse{
+		UserID:   member.UserID,
+		Username: member.User.Username,
+		Role:     member.Role,
+		AddedAt:  member.CreatedAt,
+	}
+}
+
+func newMemberResponses(members []models.WorkspaceMember) []MemberResponse {
+	resp := make([]MemberResponse, len(members))
+	for i := range members {
+		resp[i] = newMemberResponse(&members[i])
+	}
+	return resp
+}
+
+// writeWorkspaceError maps workspace service errors to HTTP responses
+func writeWorkspaceError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	message := "Failed to update workspace"
+	switch {
+	case errors.Is(err, service.ErrMemberNotFound), errors.Is(err, service.ErrUserNotFound):
+		status = http.StatusNotFound
+		message = err.Error()
+	case errors.Is(err, service.ErrWorkspaceInvalid):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case errors.Is(err, service.ErrMemberExists), errors.Is(err, service.ErrLastOwner):
+		status = http.StatusConflict
+		message = err.Error()
+	default:
+		log.Printf("Workspace request failed: %v\n", err)
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Message: message})
+}
@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"social-media-analyzer/internal/models"
+	"social-media-analyzer/internal/service"
+)
+
+// newMember adds a new signed-in user to a workspace with a role
+func (app *testApp) newMember(t *testing.T, workspaceID uint, username, role string) *service.LoginSession {
+	t.Helper()
+	login, _ := app.newUser(t, username, "")
+	if _, err := app.services.WorkspaceService.AddMember(workspaceID, username, role); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return login
+}
+
+// serveSession sends a request signed in with a session and its CSRF token
+func (app *testApp) serveSession(method, target string, login *service.LoginSession) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(`{}`))
+	req.Header.Set(CSRFHeader, login.Session.CSRFToken)
+	return app.serve(req, login)
+}
+
+// TestViewerCannotWrite tests that viewers read the workspace's data but get
+// 403 on requests that change it
+func TestViewerCannotWrite(t *testing.T) {
+	app := newTestApp(t)
+	_, workspaceID := app.newUser(t, "anna", "Acme")
+	viewer := app.newMember(t, workspaceID, "boris", models.WorkspaceRoleViewer)
+
+	if rec := app.serveSession(http.MethodGet, "/api/groups", viewer); rec.Code != http.StatusOK {
+		t.Errorf("Expected a viewer to read, got %d", rec.Code)
+	}
+	if rec := app.serveSession(http.MethodPost, "/api/groups", viewer); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a viewer's POST, got %d", rec.Code)
+	}
+
+	// A write key does not get more than its owner's role
+	key := app.newKey(t, viewer.Session.UserID, workspaceID, models.APIKeyScopeWrite)
+	if rec := app.serveKey(http.MethodPost, "/api/groups", key.Key); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a viewer's write key, got %d", rec.Code)
+	}
+}
+
+// TestGroupOfOtherWorkspace tests that /groups/:id routes do not reach groups
+// only another workspace tracks, and that a sync is only cancelled by a
+// workspace that scheduled it
+func TestGroupOfOtherWorkspace(t *testing.T) {
+	app := newTestApp(t)
+	anna, annaWorkspace := app.newUser(t, "anna", "Acme")
+	boris, borisWorkspace := app.newUser(t, "boris", "Other")
+	group := models.Group{Domain: "shared"}
+	if err := app.db.Create(&group).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := app.services.WorkspaceService.Track(borisWorkspace, group.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	trend := fmt.Sprintf("/api/groups/%d/trend", group.ID)
+	sync := fmt.Sprintf("/api/groups/%d/sync", group.ID)
+	if rec := app.serveSession(http.MethodGet, trend, anna); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a group of another workspace, got %d", rec.Code)
+	}
+	if rec := app.serveSession(http.MethodGet, trend, boris); rec.Code != http.StatusOK {
+		t.Errorf("Expected the tracking workspace to read the group, got %d", rec.Code)
+	}
+
+	if !app.services.SyncService.Enqueue(borisWorkspace, group) {
+		t.Fatal("Expected group to be enqueued")
+	}
+	if rec := app.serveSession(http.MethodDelete, sync, anna); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 cancelling a sync of an untracked group, got %d", rec.Code)
+	}
+	if _, err := app.services.WorkspaceService.Track(annaWorkspace, group.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec := app.serveSession(http.MethodDelete, sync, anna); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 cancelling a sync another workspace scheduled, got %d", rec.Code)
+	}
+	if rec := app.serveSession(http.MethodDelete, sync, boris); rec.Code != http.StatusOK {
+		t.Errorf("Expected the scheduling workspace to cancel the sync, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+// TestRemoveGroup tests that editors take a group off the workspace's list and
+// that the removal is recorded in the audit log
+func TestRemoveGroup(t *testing.T) {
+	app := newTestApp(t)
+	anna, workspaceID := app.newUser(t, "anna", "Acme")
+	viewer := app.newMember(t, workspaceID, "boris", models.WorkspaceRoleViewer)
+	group := models.Group{Domain: "competitor"}
+	if err := app.db.Create(&group).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := app.services.WorkspaceService.Track(workspaceID, group.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	target := fmt.Sprintf("/api/groups/%d", group.ID)
+	if rec := app.serveSession(http.MethodDelete, target, viewer); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a viewer, got %d", rec.Code)
+	}
+	if rec := app.serveSession(http.MethodDelete, target, anna); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the group to be removed, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := app.serveSession(http.MethodDelete, target, anna); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 once the group is not tracked, got %d", rec.Code)
+	}
+
+	events, err := app.services.AuditService.List(service.AuditFilter{WorkspaceID: workspaceID, Action: models.AuditGroupRemove})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(events) != 1 || events[0].Username != "anna" || events[0].TargetID != fmt.Sprint(group.ID) {
+		t.Errorf("Expected one removal by anna, got %+v", events)
+	}
+}
+
+// TestRemovedMemberKey tests that keys stop working once their owner leaves the workspace
+func TestRemovedMemberKey(t *testing.T) {
+	app := newTestApp(t)
+	_, workspaceID := app.newUser(t, "anna", "Acme")
+	boris := app.newMember(t, workspaceID, "boris", models.WorkspaceRoleEditor)
+	key := app.newKey(t, boris.Session.UserID, workspaceID, models.APIKeyScopeWrite)
+
+	if rec := app.serveKey(http.MethodGet, "/api/groups", key.Key); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the member's key to work, got %d", rec.Code)
+	}
+	if err := app.services.WorkspaceService.RemoveMember(workspaceID, boris.Session.UserID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec := app.serveKey(http.MethodGet, "/api/groups", key.Key); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for the key of a removed member, got %d", rec.Code)
+	}
+
+	// A key whose membership is gone is rejected even if the key itself is left
+	carl := app.newMember(t, workspaceID, "carl", models.WorkspaceRoleEditor)
+	key = app.newKey(t, carl.Session.UserID, workspaceID, models.APIKeyScopeWrite)
+	if err := app.db.Where("user_id = ?", carl.Session.UserID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec := app.serveKey(http.MethodGet, "/api/groups", key.Key); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a key without a membership, got %d", rec.Code)
+	}
+}
+
+// TestAuditAccess tests that the audit log needs the owner role and, for keys,
+// the admin scope
+func TestAuditAccess(t *testing.T) {
+	app := newTestApp(t)
+	anna, workspaceID := app.newUser(t, "anna", "Acme")
+	boris := app.newMember(t, workspaceID, "boris", models.WorkspaceRoleEditor)
+
+	tests := []struct {
+		name   string
+		serve  func() *httptest.ResponseRecorder
+		status int
+	}{
+		{"owner session", func() *httptest.ResponseRecorder {
+			return app.serveSession(http.MethodGet, "/api/audit", anna)
+		}, http.StatusOK},
+		{"owner admin key", func() *httptest.ResponseRecorder {
+			return app.serveKey(http.MethodGet, "/api/audit", app.newKey(t, anna.Session.UserID, workspaceID, models.APIKeyScopeAdmin).Key)
+		}, http.StatusOK},
+		{"owner write key", func() *httptest.ResponseRecorder {
+			return app.serveKey(http.MethodGet, "/api/audit", app.newKey(t, anna.Session.UserID, workspaceID, models.APIKeyScopeWrite).Key)
+		}, http.StatusForbidden},
+		{"editor session", func() *httptest.ResponseRecorder {
+			return app.serveSession(http.MethodGet, "/api/audit", boris)
+		}, http.StatusForbidden},
+		{"editor admin key", func() *httptest.ResponseRecorder {
+			return app.serveKey(http.MethodGet, "/api/audit", app.newKey(t, boris.Session.UserID, workspaceID, models.APIKeyScopeAdmin).Key)
+		}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if rec := tt.serve(); rec.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.status, rec.Code, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package router
+
+import (
+	"context"
+	"net/http"
+)
+
+const paramsKey contextKey = iota + 1
+
+// ParamsFromContext возвращает параметры пути, которые роутер кладёт в
+// контекст запроса. Нужен обработчикам в стиле net/http; они также могут
+// читать параметры через r.PathValue. Карта действительна до конца запроса.
+func ParamsFromContext(ctx context.Context) Params {
+	params, _ := ctx.Value(paramsKey).(Params)
+	return params
+}
+
+// ServeHTTP делает HandlerFunc обычным http.Handler: параметры берутся из
+// контекста запроса. Так обработчик роутера можно отдать стандартному middleware.
+func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h(w, r, ParamsFromContext(r.Context()))
+}
+
+// FromHandler превращает http.Handler в HandlerFunc. Параметры пути
+// обработчик получит через r.PathValue или ParamsFromContext.
+func FromHandler(h http.Handler) HandlerFunc {
+	if hf, ok := h.(HandlerFunc); ok {
+		return hf
+	}
+	return func(w http.ResponseWriter, r *http.Request, params Params) {
+		h.ServeHTTP(w, withParams(r, params))
+	}
+}
+
+// FromMiddleware превращает стандартный middleware вида
+// func(http.Handler) http.Handler в MiddlewareFunc. Если middleware подменяет
+// запрос, например добавляет значения в контекст, дальше уходит новый запрос.
+func FromMiddleware(m func(http.Handler) http.Handler) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		h := m(next)
+		return func(w http.ResponseWriter, r *http.Request, params Params) {
+			h.ServeHTTP(w, withParams(r, params))
+		}
+	}
+}
+
+// withParams кладёт параметры в контекст и в PathValue запроса, если их там
+// ещё нет.
+func withParams(r *http.Request, params Params) *http.Request {
+	if len(params) == 0 || ParamsFromContext(r.Context()) != nil {
+		return r
+	}
+	r = r.WithContext(context.WithValue(r.Context(), paramsKey, params))
+	for name, value := range params {
+		r.SetPathValue(name, value)
+	}
+	return r
+}
@@ -0,0 +1,56 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+type ctxKey string
+
+// TestStandardMiddleware tests plugging func(http.Handler) http.Handler middleware into the router, groups and routes
+func TestStandardMiddleware(t *testing.T) {
+	withUser := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trace", "std")
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKey("user"), "alice")))
+		})
+	}
+
+	rt := New()
+	rt.Use(tag("global"))
+	rt.UseHTTP(withUser)
+	api := rt.Group("/api")
+	api.UseHTTP(withUser)
+	api.GET("/groups/:id", func(w http.ResponseWriter, r *http.Request, params Params) {
+		user, _ := r.Context().Value(ctxKey("user")).(string)
+		w.Write([]byte(user + " " + params["id"] + " " + r.PathValue("id")))
+	}, FromMiddleware(withUser))
+
+	rec := serve(rt, "GET", "/api/groups/7")
+	if rec.Body.String() != "alice 7 7" || trace(rec) != "global,std,std,std" {
+		t.Errorf("Unexpected response %q with trace %q", rec.Body.String(), trace(rec))
+	}
+}
+
+// TestHandlerAdapters tests plain http.Handlers as routes and router handlers as http.Handlers
+func TestHandlerAdapters(t *testing.T) {
+	rt := New()
+	rt.GET("/std/:id/*rest", FromHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := ParamsFromContext(r.Context())
+		w.Write([]byte(r.PathValue("id") + " " + r.PathValue("rest") + " " + params["id"] + " " + r.Pattern))
+	})))
+
+	if rec := serve(rt, "GET", "/std/5/a/b"); rec.Body.String() != "5 a/b 5 /std/:id/*rest" {
+		t.Errorf("Unexpected response %q", rec.Body.String())
+	}
+
+	// HandlerFunc is an http.Handler reading its parameters from the context
+	var h http.Handler = HandlerFunc(func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.Write([]byte(params["id"]))
+	})
+	rt.GET("/wrapped/:id", FromHandler(h))
+	if rec := serve(rt, "GET", "/wrapped/9"); rec.Body.String() != "9" {
+		t.Errorf("Unexpected response %q", rec.Body.String())
+	}
+}
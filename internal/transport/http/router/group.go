@@ -0,0 +1,84 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Group — набор маршрутов с общим префиксом и своими middleware, например
+// r.Group("/api"). Middleware группы выполняются после глобальных из
+// Router.Use и до middleware конкретного маршрута. На 404 и 405 внутри
+// префикса они не действуют: такие запросы не доходят ни до одной группы.
+type Group struct {
+	router      *Router
+	parent      *Group
+	prefix      string
+	middlewares []MiddlewareFunc
+}
+
+// Group создаёт группу маршрутов под prefix.
+func (rt *Router) Group(prefix string, mws ...MiddlewareFunc) *Group {
+	return &Group{router: rt, prefix: cleanPrefix(prefix), middlewares: mws}
+}
+
+// Group создаёт вложенную группу: её префикс дописывается к префиксу
+// родителя, а middleware выполняются после родительских.
+func (g *Group) Group(prefix string, mws ...MiddlewareFunc) *Group {
+	return &Group{router: g.router, parent: g, prefix: g.prefix + cleanPrefix(prefix), middlewares: mws}
+}
+
+// Use добавляет middleware группы. Цепочка маршрута собирается один раз при
+// регистрации, поэтому Use группы и её родителей вызывают до регистрации
+// маршрутов: на уже зарегистрированные маршруты middleware не действуют.
+func (g *Group) Use(mws ...MiddlewareFunc) {
+	g.middlewares = append(g.middlewares, mws...)
+}
+
+// UseHTTP добавляет группе стандартные middleware вида
+// func(http.Handler) http.Handler, как Use, и тоже до регистрации маршрутов.
+func (g *Group) UseHTTP(mws ...func(http.Handler) http.Handler) {
+	for _, m := range mws {
+		g.middlewares = append(g.middlewares, FromMiddleware(m))
+	}
+}
+
+// Handle регистрирует обработчик для метода и пути относительно префикса группы.
+func (g *Group) Handle(method, path string, h HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return g.router.Handle(method, g.prefix+path, g.wrap(chain(h, mws)))
+}
+
+func (g *Group) GET(path string, h HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return g.Handle("GET", path, h, mws...)
+}
+func (g *Group) POST(path string, h HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return g.Handle("POST", path, h, mws...)
+}
+func (g *Group) PUT(path string, h HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return g.Handle("PUT", path, h, mws...)
+}
+func (g *Group) DELETE(path string, h HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return g.Handle("DELETE", path, h, mws...)
+}
+
+// Mount подключает http.Handler под prefix относительно префикса группы.
+func (g *Group) Mount(prefix string, h http.Handler, mws ...MiddlewareFunc) *Route {
+	return g.router.Mount(g.prefix+cleanPrefix(prefix), h, append([]MiddlewareFunc{g.wrap}, mws...)...)
+}
+
+// wrap оборачивает h в middleware группы и всех её родителей; вызывается
+// при регистрации маршрута, а не на каждый запрос.
+func (g *Group) wrap(h HandlerFunc) HandlerFunc {
+	for cur := g; cur != nil; cur = cur.parent {
+		h = chain(h, cur.middlewares)
+	}
+	return h
+}
+
+// cleanPrefix приводит префикс к виду "/api": со слешем в начале и без слеша в конце.
+func cleanPrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return "/" + prefix
+}
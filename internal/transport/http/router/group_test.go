@@ -0,0 +1,88 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// tag returns a middleware that appends name to the X-Trace response header
+func tag(name string) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, params Params) {
+			w.Header().Add("X-Trace", name)
+			next(w, r, params)
+		}
+	}
+}
+
+func serve(rt *Router, method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func trace(rec *httptest.ResponseRecorder) string {
+	return strings.Join(rec.Header().Values("X-Trace"), ",")
+}
+
+// TestGroupMiddlewareOrder tests that global, group, nested group and route middlewares run in that order
+func TestGroupMiddlewareOrder(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.Write([]byte(params["id"]))
+	}
+
+	rt := New()
+	rt.Use(tag("global"))
+	api := rt.Group("/api/", tag("api"))
+	admin := api.Group("admin")
+	admin.Use(tag("admin"))
+	admin.GET("/users/:id", ok, tag("route"))
+	api.GET("/items", ok)
+	rt.GET("/", ok)
+	// Group middlewares are bound when a route is registered
+	admin.GET("/late", ok)
+	admin.Use(tag("late"))
+
+	rec := serve(rt, "GET", "/api/admin/users/7")
+	if rec.Body.String() != "7" || trace(rec) != "global,api,admin,route" {
+		t.Errorf("Unexpected response %q with trace %q", rec.Body.String(), trace(rec))
+	}
+	if rec := serve(rt, "GET", "/api/admin/late"); trace(rec) != "global,api,admin" {
+		t.Errorf("Expected middlewares added after the route to be skipped, got %q", trace(rec))
+	}
+	if rec := serve(rt, "GET", "/api/items"); trace(rec) != "global,api" {
+		t.Errorf("Expected only global and api middlewares, got %q", trace(rec))
+	}
+	if rec := serve(rt, "GET", "/"); trace(rec) != "global" {
+		t.Errorf("Expected only the global middleware, got %q", trace(rec))
+	}
+	if rec := serve(rt, "GET", "/api/missing"); rec.Code != http.StatusNotFound || trace(rec) != "global" {
+		t.Errorf("Expected a 404 with only the global middleware, got %d with %q", rec.Code, trace(rec))
+	}
+}
+
+// TestMount tests serving a plain http.Handler under a prefix with any method
+func TestMount(t *testing.T) {
+	files := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method + " " + r.URL.Path))
+	})
+
+	rt := New()
+	rt.GET("/static/version", func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.Write([]byte("v1"))
+	})
+	rt.Mount("/static/", http.StripPrefix("/static", files), tag("static"))
+	rt.Group("/api").Mount("/legacy", files)
+
+	if rec := serve(rt, "GET", "/static/js/app.js"); rec.Body.String() != "GET /js/app.js" || trace(rec) != "static" {
+		t.Errorf("Unexpected response %q with trace %q", rec.Body.String(), trace(rec))
+	}
+	if rec := serve(rt, "POST", "/api/legacy/"); rec.Body.String() != "POST /api/legacy/" {
+		t.Errorf("Expected the mount point itself to be served, got %q", rec.Body.String())
+	}
+	if rec := serve(rt, "GET", "/static/version"); rec.Body.String() != "v1" {
+		t.Errorf("Expected the explicit route to win over the mount, got %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,278 @@
+package router
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestIDHeader — заголовок, в котором принимается и возвращается ID запроса.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength ограничивает длину ID, пришедшего от клиента.
+const maxRequestIDLength = 128
+
+type contextKey int
+
+const requestIDKey contextKey = iota
+
+// RequestIDFromContext возвращает ID запроса, выставленный middleware RequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// RequestID берёт ID запроса из заголовка X-Request-ID или генерирует новый,
+// кладёт его в контекст запроса и возвращает клиенту в том же заголовке.
+func RequestID() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, params Params) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+			next(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)), params)
+		}
+	}
+}
+
+// validRequestID пропускает только короткие ID из печатных ASCII-символов,
+// чтобы клиент не мог подсунуть в логи что-то лишнее.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AccessLog пишет по строке на запрос: метод, путь, код ответа, размер тела,
+// длительность и ID запроса. nil означает slog.Default().
+func AccessLog(logger *slog.Logger) MiddlewareFunc {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, params Params) {
+			start := time.Now()
+			sw := WrapResponseWriter(w)
+			next(sw, r, params)
+
+			status := sw.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", sw.BytesWritten()),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("request_id", RequestIDFromContext(r.Context())),
+			)
+		}
+	}
+}
+
+// Recovery перехватывает панику обработчика, пишет её в лог со стеком и,
+// если ответ ещё не начат, отвечает JSON с кодом 500.
+func Recovery() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, params Params) {
+			sw := WrapResponseWriter(w)
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+				// ErrAbortHandler — штатный способ оборвать ответ, net/http обработает его сам
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				log.Printf("panic serving %s %s (request %s): %v\n%s",
+					r.Method, r.URL.Path, RequestIDFromContext(r.Context()), err, debug.Stack())
+
+				if sw.Written() {
+					return
+				}
+				sw.Header().Del("Content-Encoding")
+				sw.Header().Del("Content-Length")
+				sw.Header().Set("Content-Type", "application/json")
+				sw.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(sw).Encode(map[string]string{"message": "Internal server error"})
+			}()
+			next(sw, r, params)
+		}
+	}
+}
+
+// compressibleTypes — типы содержимого, которые имеет смысл сжимать.
+var compressibleTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"image/svg+xml",
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(nil) },
+}
+
+// Gzip сжимает текстовые ответы, если клиент принимает gzip. Его ставят
+// снаружи Recovery, чтобы JSON с ошибкой 500 тоже проходил через сжатие.
+func Gzip() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, params Params) {
+			if r.Method == http.MethodHead || !acceptsGzip(r) {
+				next(w, r, params)
+				return
+			}
+			gw := &gzipResponseWriter{ResponseWriter: w}
+			defer func() {
+				// При панике ответ не дописываем: заголовки и конец потока
+				// отправили бы оборванный ответ как успешный, а ответить
+				// должен Recovery или net/http
+				if err := recover(); err != nil {
+					gw.release()
+					panic(err)
+				}
+				gw.Close()
+			}()
+			next(gw, r, params)
+		}
+	}
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, q, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.TrimSpace(coding) == "gzip" && strings.ReplaceAll(q, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter откладывает заголовки до первой записи: только тогда
+// известен Content-Type, а от него зависит, сжимать ли ответ.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz      *gzip.Writer
+	status  int
+	started bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	// Информационные ответы отправляются сразу и не начинают тело
+	if status >= 100 && status < 200 {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.started {
+		w.start(b)
+	}
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// start решает, сжимать ли ответ, и отправляет заголовки.
+func (w *gzipResponseWriter) start(firstChunk []byte) {
+	w.started = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	h := w.Header()
+	h.Add("Vary", "Accept-Encoding")
+	if h.Get("Content-Type") == "" && len(firstChunk) > 0 {
+		h.Set("Content-Type", http.DetectContentType(firstChunk))
+	}
+	// Ответы на Range-запросы не сжимаем: Content-Range относится к несжатому телу
+	if len(firstChunk) > 0 && h.Get("Content-Encoding") == "" && h.Get("Content-Range") == "" &&
+		bodyAllowed(w.status) && compressible(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		w.gz = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// Close дописывает сжатый поток; ответ без тела отправляет только заголовки.
+func (w *gzipResponseWriter) Close() {
+	if !w.started {
+		if w.status == 0 {
+			return
+		}
+		w.start(nil)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		w.release()
+	}
+}
+
+// release возвращает gzip.Writer в пул, не дописывая поток.
+func (w *gzipResponseWriter) release() {
+	if w.gz != nil {
+		gzipWriters.Put(w.gz)
+		w.gz = nil
+	}
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if !w.started {
+		w.start(nil)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+func compressible(contentType string) bool {
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package router
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestRecovery tests that a panicking handler gets a JSON 500 and the panic is logged
+func TestRecovery(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	rt := New()
+	rt.Use(RequestID())
+	rt.Use(Recovery())
+	rt.GET("/boom", func(w http.ResponseWriter, r *http.Request, params Params) {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/boom", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rt.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500, got %d", rec.Code)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body["message"] == "" {
+		t.Errorf("Expected a JSON error message, got %q (%v)", rec.Body.String(), err)
+	}
+	if !strings.Contains(logged.String(), "boom") || !strings.Contains(logged.String(), "req-1") {
+		t.Errorf("Expected the panic and request ID to be logged, got %q", logged.String())
+	}
+}
+
+// TestRequestID tests propagating a client ID and replacing an invalid one
+func TestRequestID(t *testing.T) {
+	var seen string
+	rt := New()
+	rt.Use(RequestID())
+	rt.GET("/", func(w http.ResponseWriter, r *http.Request, params Params) {
+		seen = RequestIDFromContext(r.Context())
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	rt.ServeHTTP(rec, req)
+	if seen != "abc-123" || rec.Header().Get(RequestIDHeader) != "abc-123" {
+		t.Errorf("Expected client ID to be kept, got %q and header %q", seen, rec.Header().Get(RequestIDHeader))
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	rt.ServeHTTP(rec, req)
+	if seen == "" || seen == "bad id\n" || rec.Header().Get(RequestIDHeader) != seen {
+		t.Errorf("Expected a generated ID, got %q", seen)
+	}
+}
+
+// TestAccessLog tests the logged fields, including for unmatched routes
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	rt := New()
+	rt.Use(RequestID())
+	rt.Use(AccessLog(slog.New(slog.NewJSONHandler(&buf, nil))))
+	rt.POST("/items", func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+
+	rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/items", nil))
+	rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %q", buf.String())
+	}
+	var entry struct {
+		Method    string `json:"method"`
+		Path      string `json:"path"`
+		Status    int    `json:"status"`
+		Bytes     int    `json:"bytes"`
+		RequestID string `json:"request_id"`
+	}
+	json.Unmarshal([]byte(lines[0]), &entry)
+	if entry.Method != "POST" || entry.Path != "/items" || entry.Status != 201 || entry.Bytes != 5 || entry.RequestID == "" {
+		t.Errorf("Unexpected log entry %s", lines[0])
+	}
+	json.Unmarshal([]byte(lines[1]), &entry)
+	if entry.Status != http.StatusNotFound {
+		t.Errorf("Expected 404 to be logged, got %s", lines[1])
+	}
+}
+
+// TestGzip tests compressing text responses only for clients that accept gzip
+func TestGzip(t *testing.T) {
+	payload := strings.Repeat(`{"key":"value"}`, 100)
+	rt := New()
+	rt.Use(Gzip())
+	rt.GET("/json", func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(payload))
+	})
+	rt.GET("/png", func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte(payload))
+	})
+	rt.GET("/empty", func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rt.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/json", "br, gzip")
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("Expected a gzip response, got headers %v", rec.Header())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != payload {
+		t.Errorf("Expected the decompressed payload, got %q", body)
+	}
+
+	if rec := get("/json", "gzip;q=0"); rec.Header().Get("Content-Encoding") != "" {
+		t.Error("Expected no compression when gzip is refused")
+	}
+	if rec := get("/png", "gzip"); rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != payload {
+		t.Error("Expected images to be sent as is")
+	}
+	if rec := get("/empty", "gzip"); rec.Code != http.StatusNoContent || rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Expected a bare 204, got %d with %v", rec.Code, rec.Header())
+	}
+}
+
+// TestGzipPanic tests a panic behind Gzip, registered outside Recovery as in
+// cmd/app: a panic before the first write gets a compressed JSON 500, one after
+// it leaves the written part as is without an error appended
+func TestGzipPanic(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	rt := New()
+	rt.Use(Gzip())
+	rt.Use(Recovery())
+	rt.GET("/early", func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.Header().Set("Content-Type", "text/plain")
+		panic("boom")
+	})
+	rt.GET("/late", func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("partial"))
+		panic("boom")
+	})
+
+	get := func(path string) (*httptest.ResponseRecorder, string) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rt.ServeHTTP(rec, req)
+		if rec.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Expected a gzip response for %s, got headers %v", path, rec.Header())
+		}
+		zr, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		body, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("Expected a complete gzip stream for %s, got %v", path, err)
+		}
+		return rec, string(body)
+	}
+
+	rec, body := get("/early")
+	if rec.Code != http.StatusInternalServerError || rec.Header().Get("Content-Type") != "application/json" || !strings.Contains(body, "Internal server error") {
+		t.Errorf("Expected a JSON 500, got %d %q", rec.Code, body)
+	}
+	rec, body = get("/late")
+	if rec.Code != http.StatusOK || body != "partial" {
+		t.Errorf("Expected the written part only, got %d %q", rec.Code, body)
+	}
+}
+
+// TestGzipPanicNotFlushed tests that Gzip does not finish a response while a
+// panic passes through it, so nothing is sent for a handler that wrote no body
+func TestGzipPanicNotFlushed(t *testing.T) {
+	rt := New()
+	rt.Use(Gzip())
+	rt.GET("/", func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		panic(http.ErrAbortHandler)
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	func() {
+		defer func() {
+			if recover() != http.ErrAbortHandler {
+				t.Error("Expected the panic to reach the server")
+			}
+		}()
+		rt.ServeHTTP(rec, req)
+	}()
+	if rec.Flushed || rec.Body.Len() != 0 || rec.Header().Get("Vary") != "" {
+		t.Errorf("Expected nothing to be sent, got %d %v %q", rec.Code, rec.Header(), rec.Body.String())
+	}
+}
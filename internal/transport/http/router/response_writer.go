@@ -0,0 +1,80 @@
+package router
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// StatusWriter оборачивает http.ResponseWriter и запоминает код ответа и
+// число записанных байт — это нужно логированию и восстановлению после паники.
+type StatusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+// WrapResponseWriter возвращает StatusWriter поверх w; уже обёрнутый writer
+// возвращается как есть.
+func WrapResponseWriter(w http.ResponseWriter) *StatusWriter {
+	if sw, ok := w.(*StatusWriter); ok {
+		return sw
+	}
+	return &StatusWriter{ResponseWriter: w}
+}
+
+func (w *StatusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *StatusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Status возвращает отправленный код ответа; 0, пока заголовки не записаны.
+func (w *StatusWriter) Status() int {
+	return w.status
+}
+
+// Written сообщает, были ли уже отправлены заголовки.
+func (w *StatusWriter) Written() bool {
+	return w.status != 0
+}
+
+// BytesWritten возвращает размер тела ответа в байтах.
+func (w *StatusWriter) BytesWritten() int {
+	return w.bytes
+}
+
+// Unwrap даёт http.ResponseController доступ к исходному writer.
+func (w *StatusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush нужен потоковым ответам, которые проверяют http.Flusher напрямую.
+func (w *StatusWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack нужен для websocket-соединений.
+func (w *StatusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return h.Hijack()
+}
@@ -2,42 +2,54 @@ package router
 
 import (
 	"net/http"
+	pathpkg "path"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // HandlerFunc тот же тип, что и в net/http, но мы оборачиваем для middleware.
 type HandlerFunc func(w http.ResponseWriter, r *http.Request, params Params)
 
-// Params хранит параметры пути (например :id).
+// Params хранит параметры пути (например :id). Карта берётся из пула и
+// очищается после ответа, поэтому обработчик не должен хранить её дольше
+// запроса — нужные значения копируют.
 type Params map[string]string
 
 // MiddlewareFunc — функция middleware.
 type MiddlewareFunc func(HandlerFunc) HandlerFunc
 
-// node — узел для простого trie (разделён по сегментам).
-type node struct {
-	segment    string
-	children   []*node
-	paramChild *node                  // child for :param
-	catchAll   *node                  // child for *wildcard
-	handlers   map[string]HandlerFunc // method -> handler
-}
-
 // Router структура маршрутизатора.
 type Router struct {
 	root             *node
+	params           sync.Pool
+	names            map[string]string // имя маршрута -> шаблон пути
 	middlewares      []MiddlewareFunc
 	notFound         http.HandlerFunc
 	methodNotAllowed http.HandlerFunc
+
+	// RedirectTrailingSlash перенаправляет "/a/" на "/a" и наоборот, если
+	// маршрут зарегистрирован только в другом виде; иначе такой запрос
+	// получает 404. "/a" и "/a/" можно зарегистрировать и как два разных
+	// маршрута. Включено по умолчанию.
+	RedirectTrailingSlash bool
+	// RedirectCleanPath перенаправляет пути с "//", "." и ".." на очищенный
+	// путь, как это делает http.ServeMux. Включено по умолчанию.
+	RedirectCleanPath bool
+	// Preflight вызывается на CORS preflight-запросы (OPTIONS с заголовками
+	// Origin и Access-Control-Request-Method) к существующему пути, у
+	// которого нет своего OPTIONS-обработчика. Он должен выставить
+	// CORS-заголовки; ответ 204 роутер отправляет сам. allowed — методы пути.
+	Preflight func(w http.ResponseWriter, r *http.Request, allowed []string)
 }
 
 // New создаёт новый Router.
 func New() *Router {
 	return &Router{
-		root: &node{
-			segment:  "/",
-			children: []*node{},
-			handlers: map[string]HandlerFunc{},
+		root:  &node{},
+		names: map[string]string{},
+		params: sync.Pool{
+			New: func() interface{} { return Params{} },
 		},
 		notFound: func(w http.ResponseWriter, r *http.Request) {
 			http.NotFound(w, r)
@@ -46,172 +58,204 @@ func New() *Router {
 			w.WriteHeader(http.StatusMethodNotAllowed)
 			w.Write([]byte("405 method not allowed"))
 		},
+		RedirectTrailingSlash: true,
+		RedirectCleanPath:     true,
 	}
 }
 
 // Use добавляет middleware (в порядке вызова).
-func (rt *Router) Use(m MiddlewareFunc) {
-	rt.middlewares = append(rt.middlewares, m)
+func (rt *Router) Use(mws ...MiddlewareFunc) {
+	rt.middlewares = append(rt.middlewares, mws...)
 }
 
-// Handle регистрирует обработчик для метода и пути.
-func (rt *Router) Handle(method, path string, h HandlerFunc) {
-	if path == "" || path[0] != '/' {
-		panic("path must start with '/'")
+// UseHTTP добавляет стандартные middleware вида func(http.Handler) http.Handler,
+// как Use.
+func (rt *Router) UseHTTP(mws ...func(http.Handler) http.Handler) {
+	for _, m := range mws {
+		rt.middlewares = append(rt.middlewares, FromMiddleware(m))
 	}
-	segments := splitPath(path)
-	cur := rt.root
-	for i, seg := range segments {
-		isParam := len(seg) > 0 && seg[0] == ':'
-		isCatchAll := len(seg) > 0 && seg[0] == '*'
+}
 
-		var next *node
+// anyMethod — ключ в handlers для обработчиков, подключённых через Mount:
+// они принимают запросы с любым методом.
+const anyMethod = "*"
 
-		// catch-all can only be last
-		if isCatchAll {
-			if i != len(segments)-1 {
-				panic("catch-all must be the last segment")
-			}
-			if cur.catchAll == nil {
-				cur.catchAll = &node{segment: seg, handlers: map[string]HandlerFunc{}}
-			}
-			next = cur.catchAll
-		} else if isParam {
-			if cur.paramChild == nil {
-				cur.paramChild = &node{segment: seg, handlers: map[string]HandlerFunc{}}
-			}
-			next = cur.paramChild
-		} else {
-			// static child
-			for _, c := range cur.children {
-				if c.segment == seg {
-					next = c
-					break
-				}
-			}
-			if next == nil {
-				next = &node{segment: seg, handlers: map[string]HandlerFunc{}}
-				cur.children = append(cur.children, next)
-			}
-		}
-		cur = next
+// Handle регистрирует обработчик для метода и пути. Middleware, переданные
+// здесь, применяются только к этому маршруту, внутри глобальных из Use.
+// Возвращённому маршруту можно дать имя для URL.
+func (rt *Router) Handle(method, path string, h HandlerFunc, mws ...MiddlewareFunc) *Route {
+	rt.add(strings.ToUpper(method), path, chain(h, mws))
+	return &Route{router: rt, pattern: path}
+}
+
+// Mount подключает обычный http.Handler ко всем путям под prefix и к самому
+// prefix со слешем в конце, с любым методом. Путь запроса передаётся как есть, поэтому
+// обработчики вроде http.FileServer оборачивают в http.StripPrefix.
+// Имя, данное возвращённому маршруту, строит URL вида prefix/<path>.
+func (rt *Router) Mount(prefix string, h http.Handler, mws ...MiddlewareFunc) *Route {
+	handler := chain(FromHandler(h), mws)
+	prefix = strings.TrimSuffix(prefix, "/")
+	rt.add(anyMethod, prefix+"/*path", handler)
+	rt.add(anyMethod, prefix+"/", handler)
+	return &Route{router: rt, pattern: prefix + "/*path"}
+}
+
+// add кладёт обработчик в дерево маршрутов.
+func (rt *Router) add(method, path string, h HandlerFunc) {
+	if path == "" || path[0] != '/' {
+		panic("path must start with '/'")
 	}
-	if cur.handlers == nil {
-		cur.handlers = map[string]HandlerFunc{}
+	n := rt.root.insert(path)
+	n.pattern = path
+	if n.handlers == nil {
+		n.handlers = map[string]HandlerFunc{}
 	}
-	cur.handlers[strings.ToUpper(method)] = h
+	n.handlers[method] = h
+	rt.root.prioritize()
 }
 
-// GET/POST helpers
-func (rt *Router) GET(path string, h HandlerFunc)  { rt.Handle("GET", path, h) }
-func (rt *Router) POST(path string, h HandlerFunc) { rt.Handle("POST", path, h) }
+// GET/POST/PUT/DELETE helpers
+func (rt *Router) GET(path string, h HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return rt.Handle("GET", path, h, mws...)
+}
+func (rt *Router) POST(path string, h HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return rt.Handle("POST", path, h, mws...)
+}
+func (rt *Router) PUT(path string, h HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return rt.Handle("PUT", path, h, mws...)
+}
+func (rt *Router) DELETE(path string, h HandlerFunc, mws ...MiddlewareFunc) *Route {
+	return rt.Handle("DELETE", path, h, mws...)
+}
 
 // ServeHTTP делает Router совместимым с net/http.
 func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	params := rt.params.Get().(Params)
+	handler := rt.resolve(r, params)
+	r = withParams(r, params)
+	// middleware применяются и к редиректам, 404 и 405, чтобы такие запросы тоже логировались
+	chain(handler, rt.middlewares)(w, r, params)
+	clear(params)
+	rt.params.Put(params)
+}
+
+// chain оборачивает h в middleware так, что первый из них выполняется первым.
+func chain(h HandlerFunc, mws []MiddlewareFunc) HandlerFunc {
+	// применяем middleware в обратном порядке (wrap)
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// resolve выбирает обработчик запроса: маршрут, редирект на канонический
+// путь, автоматический HEAD/OPTIONS, 405 или 404. Параметры пути пишутся в params.
+func (rt *Router) resolve(r *http.Request, params Params) HandlerFunc {
 	path := r.URL.Path
-	method := r.Method
-	handler, params := rt.match(method, path)
-	if handler == nil {
-		// если путь найден, но метод нет — 405, иначе 404
-		if rt.pathExists(path) {
-			rt.methodNotAllowed(w, r)
-			return
+	// например "OPTIONS *": такой запрос не относится ни к одному маршруту
+	if !strings.HasPrefix(path, "/") {
+		return wrapHTTP(rt.notFound)
+	}
+	if rt.RedirectCleanPath {
+		if clean := cleanPath(path); clean != path {
+			return redirect(clean)
 		}
-		rt.notFound(w, r)
-		return
 	}
-	// применяем middleware в обратном порядке (wrap)
-	final := handler
-	for i := len(rt.middlewares) - 1; i >= 0; i-- {
-		final = rt.middlewares[i](final)
-	}
-	final(w, r, params)
-}
-
-// match ищет обработчик и собирает параметры для конкретного метода.
-func (rt *Router) match(method, path string) (HandlerFunc, Params) {
-	segments := splitPath(path)
-	cur := rt.root
-	params := Params{}
-
-	for i, seg := range segments {
-		// try static children first
-		var matched *node
-		for _, c := range cur.children {
-			if c.segment == seg {
-				matched = c
-				break
+
+	n := rt.root.match(path, params)
+	if n != nil {
+		r.Pattern = n.pattern
+	}
+	if n == nil {
+		if rt.RedirectTrailingSlash && path != "/" {
+			other := path + "/"
+			if strings.HasSuffix(path, "/") {
+				other = strings.TrimSuffix(path, "/")
+			}
+			found := rt.root.match(other, params) != nil
+			clear(params)
+			if found {
+				return redirect(other)
 			}
 		}
-		if matched != nil {
-			cur = matched
-			continue
-		}
-		// param child?
-		if cur.paramChild != nil {
-			paramName := strings.TrimPrefix(cur.paramChild.segment, ":")
-			params[paramName] = seg
-			cur = cur.paramChild
-			continue
-		}
-		// catch-all?
-		if cur.catchAll != nil {
-			paramName := strings.TrimPrefix(cur.catchAll.segment, "*")
-			rest := strings.Join(segments[i:], "/")
-			params[paramName] = rest
-			cur = cur.catchAll
-			break
-		}
-		// nothing matched
-		return nil, nil
+		return wrapHTTP(rt.notFound)
+	}
+
+	method := strings.ToUpper(r.Method)
+	if h, ok := n.handlers[method]; ok {
+		return h
+	}
+	// net/http сам отбрасывает тело ответа на HEAD
+	if h, ok := n.handlers[http.MethodGet]; ok && method == http.MethodHead {
+		return h
 	}
-	// found node — look up handler by method
-	if cur.handlers == nil {
-		return nil, nil
+	if h, ok := n.handlers[anyMethod]; ok {
+		return h
 	}
-	if handler, ok := cur.handlers[strings.ToUpper(method)]; ok {
-		return handler, params
+
+	allowed := n.allowed()
+	if method == http.MethodOptions {
+		return rt.options(allowed)
+	}
+	return func(w http.ResponseWriter, r *http.Request, _ Params) {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		rt.methodNotAllowed(w, r)
 	}
-	return nil, nil
 }
 
-// pathExists проверяет, есть ли путь вообще (без учёта метода).
-func (rt *Router) pathExists(path string) bool {
-	segments := splitPath(path)
-	cur := rt.root
-	for i, seg := range segments {
-		var matched *node
-		for _, c := range cur.children {
-			if c.segment == seg {
-				matched = c
-				break
-			}
-		}
-		if matched != nil {
-			cur = matched
-			continue
+// options отвечает на OPTIONS к пути без своего OPTIONS-обработчика.
+func (rt *Router) options(allowed []string) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, _ Params) {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		if rt.Preflight != nil && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != "" {
+			rt.Preflight(w, r, allowed)
 		}
-		if cur.paramChild != nil {
-			cur = cur.paramChild
-			continue
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// allowed возвращает отсортированный список методов узла для заголовка Allow;
+// HEAD и OPTIONS роутер обслуживает сам.
+func (n *node) allowed() []string {
+	methods := []string{http.MethodOptions}
+	for method := range n.handlers {
+		if method != http.MethodOptions {
+			methods = append(methods, method)
 		}
-		if cur.catchAll != nil {
-			cur = cur.catchAll
-			break
+	}
+	if _, ok := n.handlers[http.MethodGet]; ok {
+		if _, ok := n.handlers[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
 		}
-		_ = i
-		return false
 	}
-	return cur != nil && len(cur.handlers) > 0
+	sort.Strings(methods)
+	return methods
 }
 
-func splitPath(p string) []string {
-	if p == "/" || p == "" {
-		return []string{}
+// redirect перенаправляет на тот же запрос с другим путём: 301 для GET и
+// HEAD, 308 для остальных, чтобы клиент повторил метод и тело.
+func redirect(path string) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, _ Params) {
+		code := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			code = http.StatusPermanentRedirect
+		}
+		u := *r.URL
+		u.Path = path
+		u.RawPath = ""
+		http.Redirect(w, r, u.String(), code)
 	}
-	p = strings.Trim(p, "/")
-	if p == "" {
-		return []string{}
+}
+
+// cleanPath убирает из пути "//", "." и "..", сохраняя слеш в конце.
+func cleanPath(p string) string {
+	clean := pathpkg.Clean(p)
+	if strings.HasSuffix(p, "/") && clean != "/" {
+		clean += "/"
 	}
-	return strings.Split(p, "/")
+	return clean
+}
+
+func wrapHTTP(h http.HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, _ Params) { h(w, r) }
 }
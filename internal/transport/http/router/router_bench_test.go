@@ -0,0 +1,99 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// benchRoutes mirrors the routes registered in cmd/app
+var benchRoutes = []struct{ method, path string }{
+	{"GET", "/"},
+	{"GET", "/compare"},
+	{"GET", "/api/groups"},
+	{"POST", "/api/groups"},
+	{"POST", "/api/groups/bulk"},
+	{"GET", "/api/groups/:id/trend"},
+	{"GET", "/api/groups/:id/screen-names"},
+	{"DELETE", "/api/groups/:id/sync"},
+	{"PUT", "/api/groups/:id/tags"},
+	{"PUT", "/api/groups/:id/notes"},
+	{"GET", "/api/tags"},
+	{"POST", "/api/tags/bulk"},
+	{"GET", "/api/compare"},
+	{"GET", "/api/sets"},
+	{"POST", "/api/sets"},
+	{"GET", "/api/sets/:id"},
+	{"PUT", "/api/sets/:id"},
+	{"DELETE", "/api/sets/:id"},
+	{"GET", "/api/sets/:id/benchmark"},
+	{"GET", "/api/retention"},
+}
+
+func benchRouter() http.Handler {
+	rt := New()
+	noop := func(w http.ResponseWriter, r *http.Request, params Params) {}
+	for _, route := range benchRoutes {
+		rt.Handle(route.method, route.path, noop)
+	}
+	return rt
+}
+
+// benchServeMux registers the same routes with Go 1.22 ServeMux patterns
+func benchServeMux() http.Handler {
+	mux := http.NewServeMux()
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	for _, route := range benchRoutes {
+		path := strings.ReplaceAll(route.path, ":id", "{id}")
+		if path == "/" {
+			path = "/{$}"
+		}
+		mux.HandleFunc(route.method+" "+path, noop)
+	}
+	return mux
+}
+
+func benchServe(b *testing.B, h http.Handler, method, path string) {
+	req := httptest.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkRouterStatic(b *testing.B) {
+	benchServe(b, benchRouter(), "GET", "/api/retention")
+}
+
+func BenchmarkServeMuxStatic(b *testing.B) {
+	benchServe(b, benchServeMux(), "GET", "/api/retention")
+}
+
+func BenchmarkRouterParam(b *testing.B) {
+	benchServe(b, benchRouter(), "GET", "/api/sets/42/benchmark")
+}
+
+func BenchmarkServeMuxParam(b *testing.B) {
+	benchServe(b, benchServeMux(), "GET", "/api/sets/42/benchmark")
+}
+
+// BenchmarkRouterGroupParam serves a route of nested groups with
+// middlewares, as the API routes in cmd/app are
+func BenchmarkRouterGroupParam(b *testing.B) {
+	pass := func(next HandlerFunc) HandlerFunc { return next }
+	rt := New()
+	group := rt.Group("/api", pass).Group("/sets/:id", pass)
+	group.GET("/benchmark", func(w http.ResponseWriter, r *http.Request, params Params) {}, pass)
+	benchServe(b, rt, "GET", "/api/sets/42/benchmark")
+}
+
+func BenchmarkRouterNotFound(b *testing.B) {
+	benchServe(b, benchRouter(), "GET", "/api/unknown/path")
+}
+
+func BenchmarkServeMuxNotFound(b *testing.B) {
+	benchServe(b, benchServeMux(), "GET", "/api/unknown/path")
+}
@@ -0,0 +1,241 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func echo(w http.ResponseWriter, r *http.Request, params Params) {
+	w.Write([]byte(r.Method + " " + r.URL.Path))
+}
+
+// TestMethodNotAllowed tests the 405 response and its Allow header
+func TestMethodNotAllowed(t *testing.T) {
+	rt := New()
+	rt.GET("/items/:id", echo)
+	rt.PUT("/items/:id", echo)
+
+	rec := serve(rt, "DELETE", "/items/1")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected 405, got %d", rec.Code)
+	}
+	if allow := rec.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS, PUT" {
+		t.Errorf("Unexpected Allow header %q", allow)
+	}
+	if rec := serve(rt, "DELETE", "/missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown path, got %d", rec.Code)
+	}
+}
+
+// TestHeadFallsBackToGet tests that HEAD is served by the GET handler unless it has its own
+func TestHeadFallsBackToGet(t *testing.T) {
+	rt := New()
+	rt.GET("/a", echo)
+	rt.GET("/b", echo)
+	rt.Handle("HEAD", "/b", func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.Header().Set("X-Head", "1")
+	})
+
+	if rec := serve(rt, "HEAD", "/a"); rec.Code != http.StatusOK || rec.Body.String() != "HEAD /a" {
+		t.Errorf("Expected the GET handler, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve(rt, "HEAD", "/b"); rec.Header().Get("X-Head") != "1" {
+		t.Error("Expected the explicit HEAD handler")
+	}
+}
+
+// TestOptions tests automatic OPTIONS responses and the CORS preflight hook
+func TestOptions(t *testing.T) {
+	var preflightAllowed []string
+	rt := New()
+	rt.POST("/api/items", echo)
+	rt.Handle("OPTIONS", "/custom", func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	rt.Preflight = func(w http.ResponseWriter, r *http.Request, allowed []string) {
+		preflightAllowed = allowed
+		w.Header().Set("Access-Control-Allow-Origin", r.Header.Get("Origin"))
+	}
+
+	rec := serve(rt, "OPTIONS", "/api/items")
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Allow") != "OPTIONS, POST" {
+		t.Errorf("Unexpected response %d with Allow %q", rec.Code, rec.Header().Get("Allow"))
+	}
+	if preflightAllowed != nil {
+		t.Error("Expected no preflight hook call for a plain OPTIONS request")
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("OPTIONS", "/api/items", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rt.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "https://example.com" {
+		t.Errorf("Unexpected preflight response %d with headers %v", rec.Code, rec.Header())
+	}
+	if len(preflightAllowed) != 2 {
+		t.Errorf("Expected the hook to get the allowed methods, got %v", preflightAllowed)
+	}
+
+	if rec := serve(rt, "OPTIONS", "/custom"); rec.Code != http.StatusTeapot {
+		t.Errorf("Expected the explicit OPTIONS handler, got %d", rec.Code)
+	}
+	if rec := serve(rt, "OPTIONS", "/missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown path, got %d", rec.Code)
+	}
+}
+
+// TestRedirects tests trailing-slash and clean-path redirects and turning them off
+func TestRedirects(t *testing.T) {
+	rt := New()
+	rt.GET("/items", echo)
+	rt.POST("/items", echo)
+	rt.GET("/dir/", echo)
+	rt.GET("/files/*path", echo)
+
+	tests := []struct {
+		method, path string
+		code         int
+		location     string
+	}{
+		{"GET", "/items/", http.StatusMovedPermanently, "/items"},
+		{"POST", "/items/?x=1", http.StatusPermanentRedirect, "/items?x=1"},
+		{"GET", "/dir", http.StatusMovedPermanently, "/dir/"},
+		{"GET", "//items", http.StatusMovedPermanently, "/items"},
+		{"GET", "/dir/../items", http.StatusMovedPermanently, "/items"},
+		{"GET", "/files/a/", http.StatusOK, ""},
+		{"GET", "/items", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		rec := serve(rt, tt.method, tt.path)
+		if rec.Code != tt.code || rec.Header().Get("Location") != tt.location {
+			t.Errorf("%s %s: expected %d to %q, got %d to %q",
+				tt.method, tt.path, tt.code, tt.location, rec.Code, rec.Header().Get("Location"))
+		}
+	}
+
+	rt.RedirectTrailingSlash = false
+	rt.RedirectCleanPath = false
+	if rec := serve(rt, "GET", "/items/"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without trailing-slash redirects, got %d", rec.Code)
+	}
+	if rec := serve(rt, "GET", "//items"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected //items to be matched as is, got %d", rec.Code)
+	}
+}
+
+// TestTrailingSlashRoutes tests that a path with and without a trailing slash can be two routes
+func TestTrailingSlashRoutes(t *testing.T) {
+	rt := New()
+	rt.GET("/items", echo)
+	rt.POST("/items/", echo)
+
+	if rec := serve(rt, "GET", "/items"); rec.Body.String() != "GET /items" {
+		t.Errorf("Unexpected response %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve(rt, "POST", "/items/"); rec.Body.String() != "POST /items/" {
+		t.Errorf("Unexpected response %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve(rt, "POST", "/items"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for the route without a slash, got %d", rec.Code)
+	}
+}
+
+// TestParamConstraints tests typed parameters sharing one level with plain ones
+func TestParamConstraints(t *testing.T) {
+	handler := func(name string) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, params Params) {
+			w.Write([]byte(name + " " + params["id"] + params["slug"] + params["domain"]))
+		}
+	}
+	rt := New()
+	rt.GET("/groups/:domain", handler("domain"))
+	rt.GET("/groups/:id{int}", handler("id"))
+	rt.GET("/tags/:slug{[a-z-]+}", handler("slug"))
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/groups/42", "id 42"},
+		{"/groups/-7", "id -7"},
+		{"/groups/durov", "domain durov"},
+		{"/groups/4x", "domain 4x"},
+		{"/tags/go-lang", "slug go-lang"},
+	}
+	for _, tt := range tests {
+		if rec := serve(rt, "GET", tt.path); rec.Body.String() != tt.want {
+			t.Errorf("%s: expected %q, got %d %q", tt.path, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := serve(rt, "GET", "/tags/Go"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a value failing the constraint, got %d", rec.Code)
+	}
+}
+
+// TestBacktracking tests falling back to a parameter when a matching static branch dead-ends
+func TestBacktracking(t *testing.T) {
+	rt := New()
+	rt.GET("/groups/new", echo)
+	rt.GET("/groups/news/feed", echo)
+	rt.GET("/groups/:id/trend", func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.Write([]byte("trend " + params["id"]))
+	})
+	rt.GET("/files/:name", echo)
+	rt.GET("/files/*path", func(w http.ResponseWriter, r *http.Request, params Params) {
+		w.Write([]byte("path " + params["path"] + " name " + params["name"]))
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/groups/new", "GET /groups/new"},
+		{"/groups/new/trend", "trend new"},
+		{"/groups/news/trend", "trend news"},
+		{"/groups/news/feed", "GET /groups/news/feed"},
+		{"/files/a.txt", "GET /files/a.txt"},
+		{"/files/a/b.txt", "path a/b.txt name "},
+	}
+	for _, tt := range tests {
+		if rec := serve(rt, "GET", tt.path); rec.Body.String() != tt.want {
+			t.Errorf("%s: expected %q, got %d %q", tt.path, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// TestParamsAreReleased tests that parameters of one request do not leak into the next
+func TestParamsAreReleased(t *testing.T) {
+	var seen []int
+	rt := New()
+	rt.GET("/a/:x", echo)
+	rt.GET("/b", func(w http.ResponseWriter, r *http.Request, params Params) {
+		seen = append(seen, len(params))
+	})
+	for i := 0; i < 10; i++ {
+		serve(rt, "GET", "/a/1")
+		serve(rt, "GET", "/b")
+	}
+	for _, n := range seen {
+		if n != 0 {
+			t.Fatalf("Expected no parameters on /b, got %d", n)
+		}
+	}
+}
+
+// TestInvalidPatterns tests that malformed route patterns are rejected at registration
+func TestInvalidPatterns(t *testing.T) {
+	for _, pattern := range []string{"items", "/a/*rest/b", "/a/:{int}", "/a/:id{int", "/a/*x"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected %q to be rejected", pattern)
+				}
+			}()
+			rt := New()
+			rt.GET("/a/*y", echo)
+			rt.GET(pattern, echo)
+		}()
+	}
+}
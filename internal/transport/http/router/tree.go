@@ -0,0 +1,280 @@
+package router
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// nodeKind — вид узла дерева маршрутов.
+type nodeKind uint8
+
+const (
+	staticNode   nodeKind = iota // кусок пути, общий для маршрутов поддерева
+	paramNode                    // :name или :name{constraint}, ровно один сегмент
+	catchAllNode                 // *name, весь остаток пути
+)
+
+// node — узел сжатого префиксного (radix) дерева. Статические куски путей
+// разных маршрутов делят общие префиксы, например "/api/groups" и
+// "/api/tags" висят на общем узле "/api/". Параметры и catch-all — отдельные
+// узлы на границе сегмента.
+type node struct {
+	kind nodeKind
+	path string // статический кусок или шаблон параметра целиком (":id{int}")
+
+	// статические дети: у всех разная первая буква, indices[i] — первая
+	// буква static[i]; порядок — по убыванию priority
+	indices string
+	static  []*node
+	// параметры, которые пробуются по очереди: сначала с ограничением,
+	// затем без, в порядке регистрации
+	params   []*node
+	catchAll *node
+
+	name       string            // имя параметра или catch-all
+	constraint func(string) bool // nil — подходит любое непустое значение
+
+	// priority — число обработчиков в поддереве: чем больше, тем раньше
+	// узел проверяется среди соседей
+	priority int
+	pattern  string                 // шаблон маршрута, отдаётся в r.Pattern
+	handlers map[string]HandlerFunc // method -> handler
+}
+
+// namedConstraints — ограничения, которые можно указать по имени: :id{int}.
+// Всё остальное в фигурных скобках считается регулярным выражением.
+var namedConstraints = map[string]func(string) bool{
+	"int": func(s string) bool {
+		_, err := strconv.ParseInt(s, 10, 64)
+		return err == nil
+	},
+	"uint": func(s string) bool {
+		_, err := strconv.ParseUint(s, 10, 64)
+		return err == nil
+	},
+}
+
+// patternToken — кусок шаблона маршрута: статический текст или параметр.
+type patternToken struct {
+	kind nodeKind
+	text string
+}
+
+// parsePattern режет шаблон "/groups/:id{int}/trend" на куски
+// "/groups/", ":id{int}", "/trend".
+func parsePattern(path string) []patternToken {
+	var tokens []patternToken
+	parts := strings.Split(path[1:], "/")
+	static := "/"
+	for i, part := range parts {
+		last := i == len(parts)-1
+		if part != "" && (part[0] == ':' || part[0] == '*') {
+			kind := paramNode
+			if part[0] == '*' {
+				if !last {
+					panic("catch-all must be the last segment")
+				}
+				kind = catchAllNode
+			}
+			tokens = append(tokens, patternToken{kind: staticNode, text: static}, patternToken{kind: kind, text: part})
+			static = ""
+			if !last {
+				static = "/"
+			}
+			continue
+		}
+		static += part
+		if !last {
+			static += "/"
+		}
+	}
+	if static != "" {
+		tokens = append(tokens, patternToken{kind: staticNode, text: static})
+	}
+	return tokens
+}
+
+// insert добавляет путь маршрута и возвращает узел, в котором лежат его обработчики.
+func (n *node) insert(path string) *node {
+	cur := n
+	for _, token := range parsePattern(path) {
+		switch token.kind {
+		case staticNode:
+			cur = cur.insertStatic(token.text)
+		case paramNode:
+			cur = cur.insertParam(token.text)
+		case catchAllNode:
+			name := token.text[1:]
+			if cur.catchAll == nil {
+				cur.catchAll = &node{kind: catchAllNode, path: token.text, name: name}
+			} else if cur.catchAll.name != name {
+				panic("catch-all " + token.text + " in " + path + " conflicts with " + cur.catchAll.path)
+			}
+			cur = cur.catchAll
+		}
+	}
+	return cur
+}
+
+// insertStatic спускается по статическому куску s, при необходимости
+// разделяя существующий узел по общему префиксу.
+func (n *node) insertStatic(s string) *node {
+	if s == "" {
+		return n
+	}
+	if i := strings.IndexByte(n.indices, s[0]); i >= 0 {
+		child := n.static[i]
+		common := commonPrefix(child.path, s)
+		if common < len(child.path) {
+			child.split(common)
+		}
+		return child.insertStatic(s[common:])
+	}
+	child := &node{kind: staticNode, path: s}
+	n.indices += s[:1]
+	n.static = append(n.static, child)
+	return child
+}
+
+// split оставляет в узле первые at байт пути, а остальное вместе с детьми и
+// обработчиками переносит в новый дочерний узел.
+func (n *node) split(at int) {
+	rest := &node{
+		kind:     staticNode,
+		path:     n.path[at:],
+		indices:  n.indices,
+		static:   n.static,
+		params:   n.params,
+		catchAll: n.catchAll,
+		priority: n.priority,
+		pattern:  n.pattern,
+		handlers: n.handlers,
+	}
+	*n = node{
+		kind:     staticNode,
+		path:     n.path[:at],
+		indices:  rest.path[:1],
+		static:   []*node{rest},
+		priority: rest.priority,
+	}
+}
+
+// insertParam находит или создаёт параметр с тем же шаблоном. Параметры с
+// разными именами или ограничениями на одном уровне уживаются: при поиске
+// они пробуются по очереди.
+func (n *node) insertParam(pattern string) *node {
+	for _, p := range n.params {
+		if p.path == pattern {
+			return p
+		}
+	}
+	name, constraint := parseParam(pattern)
+	p := &node{kind: paramNode, path: pattern, name: name, constraint: constraint}
+	n.params = append(n.params, p)
+	sort.SliceStable(n.params, func(a, b int) bool {
+		return n.params[a].constraint != nil && n.params[b].constraint == nil
+	})
+	return p
+}
+
+// parseParam разбирает ":name" или ":name{constraint}".
+func parseParam(pattern string) (string, func(string) bool) {
+	name, rule, hasRule := strings.Cut(pattern[1:], "{")
+	if name == "" {
+		panic("parameter " + pattern + " has no name")
+	}
+	if !hasRule {
+		return name, nil
+	}
+	if !strings.HasSuffix(rule, "}") {
+		panic("parameter " + pattern + " has an unterminated constraint")
+	}
+	rule = strings.TrimSuffix(rule, "}")
+	if check, ok := namedConstraints[rule]; ok {
+		return name, check
+	}
+	re := regexp.MustCompile("^(?:" + rule + ")$")
+	return name, re.MatchString
+}
+
+// prioritize пересчитывает priority и переупорядочивает статических детей,
+// чтобы самые нагруженные ветки проверялись первыми.
+func (n *node) prioritize() int {
+	n.priority = len(n.handlers)
+	for _, c := range n.static {
+		n.priority += c.prioritize()
+	}
+	for _, p := range n.params {
+		n.priority += p.prioritize()
+	}
+	if n.catchAll != nil {
+		n.priority += n.catchAll.prioritize()
+	}
+
+	sort.SliceStable(n.static, func(a, b int) bool {
+		return n.static[a].priority > n.static[b].priority
+	})
+	indices := make([]byte, len(n.static))
+	for i, c := range n.static {
+		indices[i] = c.path[0]
+	}
+	n.indices = string(indices)
+	return n.priority
+}
+
+// match ищет узел с обработчиками для остатка пути path, уже сняв путь
+// самого узла. Если ветка не подошла, поиск откатывается и пробует
+// следующую: статическую, затем параметры, затем catch-all. Значения
+// параметров пишутся в params и удаляются при откате.
+func (n *node) match(path string, params Params) *node {
+	if path == "" {
+		if len(n.handlers) > 0 {
+			return n
+		}
+		return nil
+	}
+
+	if i := strings.IndexByte(n.indices, path[0]); i >= 0 {
+		child := n.static[i]
+		if strings.HasPrefix(path, child.path) {
+			if found := child.match(path[len(child.path):], params); found != nil {
+				return found
+			}
+		}
+	}
+
+	if len(n.params) > 0 {
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if value := path[:end]; value != "" {
+			for _, p := range n.params {
+				if p.constraint != nil && !p.constraint(value) {
+					continue
+				}
+				params[p.name] = value
+				if found := p.match(path[end:], params); found != nil {
+					return found
+				}
+				delete(params, p.name)
+			}
+		}
+	}
+
+	if n.catchAll != nil && len(n.catchAll.handlers) > 0 {
+		params[n.catchAll.name] = path
+		return n.catchAll
+	}
+	return nil
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
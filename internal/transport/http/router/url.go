@@ -0,0 +1,104 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrUnknownRoute = errors.New("unknown route name")
+	ErrInvalidURL   = errors.New("cannot build route URL")
+)
+
+// Route — зарегистрированный маршрут; через него маршруту дают имя для URL.
+type Route struct {
+	router  *Router
+	pattern string
+}
+
+// Name даёт маршруту имя, по которому Router.URL строит его путь. Имена
+// уникальны на весь роутер; одно имя на путь, даже если методов несколько.
+func (r *Route) Name(name string) *Route {
+	if existing, ok := r.router.names[name]; ok {
+		panic("route name " + name + " is already used by " + existing)
+	}
+	r.router.names[name] = r.pattern
+	return r
+}
+
+// Pattern возвращает полный шаблон пути маршрута.
+func (r *Route) Pattern() string {
+	return r.pattern
+}
+
+// URL строит путь именованного маршрута, подставляя параметры из пар
+// ключ-значение: rt.URL("api.groups.trend", "id", 42). Значения проверяются
+// ограничениями параметров и экранируются; пары, которым нет параметра в
+// шаблоне, уходят в query string. Подходит для template.FuncMap.
+func (rt *Router) URL(name string, pairs ...interface{}) (string, error) {
+	pattern, ok := rt.names[name]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownRoute, name)
+	}
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("%w %q: odd number of parameter arguments", ErrInvalidURL, name)
+	}
+
+	values := make(map[string]string, len(pairs)/2)
+	keys := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return "", fmt.Errorf("%w %q: parameter name %v is not a string", ErrInvalidURL, name, pairs[i])
+		}
+		if _, dup := values[key]; !dup {
+			keys = append(keys, key)
+		}
+		values[key] = fmt.Sprint(pairs[i+1])
+	}
+
+	var b strings.Builder
+	for _, token := range parsePattern(pattern) {
+		switch token.kind {
+		case staticNode:
+			b.WriteString(token.text)
+		case paramNode:
+			param, constraint := parseParam(token.text)
+			value, ok := values[param]
+			if !ok || value == "" {
+				return "", fmt.Errorf("%w %q: missing parameter %q", ErrInvalidURL, name, param)
+			}
+			if constraint != nil && !constraint(value) {
+				return "", fmt.Errorf("%w %q: %q does not match %s", ErrInvalidURL, name, value, token.text)
+			}
+			b.WriteString(url.PathEscape(value))
+			delete(values, param)
+		case catchAllNode:
+			param := token.text[1:]
+			value, ok := values[param]
+			if !ok || value == "" {
+				return "", fmt.Errorf("%w %q: missing parameter %q", ErrInvalidURL, name, param)
+			}
+			segments := strings.Split(value, "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+			delete(values, param)
+		}
+	}
+
+	query := url.Values{}
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			query.Set(key, value)
+		}
+	}
+	if len(query) > 0 {
+		b.WriteString("?")
+		b.WriteString(query.Encode())
+	}
+	return b.String(), nil
+}
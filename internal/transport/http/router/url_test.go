@@ -0,0 +1,56 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestURL tests building paths of named routes, with parameters, groups, mounts and query strings
+func TestURL(t *testing.T) {
+	rt := New()
+	rt.GET("/", echo).Name("main")
+	api := rt.Group("/api")
+	api.GET("/groups/:id{int}/trend", echo).Name("trend")
+	api.GET("/tags/:slug", echo).Name("tag")
+	rt.Mount("/static/", http.NotFoundHandler()).Name("static")
+
+	tests := []struct {
+		name  string
+		pairs []interface{}
+		want  string
+	}{
+		{"main", nil, "/"},
+		{"main", []interface{}{"tag", "a b", "page", 2}, "/?page=2&tag=a+b"},
+		{"trend", []interface{}{"id", uint(42)}, "/api/groups/42/trend"},
+		{"tag", []interface{}{"slug", "a/b c"}, "/api/tags/a%2Fb%20c"},
+		{"static", []interface{}{"path", "js/app.js"}, "/static/js/app.js"},
+	}
+	for _, tt := range tests {
+		got, err := rt.URL(tt.name, tt.pairs...)
+		if err != nil || got != tt.want {
+			t.Errorf("URL(%q, %v) = %q, %v; expected %q", tt.name, tt.pairs, got, err, tt.want)
+		}
+	}
+
+	if _, err := rt.URL("missing"); !errors.Is(err, ErrUnknownRoute) {
+		t.Errorf("Expected ErrUnknownRoute, got %v", err)
+	}
+	for _, pairs := range [][]interface{}{nil, {"id"}, {"id", "abc"}, {1, 2}} {
+		if _, err := rt.URL("trend", pairs...); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("URL(trend, %v): expected ErrInvalidURL, got %v", pairs, err)
+		}
+	}
+}
+
+// TestDuplicateRouteName tests that a route name can only be used once
+func TestDuplicateRouteName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic")
+		}
+	}()
+	rt := New()
+	rt.GET("/a", echo).Name("a")
+	rt.GET("/b", echo).Name("a")
+}
@@ -1,3 +1,6 @@
+// Every state-changing API request carries the session's CSRF token
+const csrfToken = document.querySelector('meta[name="csrf-token"]').content;
+
 document.getElementById('addGroupBtn').addEventListener('click', async function() {
     const groupLink = document.getElementById('groupLink').value.trim();
     const loadingSpinner = document.getElementById('loadingSpinner');
@@ -22,10 +25,11 @@ document.getElementById('addGroupBtn').addEventListener('click', async function(
     addBtn.disabled = true;
 
     try {
-        const response = await fetch('/api/groups', {
+        const response = await fetch(routes.groups, {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
+                'X-CSRF-Token': csrfToken,
             },
             body: JSON.stringify({ link: groupLink })
         });
@@ -59,3 +63,62 @@ document.getElementById('groupLink').addEventListener('keypress', function(event
         document.getElementById('addGroupBtn').click();
     }
 });
+
+const importStatusLabels = {
+    added: 'добавлено',
+    already_tracked: 'уже отслеживаются',
+    invalid: 'некорректные ссылки',
+    not_community: 'не сообщества',
+    not_found: 'не найдены',
+    duplicate: 'повторы',
+    failed: 'ошибки'
+};
+
+document.getElementById('importGroupsBtn').addEventListener('click', async function() {
+    const fileInput = document.getElementById('groupsFile');
+    const loadingSpinner = document.getElementById('loadingSpinner');
+    const errorAlert = document.getElementById('errorAlert');
+    const errorMessage = document.getElementById('errorMessage');
+    const importReport = document.getElementById('importReport');
+    const importBtn = document.getElementById('importGroupsBtn');
+
+    errorAlert.style.display = 'none';
+    importReport.style.display = 'none';
+
+    if (!fileInput.files.length) {
+        errorMessage.textContent = 'Пожалуйста, выберите CSV или TXT файл со ссылками.';
+        errorAlert.style.display = 'block';
+        return;
+    }
+
+    const formData = new FormData();
+    formData.append('file', fileInput.files[0]);
+
+    loadingSpinner.style.display = 'flex';
+    importBtn.disabled = true;
+
+    try {
+        const response = await fetch(routes.groupsBulk, {
+            method: 'POST',
+            headers: { 'X-CSRF-Token': csrfToken },
+            body: formData
+        });
+        const data = await response.json().catch(() => ({}));
+        if (!response.ok) {
+            throw new Error(data.message || 'Ошибка при импорте списка. Попробуйте снова.');
+        }
+
+        const summary = Object.entries(data.summary || {})
+            .map(([status, count]) => `${importStatusLabels[status] || status}: ${count}`)
+            .join(', ');
+        importReport.textContent = `Импорт завершён — ${summary}.`;
+        importReport.style.display = 'block';
+        fileInput.value = '';
+    } catch (error) {
+        errorMessage.textContent = error.message;
+        errorAlert.style.display = 'block';
+    } finally {
+        loadingSpinner.style.display = 'none';
+        importBtn.disabled = false;
+    }
+});
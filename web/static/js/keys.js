@@ -0,0 +1,72 @@
+// Every state-changing API request carries the session's CSRF token
+const csrfToken = document.querySelector('meta[name="csrf-token"]').content;
+
+function showKeyError(message) {
+    const keyError = document.getElementById('keyError');
+    keyError.textContent = message;
+    keyError.style.display = 'block';
+}
+
+document.getElementById('createKeyBtn').addEventListener('click', async function() {
+    const name = document.getElementById('keyName').value.trim();
+    const createBtn = document.getElementById('createKeyBtn');
+
+    document.getElementById('keyError').style.display = 'none';
+    if (!name) {
+        showKeyError('Пожалуйста, введите название ключа.');
+        return;
+    }
+
+    createBtn.disabled = true;
+    try {
+        const response = await fetch(routes.keys, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-CSRF-Token': csrfToken,
+            },
+            body: JSON.stringify({
+                name: name,
+                scope: document.getElementById('keyScope').value,
+                expires_in_days: parseInt(document.getElementById('keyExpires').value, 10)
+            })
+        });
+        const data = await response.json().catch(() => ({}));
+        if (!response.ok) {
+            throw new Error(data.message || 'Не удалось создать ключ. Попробуйте снова.');
+        }
+
+        // The key is shown only once; the list is refreshed when the user is done copying it
+        document.getElementById('newKeyValue').textContent = data.key;
+        document.getElementById('newKey').style.display = 'block';
+        document.getElementById('keyName').value = '';
+    } catch (error) {
+        showKeyError(error.message);
+    } finally {
+        createBtn.disabled = false;
+    }
+});
+
+document.querySelectorAll('.revoke-key').forEach(button => {
+    button.addEventListener('click', async function() {
+        if (!confirm('Отозвать ключ? Скрипты, которые им пользуются, перестанут работать.')) {
+            return;
+        }
+
+        button.disabled = true;
+        try {
+            const response = await fetch(button.dataset.revokeUrl, {
+                method: 'DELETE',
+                headers: { 'X-CSRF-Token': csrfToken }
+            });
+            if (!response.ok) {
+                const data = await response.json().catch(() => ({}));
+                throw new Error(data.message || 'Не удалось отозвать ключ.');
+            }
+            button.closest('tr').remove();
+        } catch (error) {
+            showKeyError(error.message);
+            button.disabled = false;
+        }
+    });
+});
@@ -2,6 +2,14 @@ const rowsPerPage = 20;
 let currentPage = 1;
 let data = [];
 
+function escapeHtml(value) {
+    return String(value)
+        .replace(/&/g, "&amp;")
+        .replace(/</g, "&lt;")
+        .replace(/>/g, "&gt;")
+        .replace(/"/g, "&quot;");
+}
+
 function extractDataFromTable() {
     const tbody = document.getElementById("data-body");
     const rows = tbody.querySelectorAll("tr");
@@ -10,13 +18,17 @@ function extractDataFromTable() {
         const cells = row.querySelectorAll("td");
         return {
             group: cells[0]?.textContent?.trim() || "",
+            // Server-rendered and escaped: name, photo and screen name
+            groupHtml: cells[0]?.innerHTML?.trim() || "",
+            notes: cells[0]?.getAttribute("title") || "",
             members: parseInt(cells[1]?.textContent?.trim()) || 0,
             parsedAt: cells[2]?.textContent?.trim() || "-",
             totalPosts: parseInt(cells[3]?.textContent?.trim()) || 0,
             totalLikes: parseInt(cells[4]?.textContent?.trim()) || 0,
             avgLikes: parseFloat(cells[5]?.textContent?.trim()) || 0,
             maxLikes: parseInt(cells[6]?.textContent?.trim()) || 0,
-            avgComments: parseFloat(cells[7]?.textContent?.trim()) || 0
+            avgComments: parseFloat(cells[7]?.textContent?.trim()) || 0,
+            tags: cells[8]?.textContent?.trim() || ""
         };
     });
 }
@@ -30,14 +42,14 @@ function renderTable(page = 1) {
     tbody.innerHTML = "";
 
     if (pageData.length === 0) {
-        tbody.innerHTML = "<tr><td colspan='8' class='text-center'>Нет данных</td></tr>";
+        tbody.innerHTML = "<tr><td colspan='9' class='text-center'>Нет данных</td></tr>";
         return;
     }
 
     pageData.forEach(item => {
         const row = `
             <tr>
-                <td>${item.group}</td>
+                <td title="${escapeHtml(item.notes)}">${item.groupHtml || escapeHtml(item.group)}</td>
                 <td>${item.members}</td>
                 <td>${item.parsedAt}</td>
                 <td>${item.totalPosts}</td>
@@ -45,6 +57,7 @@ function renderTable(page = 1) {
                 <td>${typeof item.avgLikes === 'number' ? item.avgLikes.toFixed(2) : item.avgLikes}</td>
                 <td>${item.maxLikes}</td>
                 <td>${typeof item.avgComments === 'number' ? item.avgComments.toFixed(2) : item.avgComments}</td>
+                <td>${escapeHtml(item.tags)}</td>
             </tr>`;
         tbody.insertAdjacentHTML("beforeend", row);
     });
@@ -0,0 +1,70 @@
+let trendChart = null;
+
+async function loadTrend(option) {
+    const response = await fetch(option.dataset.trendUrl);
+    if (!response.ok) {
+        return;
+    }
+    const trend = await response.json();
+    const days = trend.days || [];
+
+    const data = {
+        labels: days.map(d => d.day),
+        datasets: [
+            {
+                label: 'Посты',
+                data: days.map(d => d.posts),
+                borderColor: '#0b5dd5',
+                backgroundColor: 'rgba(11,93,213,0.2)',
+                tension: 0.3,
+                yAxisID: 'y'
+            },
+            {
+                label: 'Лайки',
+                data: days.map(d => d.likes),
+                borderColor: '#3399ff',
+                backgroundColor: 'rgba(51,153,255,0.2)',
+                tension: 0.3,
+                yAxisID: 'y1'
+            },
+            {
+                label: 'Комментарии',
+                data: days.map(d => d.comments),
+                borderColor: '#66ccff',
+                backgroundColor: 'rgba(102,204,255,0.2)',
+                tension: 0.3,
+                yAxisID: 'y1'
+            }
+        ]
+    };
+
+    if (trendChart) {
+        trendChart.data = data;
+        trendChart.update();
+        return;
+    }
+
+    const ctx = document.getElementById('trendChart').getContext('2d');
+    trendChart = new Chart(ctx, {
+        type: 'line',
+        data: data,
+        options: {
+            responsive: true,
+            plugins: { legend: { display: true } },
+            scales: {
+                y: { beginAtZero: true, position: 'left' },
+                y1: { beginAtZero: true, position: 'right', grid: { drawOnChartArea: false } }
+            }
+        }
+    });
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+    const select = document.getElementById('trendGroup');
+    if (!select || !select.value) {
+        return;
+    }
+
+    select.addEventListener('change', () => loadTrend(select.selectedOptions[0]));
+    loadTrend(select.selectedOptions[0]);
+});
@@ -0,0 +1,102 @@
+// Every state-changing API request carries the session's CSRF token
+const csrfToken = document.querySelector('meta[name="csrf-token"]').content;
+
+function showWorkspaceError(message) {
+    const workspaceError = document.getElementById('workspaceError');
+    workspaceError.textContent = message;
+    workspaceError.style.display = 'block';
+}
+
+async function sendJSON(url, method, body, fallbackMessage) {
+    const response = await fetch(url, {
+        method: method,
+        headers: {
+            'Content-Type': 'application/json',
+            'X-CSRF-Token': csrfToken,
+        },
+        body: body === undefined ? undefined : JSON.stringify(body)
+    });
+    if (!response.ok) {
+        const data = await response.json().catch(() => ({}));
+        throw new Error(data.message || fallbackMessage);
+    }
+}
+
+document.getElementById('createWorkspaceBtn').addEventListener('click', async function() {
+    const name = document.getElementById('workspaceName').value.trim();
+    const createBtn = document.getElementById('createWorkspaceBtn');
+
+    document.getElementById('workspaceError').style.display = 'none';
+    if (!name) {
+        showWorkspaceError('Пожалуйста, введите название пространства.');
+        return;
+    }
+
+    createBtn.disabled = true;
+    try {
+        await sendJSON(routes.workspaces, 'POST', { name: name }, 'Не удалось создать пространство.');
+        location.reload();
+    } catch (error) {
+        showWorkspaceError(error.message);
+        createBtn.disabled = false;
+    }
+});
+
+const addMemberBtn = document.getElementById('addMemberBtn');
+if (addMemberBtn) {
+    addMemberBtn.addEventListener('click', async function() {
+        const username = document.getElementById('memberUsername').value.trim();
+
+        document.getElementById('workspaceError').style.display = 'none';
+        if (!username) {
+            showWorkspaceError('Пожалуйста, введите имя пользователя.');
+            return;
+        }
+
+        addMemberBtn.disabled = true;
+        try {
+            await sendJSON(routes.members, 'POST', {
+                username: username,
+                role: document.getElementById('memberRole').value
+            }, 'Не удалось добавить участника.');
+            location.reload();
+        } catch (error) {
+            showWorkspaceError(error.message);
+            addMemberBtn.disabled = false;
+        }
+    });
+}
+
+document.querySelectorAll('.member-role').forEach(select => {
+    // Remember the saved role to restore it if the change is refused
+    select.dataset.saved = select.value;
+    select.addEventListener('change', async function() {
+        select.disabled = true;
+        try {
+            await sendJSON(select.dataset.memberUrl, 'PUT', { role: select.value }, 'Не удалось изменить роль.');
+            select.dataset.saved = select.value;
+        } catch (error) {
+            showWorkspaceError(error.message);
+            select.value = select.dataset.saved;
+        } finally {
+            select.disabled = false;
+        }
+    });
+});
+
+document.querySelectorAll('.remove-member').forEach(button => {
+    button.addEventListener('click', async function() {
+        if (!confirm('Удалить участника? Его API-ключи для этого пространства будут отозваны.')) {
+            return;
+        }
+
+        button.disabled = true;
+        try {
+            await sendJSON(button.dataset.memberUrl, 'DELETE', undefined, 'Не удалось удалить участника.');
+            button.closest('tr').remove();
+        } catch (error) {
+            showWorkspaceError(error.message);
+            button.disabled = false;
+        }
+    });
+});